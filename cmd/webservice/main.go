@@ -8,9 +8,12 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // plant timezones must resolve in minimal images
 
 	"tensor-graphql/infrastructure/config"
 	"tensor-graphql/infrastructure/database"
+	apiMiddleware "tensor-graphql/internal/api/middleware"
+	"tensor-graphql/internal/auth"
 	"tensor-graphql/internal/container"
 
 	"github.com/99designs/gqlgen/graphql/handler"
@@ -47,6 +50,14 @@ func main() {
 		panic(err)
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(log, os.Args[2:]); err != nil {
+			log.Error("error: migration failed", zap.Error(err))
+			os.Exit(1)
+		}
+		return
+	}
+
 	if err := run(log); err != nil {
 		log.Error("error: shutting down", zap.Error(err))
 		os.Exit(1)
@@ -56,6 +67,14 @@ func main() {
 func run(log *zap.Logger) error {
 	conf := config.Get()
 
+	// Apply pending migrations before serving, when enabled
+	if conf.AutoMigrate {
+		if err := migrateUp(log, conf); err != nil {
+			log.Error("failed to migrate db", zap.Error(err))
+			return err
+		}
+	}
+
 	// Initialize Database
 	db, err := database.InitializeDatabase(conf)
 	if err != nil {
@@ -63,6 +82,11 @@ func run(log *zap.Logger) error {
 		return err
 	}
 
+	// Send reads to the master while the slave is unreachable
+	healthCheckCtx, stopHealthCheck := context.WithCancel(context.Background())
+	defer stopHealthCheck()
+	db.StartHealthCheck(healthCheckCtx, conf.DBSlave.HealthCheckInterval)
+
 	// Shared component for dependency injection
 	sharedComponent := &container.SharedComponent{
 		DB:   db,
@@ -73,10 +97,22 @@ func run(log *zap.Logger) error {
 	// Initialize handler components, including GraphQL resolver and use cases
 	cc := container.NewHandlerComponent(sharedComponent)
 
+	// Resolve callers of the GraphQL endpoint from JWTs or trusted proxy headers
+	principalExtractor, err := auth.NewPrincipalExtractor(conf.Auth)
+	if err != nil {
+		log.Error("failed to initialize principal extractor", zap.Error(err))
+		return err
+	}
+
+	// Machine clients authenticate with "Authorization: ApiKey <key>" instead
+	principalExtractor = auth.NewChainExtractor(auth.NewAPIKeyExtractor(cc.APIKeyUsecase), principalExtractor)
+
 	// Initialize Echo Web Server
 	e := echo.New()
 	e.Pre(middleware.RemoveTrailingSlash())
 	e.Use(middleware.Recover())
+	e.Use(apiMiddleware.RequestID())
+	e.Use(apiMiddleware.ReadYourWrites())
 	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			c.Request().Header.Set("Cache-Control", "max-age=3600, public")
@@ -100,16 +136,35 @@ func run(log *zap.Logger) error {
 
 	e.Validator = &requestValidator{}
 
-	// Set up GraphQL handler with transports (POST and GET)
-	graphqlHandler := handler.New(graphqlResolver.NewExecutableSchema(graphqlResolver.Config{Resolvers: cc.Resolver}))
+	// Set up GraphQL handler with transports (POST, GET and multipart uploads)
+	graphqlHandler := handler.New(graphqlResolver.NewExecutableSchema(graphqlResolver.Config{
+		Resolvers: cc.Resolver,
+		Directives: graphqlResolver.DirectiveRoot{
+			HasRole: graphqlResolver.HasRole,
+		},
+	}))
 	graphqlHandler.AddTransport(transport.POST{})
 	graphqlHandler.AddTransport(transport.GET{})
+	graphqlHandler.AddTransport(transport.MultipartForm{})
+	graphqlHandler.SetErrorPresenter(graphqlResolver.ErrorPresenter)
+	// Fetch the weather of a plant once per operation, only for the fields asked for
+	graphqlHandler.AroundOperations(cc.Resolver.WeatherLoader)
 
 	// GraphQL endpoint
 	e.POST("/graphql", func(c echo.Context) error {
 		graphqlHandler.ServeHTTP(c.Response(), c.Request())
 		return nil
-	})
+	}, apiMiddleware.Authenticate(principalExtractor))
+
+	// File exports, streamed so large fleets are never held in memory. They
+	// outlast the WriteTimeout of the server, so they get their own deadline.
+	const exportTimeout = 10 * time.Minute
+	e.GET("/export/power-plants.geojson", cc.Export.PowerPlantsGeoJSON,
+		apiMiddleware.WriteDeadline(exportTimeout),
+		apiMiddleware.Authenticate(principalExtractor), apiMiddleware.RequireRole(auth.RoleViewer))
+	e.GET("/export/forecasts", cc.Export.Forecasts,
+		apiMiddleware.WriteDeadline(exportTimeout),
+		apiMiddleware.Authenticate(principalExtractor), apiMiddleware.RequireRole(auth.RoleViewer))
 
 	fmt.Println(conf.Environment)
 	// Enable Playground only in development environment
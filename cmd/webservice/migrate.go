@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"tensor-graphql/infrastructure/config"
+	"tensor-graphql/infrastructure/database"
+
+	"go.uber.org/zap"
+)
+
+const migrateUsage = "usage: migrate up | down [steps] | status | force <version>"
+
+// runMigrate runs the migrate subcommand against the master database with the
+// migrations embedded in the binary.
+func runMigrate(log *zap.Logger, args []string) (err error) {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	migrator, err := database.NewMigrator(config.Get().DBMaster)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	switch args[0] {
+	case "up":
+		err = migrator.Up()
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid steps %q. %s", args[1], migrateUsage)
+			}
+		}
+		err = migrator.Down(steps)
+	case "force":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		version, convErr := strconv.Atoi(args[1])
+		if convErr != nil {
+			return fmt.Errorf("invalid version %q. %s", args[1], migrateUsage)
+		}
+		err = migrator.Force(version)
+	case "status":
+		return printMigrationStatus(migrator)
+	default:
+		return errors.New(migrateUsage)
+	}
+	if err != nil {
+		return err
+	}
+
+	version, dirty, err := migrator.Version()
+	if err != nil {
+		return err
+	}
+	log.Info("migration finished", zap.String("command", args[0]), zap.Uint("version", version), zap.Bool("dirty", dirty))
+
+	return nil
+}
+
+func printMigrationStatus(migrator *database.Migrator) error {
+	version, dirty, err := migrator.Version()
+	if err != nil {
+		return err
+	}
+
+	statuses, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("current version: %d (dirty: %t)\n", version, dirty)
+	for _, status := range statuses {
+		state := "pending"
+		if status.Applied {
+			state = "applied"
+		}
+		fmt.Printf("%d\t%s\n", status.Version, state)
+	}
+
+	return nil
+}
+
+// migrateUp applies all pending migrations, for the AUTO_MIGRATE startup mode.
+func migrateUp(log *zap.Logger, conf *config.Config) error {
+	migrator, err := database.NewMigrator(conf.DBMaster)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	err = migrator.Up()
+	if err != nil {
+		return err
+	}
+
+	version, _, err := migrator.Version()
+	if err != nil {
+		return err
+	}
+	log.Info("database migrated", zap.Uint("version", version))
+
+	return nil
+}
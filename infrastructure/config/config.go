@@ -3,7 +3,10 @@ package config
 import (
 	"fmt"
 	"log"
+	"net/url"
+	"strings"
 	"tensor-graphql/internal/constant"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
@@ -16,13 +19,44 @@ type Config struct {
 
 	DBMaster *DB
 	DBSlave  *DB
+	// AutoMigrate applies pending migrations on startup.
+	AutoMigrate bool
+
+	Auth *Auth
+	Map  *Map
 }
 
 // DB config model
 type DB struct {
-	ConnectionString string
-	MaxIdle          int
-	MaxOpen          int
+	// Driver is the database backend, mysql, postgres or sqlite.
+	Driver              string
+	ConnectionString    string
+	MaxIdle             int
+	MaxOpen             int
+	HealthCheckInterval time.Duration
+}
+
+// Auth config model
+type Auth struct {
+	Mode               string
+	SubjectHeader      string
+	OrganizationHeader string
+	RolesHeader        string
+	JWTSecret          string
+	JWTPublicKeyFile   string
+	JWKSFile           string
+	JWTIssuer          string
+	JWTAudience        string
+}
+
+// Map config model
+type Map struct {
+	// MaxPlants is the number of plants a map region returns before they are
+	// clustered.
+	MaxPlants int
+	// ClusterGridSize is the number of rows and columns a clustered region is
+	// split into.
+	ClusterGridSize int
 }
 
 // DatabaseConfig stores database configurations.
@@ -32,6 +66,9 @@ type configEnv struct {
 	CORSOrigins []string `envconfig:"CORS_ORIGINS"`
 
 	// Database config
+	DBDriver        string `envconfig:"DBDRIVER" default:"mysql"`
+	DBSSLMode       string `envconfig:"DBSSLMODE" default:"disable"`
+	DBSQLitePath    string `envconfig:"DBSQLITEPATH" default:"tensor.db"`
 	DBMasterMaxIdle int    `envconfig:"DBMASTERMAXIDLECONN"`
 	DBMasterMaxOpen int    `envconfig:"DBMASTERMAXOPENCONN"`
 	DBSlaveMaxIdle  int    `envconfig:"DBSLAVEMAXIDLECONN"`
@@ -46,6 +83,24 @@ type configEnv struct {
 	DBSlaveHost     string `envconfig:"DBSLAVEHOST"`
 	DBSlavePort     string `envconfig:"DBSLAVEPORT"`
 	DBSlaveName     string `envconfig:"DBSLAVENAME"`
+
+	DBSlaveHealthCheckInterval time.Duration `envconfig:"DBSLAVEHEALTHCHECKINTERVAL" default:"5s"`
+	AutoMigrate                bool          `envconfig:"AUTO_MIGRATE" default:"false"`
+
+	// Auth config
+	AuthMode          string `envconfig:"AUTH_MODE" default:"jwt"`
+	AuthSubjectHeader string `envconfig:"AUTH_SUBJECT_HEADER"`
+	AuthOrgHeader     string `envconfig:"AUTH_ORGANIZATION_HEADER"`
+	AuthRolesHeader   string `envconfig:"AUTH_ROLES_HEADER"`
+	JWTSecret         string `envconfig:"JWT_HS256_SECRET"`
+	JWTPublicKeyFile  string `envconfig:"JWT_RS256_PUBLIC_KEY_FILE"`
+	JWKSFile          string `envconfig:"JWT_JWKS_FILE"`
+	JWTIssuer         string `envconfig:"JWT_ISSUER"`
+	JWTAudience       string `envconfig:"JWT_AUDIENCE"`
+
+	// Map config
+	MapMaxPlants       int `envconfig:"MAP_MAX_PLANTS" default:"200"`
+	MapClusterGridSize int `envconfig:"MAP_CLUSTER_GRID_SIZE" default:"8"`
 }
 
 var appConfig *Config
@@ -64,13 +119,18 @@ func Init() {
 	appConfig.HttpPort = cfg.Port
 	appConfig.CORSOrigins = cfg.CORSOrigins
 
+	appConfig.AutoMigrate = cfg.AutoMigrate
+
 	initDB(&cfg)
+	initAuth(&cfg)
+	initMap(&cfg)
 }
 
 func initDB(c *configEnv) {
 	appConfig.DBMaster = &DB{
-		ConnectionString: fmt.Sprintf(
-			constant.DBStringConnection,
+		Driver: c.DBDriver,
+		ConnectionString: connectionString(
+			c,
 			c.DBMasterUser,
 			c.DBMasterPass,
 			c.DBMasterHost,
@@ -81,16 +141,59 @@ func initDB(c *configEnv) {
 		MaxOpen: c.DBMasterMaxOpen,
 	}
 	appConfig.DBSlave = &DB{
-		ConnectionString: fmt.Sprintf(
-			constant.DBStringConnection,
+		Driver: c.DBDriver,
+		ConnectionString: connectionString(
+			c,
 			c.DBSlaveUser,
 			c.DBSlavePass,
 			c.DBSlaveHost,
 			c.DBSlavePort,
 			c.DBSlaveName,
 		),
-		MaxIdle: c.DBMasterMaxIdle,
-		MaxOpen: c.DBMasterMaxOpen,
+		MaxIdle:             c.DBSlaveMaxIdle,
+		MaxOpen:             c.DBSlaveMaxOpen,
+		HealthCheckInterval: c.DBSlaveHealthCheckInterval,
+	}
+}
+
+// connectionString returns the DSN of a database for the configured driver.
+func connectionString(c *configEnv, user, pass, host, port, name string) string {
+	switch strings.ToLower(c.DBDriver) {
+	case "postgres", "postgresql", "pgx":
+		return fmt.Sprintf(
+			constant.PostgresDBStringConnection,
+			url.UserPassword(user, pass).String(),
+			host,
+			port,
+			name,
+			c.DBSSLMode,
+		)
+	case "sqlite", "sqlite3":
+		// The master and the slave share the database file.
+		return fmt.Sprintf(constant.SQLiteDBStringConnection, c.DBSQLitePath)
+	default:
+		return fmt.Sprintf(constant.DBStringConnection, user, pass, host, port, name)
+	}
+}
+
+func initAuth(c *configEnv) {
+	appConfig.Auth = &Auth{
+		Mode:               c.AuthMode,
+		SubjectHeader:      c.AuthSubjectHeader,
+		OrganizationHeader: c.AuthOrgHeader,
+		RolesHeader:        c.AuthRolesHeader,
+		JWTSecret:          c.JWTSecret,
+		JWTPublicKeyFile:   c.JWTPublicKeyFile,
+		JWKSFile:           c.JWKSFile,
+		JWTIssuer:          c.JWTIssuer,
+		JWTAudience:        c.JWTAudience,
+	}
+}
+
+func initMap(c *configEnv) {
+	appConfig.Map = &Map{
+		MaxPlants:       c.MapMaxPlants,
+		ClusterGridSize: c.MapClusterGridSize,
 	}
 }
 
@@ -1,17 +1,26 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"sync/atomic"
 	"tensor-graphql/infrastructure/config"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
 )
 
 // DB component
 type DB struct {
-	Master *sql.DB
-	Slave  *sql.DB
+	Master  *sql.DB
+	Slave   *sql.DB
+	Dialect Dialect
+
+	// slaveDown is set by the health check while the slave cannot be reached.
+	slaveDown atomic.Bool
 }
 
 // InitializeDatabase to initialize database
@@ -21,8 +30,13 @@ func InitializeDatabase(conf *config.Config) (db *DB, err error) {
 	}
 	db = &DB{}
 
+	db.Dialect, err = ParseDialect(conf.DBMaster.Driver)
+	if err != nil {
+		return nil, err
+	}
+
 	confMaster := conf.DBMaster
-	db.Master, err = sql.Open("mysql", confMaster.ConnectionString)
+	db.Master, err = sql.Open(db.Dialect.DriverName(), confMaster.ConnectionString)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open DB master connection. %+v", err)
 	}
@@ -34,15 +48,63 @@ func InitializeDatabase(conf *config.Config) (db *DB, err error) {
 	}
 
 	confSlave := conf.DBSlave
-	db.Slave, err = sql.Open("mysql", confSlave.ConnectionString)
+	db.Slave, err = sql.Open(db.Dialect.DriverName(), confSlave.ConnectionString)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open DB slave connection. %+v", err)
 	}
 	db.Slave.SetMaxIdleConns(confSlave.MaxIdle)
 	db.Slave.SetMaxOpenConns(confSlave.MaxOpen)
-	err = db.Slave.Ping()
-	if err != nil {
-		return nil, fmt.Errorf("failed to ping DB slave. %+v", err)
+
+	// An unreachable slave is not fatal, reads go to the master until the
+	// health check sees it again.
+	db.CheckSlave(context.Background())
+
+	return db, nil
+}
+
+// Reader returns the connection pool for reads outside of a transaction: the
+// slave, or the master while the slave is down.
+func (db *DB) Reader() *sql.DB {
+	if db.Slave == nil || db.slaveDown.Load() {
+		return db.Master
+	}
+	return db.Slave
+}
+
+// SlaveHealthy reports whether the last health check reached the slave.
+func (db *DB) SlaveHealthy() bool {
+	return !db.slaveDown.Load()
+}
+
+// CheckSlave pings the slave and records whether it is reachable.
+func (db *DB) CheckSlave(ctx context.Context) {
+	if db.Slave == nil {
+		return
+	}
+
+	err := db.Slave.PingContext(ctx)
+	db.slaveDown.Store(err != nil)
+}
+
+// StartHealthCheck checks the slave every interval until ctx is done.
+func (db *DB) StartHealthCheck(ctx context.Context, interval time.Duration) {
+	if db == nil || interval <= 0 {
+		return
 	}
-	return db, err
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				checkCtx, cancel := context.WithTimeout(ctx, interval)
+				db.CheckSlave(checkCtx)
+				cancel()
+			}
+		}
+	}()
 }
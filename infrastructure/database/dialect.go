@@ -0,0 +1,106 @@
+package database
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dialect identifies the SQL flavour of a database backend.
+type Dialect string
+
+const (
+	// MySQL is the default backend, the zero Dialect behaves like MySQL.
+	MySQL    Dialect = "mysql"
+	Postgres Dialect = "postgres"
+	SQLite   Dialect = "sqlite"
+)
+
+// ParseDialect returns the dialect of a DB_DRIVER config value.
+func ParseDialect(driver string) (Dialect, error) {
+	switch Dialect(strings.ToLower(driver)) {
+	case "", MySQL:
+		return MySQL, nil
+	case Postgres, "postgresql", "pgx":
+		return Postgres, nil
+	case SQLite, "sqlite3":
+		return SQLite, nil
+	default:
+		return "", fmt.Errorf("unsupported database driver %q", driver)
+	}
+}
+
+// DriverName returns the database/sql driver registered for the dialect.
+func (d Dialect) DriverName() string {
+	switch d {
+	case Postgres:
+		return "pgx"
+	case SQLite:
+		return "sqlite"
+	default:
+		return "mysql"
+	}
+}
+
+// Rebind rewrites the ? placeholders of query to the placeholders of the
+// dialect. Question marks inside quoted strings and identifiers are kept.
+func (d Dialect) Rebind(query string) string {
+	if d != Postgres || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var (
+		b     strings.Builder
+		n     int
+		quote rune
+	)
+	b.Grow(len(query) + 8)
+	for _, r := range query {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '?':
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// SupportsLastInsertID reports whether sql.Result.LastInsertId works, the
+// other dialects return generated IDs with RETURNING.
+func (d Dialect) SupportsLastInsertID() bool {
+	return d != Postgres
+}
+
+// ConsecutiveInsertIDs reports whether the rows of a multi-row INSERT get
+// consecutive IDs starting at LastInsertId, as MySQL does for inserts with a
+// known number of rows. The other dialects return the IDs with RETURNING.
+func (d Dialect) ConsecutiveInsertIDs() bool {
+	return d == MySQL || d == ""
+}
+
+// SupportsSpatial reports whether the power_plant table has a spatial location
+// column, a POINT on MySQL and a PostGIS geography on PostgreSQL. SQLite
+// filters on the latitude and longitude columns.
+func (d Dialect) SupportsSpatial() bool {
+	return d != SQLite
+}
+
+// ForUpdate returns the clause that locks the rows read by a SELECT until the
+// end of the transaction. SQLite transactions take the write lock when they
+// begin, so it needs none.
+func (d Dialect) ForUpdate() string {
+	if d == SQLite {
+		return ""
+	}
+	return " FOR UPDATE"
+}
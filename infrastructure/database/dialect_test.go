@@ -0,0 +1,34 @@
+package database_test
+
+import (
+	"tensor-graphql/infrastructure/database"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRebind(t *testing.T) {
+	query := "SELECT id FROM power_plant WHERE organization_id = ? AND name = '?' LIMIT ? OFFSET ?"
+
+	assert.Equal(t, query, database.MySQL.Rebind(query))
+	assert.Equal(t,
+		"SELECT id FROM power_plant WHERE organization_id = $1 AND name = '?' LIMIT $2 OFFSET $3",
+		database.Postgres.Rebind(query))
+}
+
+func TestParseDialect(t *testing.T) {
+	dialect, err := database.ParseDialect("")
+	assert.NoError(t, err)
+	assert.Equal(t, database.MySQL, dialect)
+
+	dialect, err = database.ParseDialect("postgres")
+	assert.NoError(t, err)
+	assert.Equal(t, database.Postgres, dialect)
+
+	dialect, err = database.ParseDialect("sqlite3")
+	assert.NoError(t, err)
+	assert.Equal(t, database.SQLite, dialect)
+
+	_, err = database.ParseDialect("oracle")
+	assert.Error(t, err)
+}
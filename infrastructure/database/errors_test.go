@@ -0,0 +1,22 @@
+package database_test
+
+import (
+	"errors"
+	"fmt"
+	"tensor-graphql/infrastructure/database"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsDuplicateKey(t *testing.T) {
+	assert.True(t, database.IsDuplicateKey(&mysql.MySQLError{Number: 1062}))
+	assert.True(t, database.IsDuplicateKey(fmt.Errorf("insert: %w", &pgconn.PgError{Code: "23505"})))
+
+	assert.False(t, database.IsDuplicateKey(&mysql.MySQLError{Number: 1452}))
+	assert.False(t, database.IsDuplicateKey(&pgconn.PgError{Code: "23503"}))
+	assert.False(t, database.IsDuplicateKey(errors.New("duplicate")))
+	assert.False(t, database.IsDuplicateKey(nil))
+}
@@ -0,0 +1,161 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"tensor-graphql/infrastructure/config"
+	"tensor-graphql/infrastructure/database/migrations"
+
+	"github.com/golang-migrate/migrate/v4"
+	migratedatabase "github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/mysql"
+	"github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// Migrator applies the embedded migrations of the configured dialect to the
+// master database.
+type Migrator struct {
+	migrate *migrate.Migrate
+	dir     string
+}
+
+// MigrationStatus describes one embedded migration.
+type MigrationStatus struct {
+	Version uint
+	Applied bool
+}
+
+// NewMigrator opens a dedicated connection to the master for migrations.
+func NewMigrator(conf *config.DB) (m *Migrator, err error) {
+	dialect, err := ParseDialect(conf.Driver)
+	if err != nil {
+		return nil, err
+	}
+
+	dsn := conf.ConnectionString
+	if dialect == MySQL {
+		// Migration files may hold several statements.
+		if strings.Contains(dsn, "?") {
+			dsn += "&multiStatements=true"
+		} else {
+			dsn += "?multiStatements=true"
+		}
+	}
+
+	db, err := sql.Open(dialect.DriverName(), dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open DB master connection. %+v", err)
+	}
+
+	dir := string(dialect)
+	source, err := iofs.New(migrations.FS, dir)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to read migrations. %+v", err)
+	}
+
+	var driver migratedatabase.Driver
+	switch dialect {
+	case Postgres:
+		driver, err = pgx.WithInstance(db, &pgx.Config{})
+	case SQLite:
+		driver, err = sqlite.WithInstance(db, &sqlite.Config{})
+	default:
+		driver, err = mysql.WithInstance(db, &mysql.Config{})
+	}
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to prepare migration driver. %+v", err)
+	}
+
+	mig, err := migrate.NewWithInstance("iofs", source, string(dialect), driver)
+	if err != nil {
+		driver.Close()
+		return nil, fmt.Errorf("failed to prepare migrations. %+v", err)
+	}
+
+	return &Migrator{
+		migrate: mig,
+		dir:     dir,
+	}, nil
+}
+
+// Up applies all pending migrations.
+func (m *Migrator) Up() error {
+	err := m.migrate.Up()
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
+
+// Down reverts the last steps migrations.
+func (m *Migrator) Down(steps int) error {
+	if steps < 1 {
+		return fmt.Errorf("steps must be at least 1, got %d", steps)
+	}
+
+	err := m.migrate.Steps(-steps)
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
+
+// Force sets the schema version without running migrations and clears the
+// dirty flag, to recover from a migration that failed halfway.
+func (m *Migrator) Force(version int) error {
+	return m.migrate.Force(version)
+}
+
+// Version returns the current schema version, which is 0 on an empty database.
+func (m *Migrator) Version() (version uint, dirty bool, err error) {
+	version, dirty, err = m.migrate.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// Status lists the embedded migrations and whether they are applied.
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	current, _, err := m.Version()
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := iofs.New(migrations.FS, m.dir)
+	if err != nil {
+		return nil, err
+	}
+	defer source.Close()
+
+	var statuses []MigrationStatus
+	version, err := source.First()
+	for err == nil {
+		statuses = append(statuses, MigrationStatus{
+			Version: version,
+			Applied: current != 0 && version <= current,
+		})
+		version, err = source.Next(version)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	return statuses, nil
+}
+
+// Close closes the migration connection.
+func (m *Migrator) Close() error {
+	sourceErr, dbErr := m.migrate.Close()
+	if sourceErr != nil {
+		return sourceErr
+	}
+	return dbErr
+}
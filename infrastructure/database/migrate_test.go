@@ -0,0 +1,100 @@
+package database_test
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"tensor-graphql/infrastructure/config"
+	"tensor-graphql/infrastructure/database"
+	"tensor-graphql/internal/constant"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMigratorSQLite applies and reverts every migration on a SQLite file,
+// the other backends need a running server.
+func TestMigratorSQLite(t *testing.T) {
+	conf := &config.DB{
+		Driver:           "sqlite",
+		ConnectionString: fmt.Sprintf(constant.SQLiteDBStringConnection, filepath.Join(t.TempDir(), "tensor.db")),
+	}
+
+	migrator, err := database.NewMigrator(conf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer migrator.Close()
+
+	statuses, err := migrator.Status()
+	assert.NoError(t, err)
+
+	assert.NoError(t, migrator.Up())
+	version, dirty, err := migrator.Version()
+	assert.NoError(t, err)
+	assert.False(t, dirty)
+	assert.Equal(t, statuses[len(statuses)-1].Version, version)
+
+	assert.NoError(t, migrator.Down(len(statuses)))
+	version, _, err = migrator.Version()
+	assert.NoError(t, err)
+	assert.Zero(t, version)
+
+	assert.NoError(t, migrator.Up())
+}
+
+// TestMigratorSQLiteDuplicatePlantNames upgrades a database from before
+// organizations, whose plant names were not unique yet.
+func TestMigratorSQLiteDuplicatePlantNames(t *testing.T) {
+	const organizationVersion = 20261019120000
+
+	conf := &config.DB{
+		Driver:           "sqlite",
+		ConnectionString: fmt.Sprintf(constant.SQLiteDBStringConnection, filepath.Join(t.TempDir(), "tensor.db")),
+	}
+
+	migrator, err := database.NewMigrator(conf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer migrator.Close()
+
+	statuses, err := migrator.Status()
+	assert.NoError(t, err)
+	steps := 0
+	for _, status := range statuses {
+		if status.Version >= organizationVersion {
+			steps++
+		}
+	}
+	assert.NoError(t, migrator.Up())
+	assert.NoError(t, migrator.Down(steps))
+
+	db, err := sql.Open(database.SQLite.DriverName(), conf.ConnectionString)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer db.Close()
+
+	for _, name := range []string{"Plant A", "Plant B", "Plant A", "Plant A"} {
+		_, err = db.Exec(`INSERT INTO power_plant (name, latitude, longitude) VALUES (?, 1, 2)`, name)
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, migrator.Up())
+
+	rows, err := db.Query(`SELECT name FROM power_plant ORDER BY id`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		assert.NoError(t, rows.Scan(&name))
+		names = append(names, name)
+	}
+	assert.NoError(t, rows.Err())
+	assert.Equal(t, []string{"Plant A", "Plant B", "Plant A (3)", "Plant A (4)"}, names)
+}
@@ -1 +0,0 @@
-DROP TABLE IF EXIST `power_plant`;
\ No newline at end of file
@@ -0,0 +1,12 @@
+// Package migrations embeds the SQL migrations of the database schema, so the
+// binary can migrate the database without extra tooling.
+package migrations
+
+import "embed"
+
+// FS holds the up and down migrations of each dialect in a directory named
+// after it, e.g. mysql/{version}_{title}.{up|down}.sql. All directories hold
+// the same versions.
+//
+//go:embed mysql/*.sql postgres/*.sql sqlite/*.sql
+var FS embed.FS
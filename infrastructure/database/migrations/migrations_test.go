@@ -0,0 +1,61 @@
+package migrations_test
+
+import (
+	"io/fs"
+	"strings"
+	"tensor-graphql/infrastructure/database/migrations"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/stretchr/testify/assert"
+)
+
+var dialects = []string{"mysql", "postgres", "sqlite"}
+
+func TestMigrationsArePaired(t *testing.T) {
+	files, err := fs.Glob(migrations.FS, "*/*.sql")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, files)
+
+	names := map[string]bool{}
+	for _, file := range files {
+		names[file] = true
+	}
+	for _, file := range files {
+		switch {
+		case strings.HasSuffix(file, ".up.sql"):
+			assert.True(t, names[strings.TrimSuffix(file, ".up.sql")+".down.sql"], "%s has no down migration", file)
+		case strings.HasSuffix(file, ".down.sql"):
+			assert.True(t, names[strings.TrimSuffix(file, ".down.sql")+".up.sql"], "%s has no up migration", file)
+		default:
+			t.Errorf("%s is neither an up nor a down migration", file)
+		}
+	}
+
+	for _, dir := range dialects {
+		source, err := iofs.New(migrations.FS, dir)
+		if assert.NoError(t, err) {
+			_, err = source.First()
+			assert.NoError(t, err)
+			source.Close()
+		}
+	}
+}
+
+// TestDialectsHaveSameVersions keeps the schema of the backends in step.
+func TestDialectsHaveSameVersions(t *testing.T) {
+	mysqlFiles := migrationFiles(t, "mysql")
+	for _, dir := range dialects[1:] {
+		assert.Equal(t, mysqlFiles, migrationFiles(t, dir), dir)
+	}
+}
+
+func migrationFiles(t *testing.T, dir string) []string {
+	files, err := fs.Glob(migrations.FS, dir+"/*.sql")
+	assert.NoError(t, err)
+
+	for i := range files {
+		files[i] = strings.TrimPrefix(files[i], dir+"/")
+	}
+	return files
+}
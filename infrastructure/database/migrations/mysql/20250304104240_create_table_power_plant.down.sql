@@ -0,0 +1 @@
+DROP TABLE IF EXISTS `power_plant`;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE `power_plant` ADD COLUMN `timezone` VARCHAR(64) NOT NULL DEFAULT 'GMT' AFTER `longitude`;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS `api_key`;
\ No newline at end of file
@@ -0,0 +1,14 @@
+CREATE TABLE `api_key` (
+  `id` BIGINT(20) unsigned NOT NULL AUTO_INCREMENT,
+  `name` VARCHAR(255) NOT NULL,
+  `key_prefix` VARCHAR(16) NOT NULL,
+  `key_hash` CHAR(64) NOT NULL,
+  `scopes` VARCHAR(255) NOT NULL,
+  `created_by` VARCHAR(255) NOT NULL,
+  `expires_at` datetime NULL,
+  `last_used_at` datetime NULL,
+  `revoked_at` datetime NULL,
+  `created_at` datetime NOT NULL DEFAULT current_timestamp(),
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_api_key_key_hash` (`key_hash`)
+);
\ No newline at end of file
@@ -0,0 +1,10 @@
+ALTER TABLE `api_key`
+  DROP FOREIGN KEY `fk_api_key_organization`,
+  DROP COLUMN `organization_id`;
+
+ALTER TABLE `power_plant`
+  DROP FOREIGN KEY `fk_power_plant_organization`,
+  DROP KEY `uk_power_plant_organization_id_name`,
+  DROP COLUMN `organization_id`;
+
+DROP TABLE IF EXISTS `organization`;
\ No newline at end of file
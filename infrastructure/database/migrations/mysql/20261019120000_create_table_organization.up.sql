@@ -0,0 +1,29 @@
+CREATE TABLE `organization` (
+  `id` BIGINT(20) unsigned NOT NULL AUTO_INCREMENT,
+  `name` VARCHAR(255) NOT NULL,
+  `created_at` datetime NOT NULL DEFAULT current_timestamp(),
+  `updated_at` datetime NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_organization_name` (`name`)
+);
+
+-- Existing plants and API keys are assigned to a default organization.
+INSERT INTO `organization` (`id`, `name`) VALUES (1, 'Default');
+
+-- Plant names were not unique before, every duplicate but the oldest gets its
+-- ID appended so the unique key on the organization and name can be added.
+UPDATE `power_plant` AS `duplicate`
+  JOIN (SELECT `name`, MIN(`id`) AS `id` FROM `power_plant` GROUP BY `name`) AS `original`
+    ON `original`.`name` = `duplicate`.`name` AND `original`.`id` < `duplicate`.`id`
+  SET `duplicate`.`name` = CONCAT(LEFT(`duplicate`.`name`, 230), ' (', `duplicate`.`id`, ')');
+
+ALTER TABLE `power_plant`
+  ADD COLUMN `organization_id` BIGINT(20) unsigned NOT NULL DEFAULT 1 AFTER `id`,
+  ADD UNIQUE KEY `uk_power_plant_organization_id_name` (`organization_id`, `name`),
+  ADD CONSTRAINT `fk_power_plant_organization` FOREIGN KEY (`organization_id`) REFERENCES `organization` (`id`);
+ALTER TABLE `power_plant` ALTER COLUMN `organization_id` DROP DEFAULT;
+
+ALTER TABLE `api_key`
+  ADD COLUMN `organization_id` BIGINT(20) unsigned NOT NULL DEFAULT 1 AFTER `id`,
+  ADD CONSTRAINT `fk_api_key_organization` FOREIGN KEY (`organization_id`) REFERENCES `organization` (`id`);
+ALTER TABLE `api_key` ALTER COLUMN `organization_id` DROP DEFAULT;
\ No newline at end of file
@@ -0,0 +1,15 @@
+CREATE TABLE `audit_event` (
+  `id` BIGINT(20) unsigned NOT NULL AUTO_INCREMENT,
+  `organization_id` BIGINT(20) unsigned NOT NULL,
+  `entity_type` VARCHAR(64) NOT NULL,
+  `entity_id` VARCHAR(64) NOT NULL,
+  `action` VARCHAR(16) NOT NULL,
+  `actor` VARCHAR(255) NOT NULL,
+  `request_id` VARCHAR(64) NOT NULL DEFAULT '',
+  `before_state` JSON NULL,
+  `after_state` JSON NULL,
+  `created_at` datetime NOT NULL DEFAULT current_timestamp(),
+  PRIMARY KEY (`id`),
+  KEY `idx_audit_event_entity` (`organization_id`, `entity_type`, `entity_id`, `id`),
+  CONSTRAINT `fk_audit_event_organization` FOREIGN KEY (`organization_id`) REFERENCES `organization` (`id`)
+);
\ No newline at end of file
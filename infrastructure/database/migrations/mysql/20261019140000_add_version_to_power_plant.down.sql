@@ -0,0 +1 @@
+ALTER TABLE `power_plant` DROP COLUMN `version`;
\ No newline at end of file
@@ -0,0 +1,7 @@
+ALTER TABLE `power_plant`
+  DROP INDEX `sidx_power_plant_location`,
+  DROP COLUMN `location`;
+
+ALTER TABLE `power_plant`
+  MODIFY COLUMN `latitude` FLOAT(12) NOT NULL,
+  MODIFY COLUMN `longitude` FLOAT(12) NOT NULL;
\ No newline at end of file
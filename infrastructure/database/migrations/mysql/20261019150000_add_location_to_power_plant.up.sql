@@ -0,0 +1,12 @@
+-- FLOAT(12) keeps about a metre of precision, DECIMAL(9,6) keeps about 10 cm.
+ALTER TABLE `power_plant`
+  MODIFY COLUMN `latitude` DECIMAL(9,6) NOT NULL,
+  MODIFY COLUMN `longitude` DECIMAL(9,6) NOT NULL;
+
+-- The location is derived from the coordinates, so the repositories keep
+-- writing latitude and longitude only.
+ALTER TABLE `power_plant`
+  ADD COLUMN `location` POINT SRID 4326 GENERATED ALWAYS AS (
+    ST_PointFromText(CONCAT('POINT(', `latitude`, ' ', `longitude`, ')'), 4326, 'axis-order=lat-long')
+  ) STORED NOT NULL AFTER `longitude`,
+  ADD SPATIAL INDEX `sidx_power_plant_location` (`location`);
\ No newline at end of file
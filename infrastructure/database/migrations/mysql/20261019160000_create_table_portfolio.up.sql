@@ -0,0 +1,21 @@
+CREATE TABLE `portfolio` (
+  `id` BIGINT(20) unsigned NOT NULL AUTO_INCREMENT,
+  `organization_id` BIGINT(20) unsigned NOT NULL,
+  `name` VARCHAR(255) NOT NULL,
+  `created_at` datetime NOT NULL DEFAULT current_timestamp(),
+  `updated_at` datetime NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_portfolio_organization_id_name` (`organization_id`, `name`),
+  CONSTRAINT `fk_portfolio_organization` FOREIGN KEY (`organization_id`) REFERENCES `organization` (`id`)
+);
+
+-- A member leaves the portfolio when the portfolio or the plant is deleted.
+CREATE TABLE `portfolio_member` (
+  `portfolio_id` BIGINT(20) unsigned NOT NULL,
+  `power_plant_id` BIGINT(20) unsigned NOT NULL,
+  `capacity_mw` DOUBLE NOT NULL,
+  PRIMARY KEY (`portfolio_id`, `power_plant_id`),
+  KEY `idx_portfolio_member_power_plant_id` (`power_plant_id`),
+  CONSTRAINT `fk_portfolio_member_portfolio` FOREIGN KEY (`portfolio_id`) REFERENCES `portfolio` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_portfolio_member_power_plant` FOREIGN KEY (`power_plant_id`) REFERENCES `power_plant` (`id`) ON DELETE CASCADE
+);
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE api_key (
+  id BIGSERIAL PRIMARY KEY,
+  name VARCHAR(255) NOT NULL,
+  key_prefix VARCHAR(16) NOT NULL,
+  key_hash CHAR(64) NOT NULL,
+  scopes VARCHAR(255) NOT NULL,
+  created_by VARCHAR(255) NOT NULL,
+  expires_at TIMESTAMP NULL,
+  last_used_at TIMESTAMP NULL,
+  revoked_at TIMESTAMP NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT uk_api_key_key_hash UNIQUE (key_hash)
+);
\ No newline at end of file
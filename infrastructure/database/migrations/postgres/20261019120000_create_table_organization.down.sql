@@ -0,0 +1,3 @@
+ALTER TABLE api_key DROP COLUMN organization_id;
+ALTER TABLE power_plant DROP COLUMN organization_id;
+DROP TABLE IF EXISTS organization;
\ No newline at end of file
@@ -0,0 +1,26 @@
+CREATE TABLE organization (
+  id BIGSERIAL PRIMARY KEY,
+  name VARCHAR(255) NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT uk_organization_name UNIQUE (name)
+);
+
+-- Existing plants and API keys are assigned to a default organization.
+INSERT INTO organization (id, name) VALUES (1, 'Default');
+SELECT setval(pg_get_serial_sequence('organization', 'id'), (SELECT MAX(id) FROM organization));
+
+-- Plant names were not unique before, every duplicate but the oldest gets its
+-- ID appended so the unique index on the organization and name can be added.
+UPDATE power_plant AS duplicate
+  SET name = LEFT(duplicate.name, 230) || ' (' || duplicate.id || ')'
+  WHERE EXISTS (SELECT 1 FROM power_plant AS original WHERE original.name = duplicate.name AND original.id < duplicate.id);
+
+ALTER TABLE power_plant
+  ADD COLUMN organization_id BIGINT NOT NULL DEFAULT 1 REFERENCES organization (id);
+ALTER TABLE power_plant ALTER COLUMN organization_id DROP DEFAULT;
+CREATE UNIQUE INDEX uk_power_plant_organization_id_name ON power_plant (organization_id, name);
+
+ALTER TABLE api_key
+  ADD COLUMN organization_id BIGINT NOT NULL DEFAULT 1 REFERENCES organization (id);
+ALTER TABLE api_key ALTER COLUMN organization_id DROP DEFAULT;
\ No newline at end of file
@@ -0,0 +1,14 @@
+CREATE TABLE audit_event (
+  id BIGSERIAL PRIMARY KEY,
+  organization_id BIGINT NOT NULL REFERENCES organization (id),
+  entity_type VARCHAR(64) NOT NULL,
+  entity_id VARCHAR(64) NOT NULL,
+  action VARCHAR(16) NOT NULL,
+  actor VARCHAR(255) NOT NULL,
+  request_id VARCHAR(64) NOT NULL DEFAULT '',
+  before_state JSONB NULL,
+  after_state JSONB NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_event_entity ON audit_event (organization_id, entity_type, entity_id, id);
\ No newline at end of file
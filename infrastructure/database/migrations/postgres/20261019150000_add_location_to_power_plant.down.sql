@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_power_plant_location;
+DROP INDEX IF EXISTS gidx_power_plant_location;
+
+ALTER TABLE power_plant DROP COLUMN IF EXISTS location;
+
+ALTER TABLE power_plant
+  ALTER COLUMN latitude TYPE DOUBLE PRECISION,
+  ALTER COLUMN longitude TYPE DOUBLE PRECISION;
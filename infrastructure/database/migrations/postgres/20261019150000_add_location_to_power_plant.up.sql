@@ -0,0 +1,19 @@
+-- DOUBLE PRECISION keeps more digits than the other backends store,
+-- NUMERIC(9,6) keeps about 10 cm like their DECIMAL(9,6).
+ALTER TABLE power_plant
+  ALTER COLUMN latitude TYPE NUMERIC(9,6),
+  ALTER COLUMN longitude TYPE NUMERIC(9,6);
+
+CREATE EXTENSION IF NOT EXISTS postgis;
+
+-- The location is derived from the coordinates, so the repositories keep
+-- writing latitude and longitude only. PostGIS points are longitude first.
+ALTER TABLE power_plant
+  ADD COLUMN location geography(Point, 4326) GENERATED ALWAYS AS (
+    ST_SetSRID(ST_MakePoint(longitude::DOUBLE PRECISION, latitude::DOUBLE PRECISION), 4326)::geography
+  ) STORED;
+
+-- The GiST index serves radius searches, the plain index bounding box lookups
+-- whose edges follow latitudes rather than great circles.
+CREATE INDEX gidx_power_plant_location ON power_plant USING GIST (location);
+CREATE INDEX idx_power_plant_location ON power_plant (organization_id, latitude, longitude);
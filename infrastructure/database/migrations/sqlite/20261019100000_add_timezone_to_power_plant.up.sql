@@ -0,0 +1 @@
+ALTER TABLE power_plant ADD COLUMN timezone TEXT NOT NULL DEFAULT 'GMT';
\ No newline at end of file
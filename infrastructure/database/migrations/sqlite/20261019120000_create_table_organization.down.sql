@@ -0,0 +1,33 @@
+CREATE TABLE power_plant_old (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name TEXT NOT NULL,
+  latitude REAL NOT NULL,
+  longitude REAL NOT NULL,
+  timezone TEXT NOT NULL DEFAULT 'GMT',
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+INSERT INTO power_plant_old (id, name, latitude, longitude, timezone, created_at, updated_at)
+  SELECT id, name, latitude, longitude, timezone, created_at, updated_at FROM power_plant;
+DROP TABLE power_plant;
+ALTER TABLE power_plant_old RENAME TO power_plant;
+
+CREATE TABLE api_key_old (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name TEXT NOT NULL,
+  key_prefix TEXT NOT NULL,
+  key_hash TEXT NOT NULL,
+  scopes TEXT NOT NULL,
+  created_by TEXT NOT NULL,
+  expires_at DATETIME NULL,
+  last_used_at DATETIME NULL,
+  revoked_at DATETIME NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT uk_api_key_key_hash UNIQUE (key_hash)
+);
+INSERT INTO api_key_old (id, name, key_prefix, key_hash, scopes, created_by, expires_at, last_used_at, revoked_at, created_at)
+  SELECT id, name, key_prefix, key_hash, scopes, created_by, expires_at, last_used_at, revoked_at, created_at FROM api_key;
+DROP TABLE api_key;
+ALTER TABLE api_key_old RENAME TO api_key;
+
+DROP TABLE IF EXISTS organization;
\ No newline at end of file
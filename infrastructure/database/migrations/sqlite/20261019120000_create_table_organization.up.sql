@@ -0,0 +1,53 @@
+CREATE TABLE organization (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name TEXT NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT uk_organization_name UNIQUE (name)
+);
+
+-- Existing plants and API keys are assigned to a default organization.
+INSERT INTO organization (id, name) VALUES (1, 'Default');
+
+-- SQLite cannot add a NOT NULL foreign key column, the tables are rebuilt.
+CREATE TABLE power_plant_new (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  organization_id INTEGER NOT NULL REFERENCES organization (id),
+  name TEXT NOT NULL,
+  latitude REAL NOT NULL,
+  longitude REAL NOT NULL,
+  timezone TEXT NOT NULL DEFAULT 'GMT',
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+-- Plant names were not unique before, every duplicate but the oldest gets its
+-- ID appended so the unique index on the organization and name can be added.
+INSERT INTO power_plant_new (id, organization_id, name, latitude, longitude, timezone, created_at, updated_at)
+  SELECT id, 1,
+    CASE WHEN EXISTS (SELECT 1 FROM power_plant AS original WHERE original.name = power_plant.name AND original.id < power_plant.id)
+      THEN substr(name, 1, 230) || ' (' || id || ')'
+      ELSE name
+    END,
+    latitude, longitude, timezone, created_at, updated_at FROM power_plant;
+DROP TABLE power_plant;
+ALTER TABLE power_plant_new RENAME TO power_plant;
+CREATE UNIQUE INDEX uk_power_plant_organization_id_name ON power_plant (organization_id, name);
+
+CREATE TABLE api_key_new (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  organization_id INTEGER NOT NULL REFERENCES organization (id),
+  name TEXT NOT NULL,
+  key_prefix TEXT NOT NULL,
+  key_hash TEXT NOT NULL,
+  scopes TEXT NOT NULL,
+  created_by TEXT NOT NULL,
+  expires_at DATETIME NULL,
+  last_used_at DATETIME NULL,
+  revoked_at DATETIME NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT uk_api_key_key_hash UNIQUE (key_hash)
+);
+INSERT INTO api_key_new (id, organization_id, name, key_prefix, key_hash, scopes, created_by, expires_at, last_used_at, revoked_at, created_at)
+  SELECT id, 1, name, key_prefix, key_hash, scopes, created_by, expires_at, last_used_at, revoked_at, created_at FROM api_key;
+DROP TABLE api_key;
+ALTER TABLE api_key_new RENAME TO api_key;
\ No newline at end of file
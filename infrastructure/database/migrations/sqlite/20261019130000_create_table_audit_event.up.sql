@@ -0,0 +1,14 @@
+CREATE TABLE audit_event (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  organization_id INTEGER NOT NULL REFERENCES organization (id),
+  entity_type TEXT NOT NULL,
+  entity_id TEXT NOT NULL,
+  action TEXT NOT NULL,
+  actor TEXT NOT NULL,
+  request_id TEXT NOT NULL DEFAULT '',
+  before_state TEXT NULL,
+  after_state TEXT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_event_entity ON audit_event (organization_id, entity_type, entity_id, id);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_power_plant_location;
\ No newline at end of file
@@ -0,0 +1,2 @@
+-- SQLite has no spatial types, a plain index serves bounding box lookups.
+CREATE INDEX idx_power_plant_location ON power_plant (organization_id, latitude, longitude);
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS portfolio_member;
+DROP TABLE IF EXISTS portfolio;
\ No newline at end of file
@@ -0,0 +1,18 @@
+CREATE TABLE portfolio (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  organization_id INTEGER NOT NULL REFERENCES organization (id),
+  name TEXT NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT uk_portfolio_organization_id_name UNIQUE (organization_id, name)
+);
+
+-- A member leaves the portfolio when the portfolio or the plant is deleted.
+CREATE TABLE portfolio_member (
+  portfolio_id INTEGER NOT NULL REFERENCES portfolio (id) ON DELETE CASCADE,
+  power_plant_id INTEGER NOT NULL REFERENCES power_plant (id) ON DELETE CASCADE,
+  capacity_mw REAL NOT NULL,
+  PRIMARY KEY (portfolio_id, power_plant_id)
+);
+
+CREATE INDEX idx_portfolio_member_power_plant_id ON portfolio_member (power_plant_id);
\ No newline at end of file
@@ -0,0 +1,154 @@
+package export
+
+import (
+	"net/http"
+	"strings"
+	"tensor-graphql/internal/model"
+	forecastusecase "tensor-graphql/internal/usecase/forecast"
+	powerplantusecase "tensor-graphql/internal/usecase/power_plant"
+	"tensor-graphql/pkg/datatype"
+	"tensor-graphql/pkg/derrors"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Handler serves the file exports of the organization of the caller.
+type Handler struct {
+	powerplantUsecase powerplantusecase.PowerPlantUsecase
+	forecastUsecase   forecastusecase.ForecastUsecase
+}
+
+func NewHandler(powerplantUsecase powerplantusecase.PowerPlantUsecase, forecastUsecase forecastusecase.ForecastUsecase) *Handler {
+	return &Handler{
+		powerplantUsecase: powerplantUsecase,
+		forecastUsecase:   forecastUsecase,
+	}
+}
+
+// PowerPlantsGeoJSON streams every power plant as a GeoJSON FeatureCollection.
+func (h *Handler) PowerPlantsGeoJSON(c echo.Context) error {
+	w := &responseWriter{
+		response:    c.Response(),
+		contentType: "application/geo+json",
+		filename:    "power-plants.geojson",
+	}
+
+	err := h.powerplantUsecase.ExportPowerPlantsGeoJSON(c.Request().Context(), w)
+	return exportError(w, err)
+}
+
+// Forecasts streams the hourly forecasts of the plants given by the plantIds
+// query parameter between startDate and endDate inclusive, as CSV or Parquet
+// depending on format. variables lists the forecast columns, all of them by
+// default. Lists are comma separated or repeated parameters.
+func (h *Handler) Forecasts(c echo.Context) error {
+	export := &model.ForecastExport{
+		PlantIDs: queryList(c, "plantIds"),
+		Format:   model.ExportFormat(strings.ToLower(c.QueryParam("format"))),
+	}
+	if export.Format == "" {
+		export.Format = model.ExportFormatCSV
+	}
+
+	var fields []derrors.FieldError
+	for _, date := range []struct {
+		param string
+		value *datatype.Date
+	}{
+		{param: "startDate", value: &export.StartDate},
+		{param: "endDate", value: &export.EndDate},
+	} {
+		if c.QueryParam(date.param) == "" {
+			continue
+		}
+		parsed, err := datatype.ParseDate(c.QueryParam(date.param), "UTC")
+		if err != nil {
+			fields = append(fields, derrors.FieldError{Field: date.param, Message: date.param + " must be a date formatted as YYYY-MM-DD"})
+			continue
+		}
+		*date.value = parsed
+	}
+	if len(fields) > 0 {
+		return exportError(nil, derrors.NewWithFields(derrors.InvalidArgument, fields, "invalid forecast export"))
+	}
+
+	export.Variables = model.ForecastVariables
+	if variables := queryList(c, "variables"); len(variables) > 0 {
+		export.Variables = make([]model.ForecastVariable, 0, len(variables))
+		for _, variable := range variables {
+			export.Variables = append(export.Variables, model.ForecastVariable(variable))
+		}
+	}
+
+	w := &responseWriter{
+		response:    c.Response(),
+		contentType: "text/csv; charset=utf-8",
+		filename:    "forecasts.csv",
+	}
+	if export.Format == model.ExportFormatParquet {
+		w.contentType = "application/vnd.apache.parquet"
+		w.filename = "forecasts.parquet"
+	}
+
+	err := h.forecastUsecase.ExportForecasts(c.Request().Context(), export, w)
+	return exportError(w, err)
+}
+
+// exportError turns an error raised before anything was written into a
+// response with its status and field errors. Once the body has started the
+// status can no longer change, the client sees a truncated file.
+func exportError(w *responseWriter, err error) error {
+	if err == nil || (w != nil && w.started) {
+		return err
+	}
+
+	status := derrors.ToStatus(err)
+	body := map[string]interface{}{
+		"message": http.StatusText(status),
+	}
+	if fields := derrors.FieldsOf(err); len(fields) > 0 {
+		details := make([]map[string]string, 0, len(fields))
+		for _, field := range fields {
+			details = append(details, map[string]string{
+				"field":   field.Field,
+				"message": field.Message,
+			})
+		}
+		body["fields"] = details
+	}
+
+	return echo.NewHTTPError(status, body).SetInternal(err)
+}
+
+// queryList returns the values of a query parameter given as a comma
+// separated list, repeatedly or both.
+func queryList(c echo.Context, name string) []string {
+	var values []string
+	for _, param := range c.QueryParams()[name] {
+		for _, value := range strings.Split(param, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// responseWriter writes the headers with the first bytes of the body, so an
+// error raised before anything was written still gets its own status.
+type responseWriter struct {
+	response    *echo.Response
+	contentType string
+	filename    string
+	started     bool
+}
+
+func (w *responseWriter) Write(data []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		w.response.Header().Set(echo.HeaderContentType, w.contentType)
+		w.response.Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+w.filename+`"`)
+		w.response.WriteHeader(http.StatusOK)
+	}
+	return w.response.Write(data)
+}
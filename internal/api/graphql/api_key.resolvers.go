@@ -0,0 +1,34 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.66
+
+import (
+	"context"
+	"tensor-graphql/internal/model"
+	"tensor-graphql/pkg/datatype"
+)
+
+// CreateAPIKey is the resolver for the createApiKey field.
+func (r *mutationResolver) CreateAPIKey(ctx context.Context, name string, scopes []model.Role, expiresAt *datatype.Time) (*model.CreatedAPIKey, error) {
+	apiKey, key, err := r.APIKeyUsecase.CreateAPIKey(ctx, name, scopes, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CreatedAPIKey{
+		APIKey: apiKey,
+		Key:    key,
+	}, nil
+}
+
+// RevokeAPIKey is the resolver for the revokeApiKey field.
+func (r *mutationResolver) RevokeAPIKey(ctx context.Context, id string) (*model.APIKey, error) {
+	return r.APIKeyUsecase.RevokeAPIKey(ctx, id)
+}
+
+// APIKeys is the resolver for the apiKeys field.
+func (r *queryResolver) APIKeys(ctx context.Context) ([]*model.APIKey, error) {
+	return r.APIKeyUsecase.GetAPIKeys(ctx)
+}
@@ -0,0 +1,15 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.66
+
+import (
+	"context"
+	"tensor-graphql/internal/model"
+)
+
+// AuditTrail is the resolver for the auditTrail field.
+func (r *queryResolver) AuditTrail(ctx context.Context, plantID string) ([]*model.AuditEvent, error) {
+	return r.PowerPlantUsecase.GetAuditTrail(ctx, plantID)
+}
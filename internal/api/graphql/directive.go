@@ -0,0 +1,26 @@
+package graphql
+
+import (
+	"context"
+	"tensor-graphql/internal/auth"
+	"tensor-graphql/internal/model"
+	"tensor-graphql/pkg/derrors"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// HasRole implements the @hasRole directive, the caller must hold role or a
+// role ranked above it.
+func HasRole(ctx context.Context, obj interface{}, next graphql.Resolver, role model.Role) (interface{}, error) {
+	principal, err := auth.RequirePrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	required, ok := auth.ParseRole(role.String())
+	if !ok || !principal.HasRole(required) {
+		return nil, derrors.New(derrors.Forbidden, "%s role required", required)
+	}
+
+	return next(ctx)
+}
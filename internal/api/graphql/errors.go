@@ -0,0 +1,49 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"tensor-graphql/pkg/derrors"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+var errorCodes = map[derrors.ErrorCode]string{
+	derrors.Unknown:         "INTERNAL_SERVER_ERROR",
+	derrors.NotFound:        "NOT_FOUND",
+	derrors.InvalidArgument: "BAD_USER_INPUT",
+	derrors.Duplicate:       "DUPLICATE",
+	derrors.Unauthorized:    "UNAUTHENTICATED",
+	derrors.Forbidden:       "FORBIDDEN",
+	derrors.Conflict:        "CONFLICT",
+}
+
+// ErrorPresenter adds the derrors code and per-field details of err to the
+// extensions of the GraphQL error.
+func ErrorPresenter(ctx context.Context, err error) *gqlerror.Error {
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+
+	var derr *derrors.Error
+	if !errors.As(err, &derr) {
+		return gqlErr
+	}
+
+	if gqlErr.Extensions == nil {
+		gqlErr.Extensions = map[string]interface{}{}
+	}
+	gqlErr.Extensions["code"] = errorCodes[derr.Code()]
+
+	if fields := derrors.FieldsOf(err); len(fields) > 0 {
+		details := make([]map[string]string, 0, len(fields))
+		for _, field := range fields {
+			details = append(details, map[string]string{
+				"field":   field.Field,
+				"message": field.Message,
+			})
+		}
+		gqlErr.Extensions["fields"] = details
+	}
+
+	return gqlErr
+}
@@ -0,0 +1,121 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.66
+
+import (
+	"context"
+	"tensor-graphql/internal/model"
+	"tensor-graphql/pkg/derrors"
+)
+
+// CreatePortfolio is the resolver for the createPortfolio field.
+func (r *mutationResolver) CreatePortfolio(ctx context.Context, name string, members []*model.PortfolioMember) (*model.Portfolio, error) {
+	portfolio := &model.Portfolio{
+		Name:    name,
+		Members: members,
+	}
+
+	err := r.PortfolioUsecase.CreatePortfolio(ctx, portfolio)
+	if err != nil {
+		return nil, err
+	}
+
+	return portfolio, nil
+}
+
+// UpdatePortfolio is the resolver for the updatePortfolio field.
+func (r *mutationResolver) UpdatePortfolio(ctx context.Context, id string, name *string, members []*model.PortfolioMember) (*model.Portfolio, error) {
+	portfolio, err := r.PortfolioUsecase.GetPortfolioByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if portfolio == nil {
+		return nil, derrors.New(derrors.NotFound, "portfolio %q not found", id)
+	}
+
+	// Only the given fields change, given members replace all current ones.
+	if name != nil {
+		portfolio.Name = *name
+	}
+	if members != nil {
+		portfolio.Members = members
+	}
+
+	err = r.PortfolioUsecase.UpdatePortfolio(ctx, portfolio)
+	if err != nil {
+		return nil, err
+	}
+
+	// Re-read the portfolio for the members in their stored order.
+	portfolio, err = r.PortfolioUsecase.GetPortfolioByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if portfolio == nil {
+		return nil, derrors.New(derrors.NotFound, "portfolio %q not found", id)
+	}
+
+	return portfolio, nil
+}
+
+// DeletePortfolio is the resolver for the deletePortfolio field.
+func (r *mutationResolver) DeletePortfolio(ctx context.Context, id string) (bool, error) {
+	err := r.PortfolioUsecase.DeletePortfolio(ctx, id)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Plants is the resolver for the plants field.
+func (r *portfolioResolver) Plants(ctx context.Context, obj *model.Portfolio) ([]*model.PowerPlant, error) {
+	return r.PortfolioUsecase.GetPortfolioPlants(ctx, obj)
+}
+
+// MeanTemperature is the resolver for the meanTemperature field.
+func (r *portfolioResolver) MeanTemperature(ctx context.Context, obj *model.Portfolio) (*float64, error) {
+	weather, err := r.portfolioWeather(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return weather.MeanTemperature, nil
+}
+
+// TotalPrecipitation is the resolver for the totalPrecipitation field.
+func (r *portfolioResolver) TotalPrecipitation(ctx context.Context, obj *model.Portfolio) (*float64, error) {
+	weather, err := r.portfolioWeather(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return weather.TotalPrecipitation, nil
+}
+
+// MaxWindSpeed is the resolver for the maxWindSpeed field.
+func (r *portfolioResolver) MaxWindSpeed(ctx context.Context, obj *model.Portfolio) (*float64, error) {
+	weather, err := r.portfolioWeather(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return weather.MaxWindSpeed, nil
+}
+
+// Portfolio is the resolver for the portfolio field.
+func (r *queryResolver) Portfolio(ctx context.Context, id string) (*model.Portfolio, error) {
+	return r.PortfolioUsecase.GetPortfolioByID(ctx, id)
+}
+
+// Portfolios is the resolver for the portfolios field.
+func (r *queryResolver) Portfolios(ctx context.Context) ([]*model.Portfolio, error) {
+	return r.PortfolioUsecase.GetPortfolios(ctx)
+}
+
+// Portfolio returns PortfolioResolver implementation.
+func (r *Resolver) Portfolio() PortfolioResolver { return &portfolioResolver{r} }
+
+type portfolioResolver struct{ *Resolver }
@@ -6,7 +6,13 @@ package graphql
 
 import (
 	"context"
+	"tensor-graphql/internal/library/openmeteo"
 	"tensor-graphql/internal/model"
+	"tensor-graphql/pkg/datatype"
+	"tensor-graphql/pkg/derrors"
+	"tensor-graphql/pkg/geo"
+
+	"github.com/99designs/gqlgen/graphql"
 )
 
 // CreatePowerPlant is the resolver for the createPowerPlant field.
@@ -22,49 +28,160 @@ func (r *mutationResolver) CreatePowerPlant(ctx context.Context, name string, la
 		return nil, err
 	}
 
-	weather, err := r.OpenmeteoLib.GetWeatherForecast(ctx, plant.Latitude, plant.Longitude, 7)
+	return plant, nil
+}
+
+// UpdatePowerPlant is the resolver for the updatePowerPlant field.
+func (r *mutationResolver) UpdatePowerPlant(ctx context.Context, id string, version int, name *string, latitude *float64, longitude *float64) (*model.PowerPlant, error) {
+	plant, err := r.PowerPlantUsecase.GetPowerPlantByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	if plant == nil {
+		return nil, derrors.New(derrors.NotFound, "power plant %q not found", id)
+	}
 
-	return mapToModel(plant, weather)
-}
-
-// UpdatePowerPlant is the resolver for the updatePowerPlant field.
-func (r *mutationResolver) UpdatePowerPlant(ctx context.Context, id string, name *string, latitude *float64, longitude *float64) (*model.PowerPlant, error) {
-	plant := &model.PowerPlant{
-		ID:        id,
-		Name:      *name,
-		Latitude:  *latitude,
-		Longitude: *longitude,
+	// Only the given fields change, the timezone is detected again when the
+	// plant moves.
+	plant.Version = version
+	if name != nil {
+		plant.Name = *name
+	}
+	if latitude != nil && *latitude != plant.Latitude {
+		plant.Latitude = *latitude
+		plant.Timezone = ""
+	}
+	if longitude != nil && *longitude != plant.Longitude {
+		plant.Longitude = *longitude
+		plant.Timezone = ""
 	}
 
-	err := r.PowerPlantUsecase.UpdatePowerPlant(ctx, plant)
+	err = r.PowerPlantUsecase.UpdatePowerPlant(ctx, plant)
 	if err != nil {
 		return nil, err
 	}
 
-	weather, err := r.OpenmeteoLib.GetWeatherForecast(ctx, plant.Latitude, plant.Longitude, 7)
+	// Re-read the plant to pick up the timestamps maintained by the database.
+	plant, err = r.PowerPlantUsecase.GetPowerPlantByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	if plant == nil {
+		return nil, derrors.New(derrors.NotFound, "power plant %q not found", id)
+	}
 
-	return mapToModel(plant, weather)
+	return plant, nil
 }
 
-// PowerPlant is the resolver for the powerPlant field.
-func (r *queryResolver) PowerPlant(ctx context.Context, id string) (*model.PowerPlant, error) {
-	plant, err := r.PowerPlantUsecase.GetPowerPlantByID(ctx, id)
+// DeletePowerPlant is the resolver for the deletePowerPlant field.
+func (r *mutationResolver) DeletePowerPlant(ctx context.Context, id string) (bool, error) {
+	err := r.PowerPlantUsecase.DeletePowerPlant(ctx, id)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CreatePowerPlants is the resolver for the createPowerPlants field.
+func (r *mutationResolver) CreatePowerPlants(ctx context.Context, input []*model.CreatePowerPlantInput) (*model.PowerPlantBatch, error) {
+	plants := make([]*model.PowerPlant, 0, len(input))
+	for _, item := range input {
+		plants = append(plants, &model.PowerPlant{
+			Name:      item.Name,
+			Latitude:  item.Latitude,
+			Longitude: item.Longitude,
+		})
+	}
+
+	return r.PowerPlantUsecase.CreatePowerPlants(ctx, plants)
+}
+
+// UpdatePowerPlants is the resolver for the updatePowerPlants field.
+func (r *mutationResolver) UpdatePowerPlants(ctx context.Context, input []*model.UpdatePowerPlantInput) (*model.PowerPlantBatch, error) {
+	return r.PowerPlantUsecase.UpdatePowerPlants(ctx, input)
+}
+
+// ImportPowerPlants is the resolver for the importPowerPlants field.
+func (r *mutationResolver) ImportPowerPlants(ctx context.Context, csv graphql.Upload, dryRun *bool) (*model.PowerPlantImport, error) {
+	return r.PowerPlantUsecase.ImportPowerPlants(ctx, csv.File, dryRun != nil && *dryRun)
+}
+
+// WeatherForecasts is the resolver for the weatherForecasts field.
+func (r *powerPlantResolver) WeatherForecasts(ctx context.Context, obj *model.PowerPlant, forecastDays *int, startDate *datatype.Date, endDate *datatype.Date) ([]*model.WeatherForecast, error) {
+	var (
+		weather *openmeteo.WeatherResponse
+		err     error
+	)
+
+	switch {
+	case startDate != nil || endDate != nil:
+		if startDate == nil || endDate == nil {
+			return nil, derrors.New(derrors.InvalidArgument, "startDate and endDate must be provided together")
+		}
+		if endDate.IsBefore(*startDate) {
+			return nil, derrors.New(derrors.InvalidArgument, "endDate must not be before startDate")
+		}
+		weather, err = r.OpenmeteoLib.GetWeatherForecastBetween(ctx, obj.Latitude, obj.Longitude, *startDate, *endDate, obj.Timezone)
+	case forecastDays == nil || *forecastDays == 7:
+		// The default forecast is shared with the weather fields of the plant.
+		weather, err = r.currentWeather(ctx, obj)
+	default:
+		if *forecastDays < 1 || *forecastDays > 16 {
+			return nil, derrors.New(derrors.InvalidArgument, "forecastDays must be between 1 and 16")
+		}
+		weather, err = r.OpenmeteoLib.GetWeatherForecast(ctx, obj.Latitude, obj.Longitude, *forecastDays, obj.Timezone)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	weather, err := r.OpenmeteoLib.GetWeatherForecast(ctx, plant.Latitude, plant.Longitude, 7)
+	return mapToForecasts(obj, weather)
+}
+
+// HasPrecipitationToday is the resolver for the hasPrecipitationToday field.
+func (r *powerPlantResolver) HasPrecipitationToday(ctx context.Context, obj *model.PowerPlant) (bool, error) {
+	mm, _, err := r.precipitationToday(ctx, obj)
+	if err != nil {
+		return false, err
+	}
+
+	return mm > 0, nil
+}
+
+// PrecipitationTodayMm is the resolver for the precipitationTodayMm field.
+func (r *powerPlantResolver) PrecipitationTodayMm(ctx context.Context, obj *model.PowerPlant) (float64, error) {
+	mm, _, err := r.precipitationToday(ctx, obj)
+	if err != nil {
+		return 0, err
+	}
+
+	return mm, nil
+}
+
+// FirstPrecipitationAt is the resolver for the firstPrecipitationAt field.
+func (r *powerPlantResolver) FirstPrecipitationAt(ctx context.Context, obj *model.PowerPlant) (*datatype.Time, error) {
+	_, first, err := r.precipitationToday(ctx, obj)
 	if err != nil {
 		return nil, err
 	}
 
-	return mapToModel(plant, weather)
+	return first, nil
+}
+
+// Elevation is the resolver for the elevation field.
+func (r *powerPlantResolver) Elevation(ctx context.Context, obj *model.PowerPlant) (float64, error) {
+	weather, err := r.currentWeather(ctx, obj)
+	if err != nil {
+		return 0, err
+	}
+
+	return weather.Elevation, nil
+}
+
+// PowerPlant is the resolver for the powerPlant field.
+func (r *queryResolver) PowerPlant(ctx context.Context, id string) (*model.PowerPlant, error) {
+	return r.PowerPlantUsecase.GetPowerPlantByID(ctx, id)
 }
 
 // PowerPlants is the resolver for the powerPlants field.
@@ -83,32 +200,48 @@ func (r *queryResolver) PowerPlants(ctx context.Context, page *int, pageSize *in
 		return nil, err
 	}
 
-	var modelPlants []*model.PowerPlant
-	for _, plant := range plants {
-		weather, err := r.OpenmeteoLib.GetWeatherForecast(ctx, plant.Latitude, plant.Longitude, 7)
-		if err != nil {
-			return nil, err
-		}
-		mapped, err := mapToModel(plant, weather)
-		if err != nil {
-			return nil, err
-		}
-		modelPlants = append(modelPlants, mapped)
-	}
-
 	return &model.PowerPlantPage{
-		Plants:     modelPlants,
+		Plants:     plants,
 		TotalCount: total,
 		Page:       *page,
 		PageSize:   *pageSize,
 	}, nil
 }
 
+// NearbyPowerPlants is the resolver for the nearbyPowerPlants field.
+func (r *queryResolver) NearbyPowerPlants(ctx context.Context, latitude float64, longitude float64, radiusKm float64, limit *int) ([]*model.PowerPlant, error) {
+	if limit == nil {
+		limit = new(int)
+		*limit = 10
+	}
+
+	return r.PowerPlantUsecase.GetNearbyPowerPlants(ctx, latitude, longitude, radiusKm, *limit)
+}
+
+// PowerPlantsInBounds is the resolver for the powerPlantsInBounds field.
+func (r *queryResolver) PowerPlantsInBounds(ctx context.Context, south float64, west float64, north float64, east float64) (*model.PowerPlantRegion, error) {
+	return r.PowerPlantUsecase.GetPowerPlantsInBounds(ctx, geo.Bounds{South: south, West: west, North: north, East: east})
+}
+
+// PowerPlantsInPolygon is the resolver for the powerPlantsInPolygon field.
+func (r *queryResolver) PowerPlantsInPolygon(ctx context.Context, geojson string) (*model.PowerPlantRegion, error) {
+	return r.PowerPlantUsecase.GetPowerPlantsInPolygon(ctx, geojson)
+}
+
+// PowerPlantsGeoJSON is the resolver for the powerPlantsGeoJSON field.
+func (r *queryResolver) PowerPlantsGeoJSON(ctx context.Context) (string, error) {
+	return r.PowerPlantUsecase.GetPowerPlantsGeoJSON(ctx)
+}
+
 // Mutation returns MutationResolver implementation.
 func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
 
+// PowerPlant returns PowerPlantResolver implementation.
+func (r *Resolver) PowerPlant() PowerPlantResolver { return &powerPlantResolver{r} }
+
 // Query returns QueryResolver implementation.
 func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 
 type mutationResolver struct{ *Resolver }
+type powerPlantResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
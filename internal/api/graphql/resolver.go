@@ -1,42 +1,96 @@
 package graphql
 
 import (
+	"context"
+	"math"
+	"time"
+
 	"tensor-graphql/internal/library/openmeteo"
 	"tensor-graphql/internal/model"
+	apikeyusecase "tensor-graphql/internal/usecase/api_key"
+	portfoliousecase "tensor-graphql/internal/usecase/portfolio"
 	usecase "tensor-graphql/internal/usecase/power_plant"
+	"tensor-graphql/pkg/datatype"
 )
 
 // Resolver adalah root resolver yang menyimpan dependency usecase.
 type Resolver struct {
 	PowerPlantUsecase usecase.PowerPlantUsecase
+	APIKeyUsecase     apikeyusecase.APIKeyUsecase
+	PortfolioUsecase  portfoliousecase.PortfolioUsecase
 	OpenmeteoLib      openmeteo.OpenMeteo
 }
 
-func NewResolver(powerplantUsecase usecase.PowerPlantUsecase, openmeteoLib openmeteo.OpenMeteo) *Resolver {
+func NewResolver(powerplantUsecase usecase.PowerPlantUsecase, apikeyUsecase apikeyusecase.APIKeyUsecase, portfolioUsecase portfoliousecase.PortfolioUsecase, openmeteoLib openmeteo.OpenMeteo) *Resolver {
 	return &Resolver{
 		PowerPlantUsecase: powerplantUsecase,
+		APIKeyUsecase:     apikeyUsecase,
+		PortfolioUsecase:  portfolioUsecase,
 		OpenmeteoLib:      openmeteoLib,
 	}
 }
 
-func mapToModel(plant *model.PowerPlant, weather *openmeteo.WeatherResponse) (*model.PowerPlant, error) {
-	mp := &model.PowerPlant{
-		ID:        plant.ID,
-		Name:      plant.Name,
-		Latitude:  plant.Latitude,
-		Longitude: plant.Longitude,
-		Elevation: weather.Elevation,
+// precipitationToday sums the precipitation forecast for the local day of
+// plant rather than the UTC day, every hour of that day counts. first is the
+// first hour with precipitation, nil when the day stays dry.
+func (r *Resolver) precipitationToday(ctx context.Context, plant *model.PowerPlant) (mm float64, first *datatype.Time, err error) {
+	weather, err := r.currentWeather(ctx, plant)
+	if err != nil {
+		return 0, nil, err
 	}
 
-	forecastDays := 7
-	if len(weather.Hourly.Time) < forecastDays {
-		forecastDays = len(weather.Hourly.Time)
+	loc := openmeteo.LoadLocation(plant.Timezone)
+	now := time.Now().In(loc)
+	for i := range weather.Hourly.Time {
+		forecastTime, err := openmeteo.ParseTime(weather.Hourly.Time[i], loc)
+		if err != nil {
+			return 0, nil, err
+		}
+		if !isSameDay(*forecastTime.Time(), now) || weather.Hourly.Precipitation[i] <= 0 {
+			continue
+		}
+
+		mm += weather.Hourly.Precipitation[i]
+		if first == nil {
+			first = &forecastTime
+		}
 	}
 
-	var forecasts []*model.WeatherForecast
-	for i := 0; i < forecastDays; i++ {
+	// openmeteo reports precipitation with a 0.1 mm resolution.
+	return math.Round(mm*10) / 10, first, nil
+}
+
+// portfolioWeather aggregates the forecasts of the plants of portfolio once
+// per operation, however many of its weather fields are asked for.
+func (r *Resolver) portfolioWeather(ctx context.Context, portfolio *model.Portfolio) (*model.PortfolioWeather, error) {
+	loader := r.weatherLoader(ctx)
+	return share(&loader.mu, loader.portfolios, portfolio, func() (*model.PortfolioWeather, error) {
+		plants, err := r.PortfolioUsecase.GetPortfolioPlants(ctx, portfolio)
+		if err != nil {
+			return nil, err
+		}
+
+		forecasts, err := loader.forecasts(ctx, plants)
+		if err != nil {
+			return nil, err
+		}
+
+		return r.PortfolioUsecase.AggregateForecasts(portfolio, forecasts), nil
+	})
+}
+
+func mapToForecasts(plant *model.PowerPlant, weather *openmeteo.WeatherResponse) ([]*model.WeatherForecast, error) {
+	loc := openmeteo.LoadLocation(plant.Timezone)
+
+	forecasts := make([]*model.WeatherForecast, 0, len(weather.Hourly.Time))
+	for i := range weather.Hourly.Time {
+		forecastTime, err := openmeteo.ParseTime(weather.Hourly.Time[i], loc)
+		if err != nil {
+			return nil, err
+		}
+
 		wf := &model.WeatherForecast{
-			Time:          weather.Hourly.Time[i],
+			Time:          forecastTime,
 			Temperature:   weather.Hourly.Temperature2m[i],
 			Precipitation: weather.Hourly.Precipitation[i],
 			WindSpeed:     weather.Hourly.WindSpeed10m[i],
@@ -44,13 +98,12 @@ func mapToModel(plant *model.PowerPlant, weather *openmeteo.WeatherResponse) (*m
 		}
 		forecasts = append(forecasts, wf)
 	}
-	mp.WeatherForecasts = forecasts
 
-	if len(weather.Hourly.Precipitation) > 0 && weather.Hourly.Precipitation[0] > 0 {
-		mp.HasPrecipitationToday = true
-	} else {
-		mp.HasPrecipitationToday = false
-	}
+	return forecasts, nil
+}
 
-	return mp, nil
+func isSameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
 }
@@ -0,0 +1,141 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+
+	"tensor-graphql/internal/library/openmeteo"
+	"tensor-graphql/internal/model"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// weatherConcurrency bounds the forecasts fetched at once for one operation.
+const weatherConcurrency = 8
+
+type weatherLoaderKey struct{}
+
+type (
+	// weatherLoader fetches the 7 day forecast of every location of one
+	// operation at most once. gqlgen resolves the fields of list elements
+	// concurrently, so the fetches are bounded by weatherConcurrency.
+	weatherLoader struct {
+		openmeteoLib *openmeteo.OpenMeteo
+		sem          chan struct{}
+
+		mu         sync.Mutex
+		weather    map[weatherLocation]*loaderCall[*openmeteo.WeatherResponse]
+		portfolios map[*model.Portfolio]*loaderCall[*model.PortfolioWeather]
+	}
+
+	weatherLocation struct {
+		latitude  float64
+		longitude float64
+		timezone  string
+	}
+
+	// loaderCall is a load shared by every resolver asking for the same key.
+	loaderCall[V any] struct {
+		done  chan struct{}
+		value V
+		err   error
+	}
+)
+
+func newWeatherLoader(openmeteoLib *openmeteo.OpenMeteo) *weatherLoader {
+	return &weatherLoader{
+		openmeteoLib: openmeteoLib,
+		sem:          make(chan struct{}, weatherConcurrency),
+		weather:      make(map[weatherLocation]*loaderCall[*openmeteo.WeatherResponse]),
+		portfolios:   make(map[*model.Portfolio]*loaderCall[*model.PortfolioWeather]),
+	}
+}
+
+// WeatherLoader is an operation middleware giving every operation its own
+// weather loader, so a plant listed twice is only fetched once.
+func (r *Resolver) WeatherLoader(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	return next(context.WithValue(ctx, weatherLoaderKey{}, newWeatherLoader(&r.OpenmeteoLib)))
+}
+
+// weatherLoader returns the loader of the operation on ctx, or a new one when
+// the resolver runs outside of an operation.
+func (r *Resolver) weatherLoader(ctx context.Context) *weatherLoader {
+	loader, ok := ctx.Value(weatherLoaderKey{}).(*weatherLoader)
+	if !ok {
+		loader = newWeatherLoader(&r.OpenmeteoLib)
+	}
+	return loader
+}
+
+// currentWeather returns the 7 day forecast of plant.
+func (r *Resolver) currentWeather(ctx context.Context, plant *model.PowerPlant) (*openmeteo.WeatherResponse, error) {
+	return r.weatherLoader(ctx).load(ctx, plant)
+}
+
+func (l *weatherLoader) load(ctx context.Context, plant *model.PowerPlant) (*openmeteo.WeatherResponse, error) {
+	key := weatherLocation{latitude: plant.Latitude, longitude: plant.Longitude, timezone: plant.Timezone}
+	return share(&l.mu, l.weather, key, func() (*openmeteo.WeatherResponse, error) {
+		select {
+		case l.sem <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		defer func() { <-l.sem }()
+
+		return l.openmeteoLib.GetWeatherForecast(ctx, plant.Latitude, plant.Longitude, 7, plant.Timezone)
+	})
+}
+
+// forecasts fetches the hourly forecasts of the plants concurrently, keyed by
+// plant ID.
+func (l *weatherLoader) forecasts(ctx context.Context, plants []*model.PowerPlant) (map[string][]*model.WeatherForecast, error) {
+	forecasts := make([][]*model.WeatherForecast, len(plants))
+	errs := make([]error, len(plants))
+
+	var wg sync.WaitGroup
+	for i, plant := range plants {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			weather, err := l.load(ctx, plant)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			forecasts[i], errs[i] = mapToForecasts(plant, weather)
+		}()
+	}
+	wg.Wait()
+
+	byID := make(map[string][]*model.WeatherForecast, len(plants))
+	for i, plant := range plants {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		byID[plant.ID] = forecasts[i]
+	}
+
+	return byID, nil
+}
+
+// share runs load once per key of calls, concurrent callers of the same key
+// wait for the first one and get its result.
+func share[K comparable, V any](mu *sync.Mutex, calls map[K]*loaderCall[V], key K, load func() (V, error)) (V, error) {
+	mu.Lock()
+	call, ok := calls[key]
+	if !ok {
+		call = &loaderCall[V]{done: make(chan struct{})}
+		calls[key] = call
+	}
+	mu.Unlock()
+
+	if ok {
+		<-call.done
+		return call.value, call.err
+	}
+
+	defer close(call.done)
+	call.value, call.err = load()
+	return call.value, call.err
+}
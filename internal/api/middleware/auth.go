@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"tensor-graphql/internal/auth"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Authenticate resolves the principal of each request with extractor and puts
+// it on the request context. Anonymous requests continue without a principal,
+// requests with invalid credentials are rejected.
+func Authenticate(extractor auth.PrincipalExtractor) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal, err := extractor.Extract(c.Request())
+			if err != nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, "invalid credentials")
+			}
+
+			if principal != nil {
+				ctx := auth.WithPrincipal(c.Request().Context(), principal)
+				c.SetRequest(c.Request().WithContext(ctx))
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// RequireRole rejects requests whose principal, put on the context by
+// Authenticate, does not hold role or a role ranked above it.
+func RequireRole(role auth.Role) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal, ok := auth.PrincipalFromContext(c.Request().Context())
+			if !ok {
+				return echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+			}
+			if !principal.HasRole(role) {
+				return echo.NewHTTPError(http.StatusForbidden, string(role)+" role required")
+			}
+
+			return next(c)
+		}
+	}
+}
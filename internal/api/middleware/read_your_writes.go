@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	repository "tensor-graphql/internal/repository/common"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ReadYourWrites routes the reads of a request to the master once the request
+// has written, so it does not read stale data from a lagging slave.
+func ReadYourWrites() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := repository.WithReadYourWrites(c.Request().Context())
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			return next(c)
+		}
+	}
+}
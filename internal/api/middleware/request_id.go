@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"tensor-graphql/pkg/requestid"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// RequestID reuses the X-Request-Id header of the request or generates a new
+// one, echoes it in the response and puts it on the request context.
+func RequestID() echo.MiddlewareFunc {
+	return middleware.RequestIDWithConfig(middleware.RequestIDConfig{
+		RequestIDHandler: func(c echo.Context, id string) {
+			ctx := requestid.WithRequestID(c.Request().Context(), id)
+			c.SetRequest(c.Request().WithContext(ctx))
+		},
+	})
+}
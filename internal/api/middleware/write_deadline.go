@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// WriteDeadline extends the write deadline of the response to timeout from
+// the start of the request, for streaming routes outlasting the WriteTimeout
+// of the server.
+func WriteDeadline(timeout time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			err := http.NewResponseController(c.Response()).SetWriteDeadline(time.Now().Add(timeout))
+			if err != nil && !errors.Is(err, http.ErrNotSupported) {
+				return err
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"tensor-graphql/infrastructure/config"
+	"tensor-graphql/pkg/derrors"
+)
+
+const (
+	bearerScheme = "Bearer "
+	apiKeyScheme = "ApiKey "
+
+	ModeJWT           = "jwt"
+	ModeTrustedHeader = "trusted_header"
+
+	DefaultSubjectHeader      = "X-Auth-Subject"
+	DefaultOrganizationHeader = "X-Auth-Organization"
+	DefaultRolesHeader        = "X-Auth-Roles"
+)
+
+type (
+	// PrincipalExtractor resolves the principal of an HTTP request. It returns
+	// a nil principal without error for anonymous requests.
+	PrincipalExtractor interface {
+		Extract(r *http.Request) (*Principal, error)
+	}
+
+	// APIKeyAuthenticator resolves the principal owning a plaintext API key.
+	APIKeyAuthenticator interface {
+		AuthenticateAPIKey(ctx context.Context, key string) (*Principal, error)
+	}
+
+	chainExtractor []PrincipalExtractor
+
+	jwtExtractor struct {
+		validator *JWTValidator
+	}
+
+	apiKeyExtractor struct {
+		authenticator APIKeyAuthenticator
+	}
+
+	trustedHeaderExtractor struct {
+		subjectHeader      string
+		organizationHeader string
+		rolesHeader        string
+	}
+)
+
+// NewPrincipalExtractor returns the extractor selected by conf.Mode.
+func NewPrincipalExtractor(conf *config.Auth) (extractor PrincipalExtractor, err error) {
+	defer derrors.Wrap(&err, "NewPrincipalExtractor(%q)", conf.Mode)
+
+	switch conf.Mode {
+	case "", ModeJWT:
+		validator, err := NewJWTValidator(conf)
+		if err != nil {
+			return nil, err
+		}
+		return NewJWTExtractor(validator), nil
+	case ModeTrustedHeader:
+		return NewTrustedHeaderExtractor(conf.SubjectHeader, conf.OrganizationHeader, conf.RolesHeader), nil
+	}
+
+	return nil, derrors.New(derrors.InvalidArgument, "unknown auth mode")
+}
+
+// NewJWTExtractor authenticates requests carrying an "Authorization: Bearer"
+// token.
+func NewJWTExtractor(validator *JWTValidator) PrincipalExtractor {
+	return &jwtExtractor{
+		validator: validator,
+	}
+}
+
+func (e *jwtExtractor) Extract(r *http.Request) (*Principal, error) {
+	token, ok := credentials(r, bearerScheme)
+	if !ok {
+		return nil, nil
+	}
+
+	return e.validator.Validate(token)
+}
+
+// NewAPIKeyExtractor authenticates requests carrying an "Authorization: ApiKey"
+// header.
+func NewAPIKeyExtractor(authenticator APIKeyAuthenticator) PrincipalExtractor {
+	return &apiKeyExtractor{
+		authenticator: authenticator,
+	}
+}
+
+func (e *apiKeyExtractor) Extract(r *http.Request) (*Principal, error) {
+	key, ok := credentials(r, apiKeyScheme)
+	if !ok {
+		return nil, nil
+	}
+
+	return e.authenticator.AuthenticateAPIKey(r.Context(), key)
+}
+
+// NewChainExtractor tries each extractor in turn and returns the first
+// principal found. An Authorization header that none of them accepts is
+// rejected rather than treated as anonymous.
+func NewChainExtractor(extractors ...PrincipalExtractor) PrincipalExtractor {
+	return chainExtractor(extractors)
+}
+
+func (c chainExtractor) Extract(r *http.Request) (*Principal, error) {
+	for _, extractor := range c {
+		principal, err := extractor.Extract(r)
+		if err != nil || principal != nil {
+			return principal, err
+		}
+	}
+
+	if r.Header.Get("Authorization") != "" {
+		return nil, derrors.New(derrors.Unauthorized, "unsupported authorization scheme")
+	}
+
+	return nil, nil
+}
+
+// NewTrustedHeaderExtractor reads the principal from headers set by an
+// authenticating proxy in front of the service. The roles header holds a comma
+// separated list of role names. Only use it when the proxy strips these
+// headers from client requests.
+func NewTrustedHeaderExtractor(subjectHeader, organizationHeader, rolesHeader string) PrincipalExtractor {
+	if subjectHeader == "" {
+		subjectHeader = DefaultSubjectHeader
+	}
+	if organizationHeader == "" {
+		organizationHeader = DefaultOrganizationHeader
+	}
+	if rolesHeader == "" {
+		rolesHeader = DefaultRolesHeader
+	}
+
+	return &trustedHeaderExtractor{
+		subjectHeader:      subjectHeader,
+		organizationHeader: organizationHeader,
+		rolesHeader:        rolesHeader,
+	}
+}
+
+func (e *trustedHeaderExtractor) Extract(r *http.Request) (*Principal, error) {
+	subject := strings.TrimSpace(r.Header.Get(e.subjectHeader))
+	if subject == "" {
+		return nil, nil
+	}
+
+	return &Principal{
+		Subject:        subject,
+		OrganizationID: strings.TrimSpace(r.Header.Get(e.organizationHeader)),
+		Roles:          ParseRoles(strings.Split(r.Header.Get(e.rolesHeader), ",")),
+	}, nil
+}
+
+// credentials returns the credentials of the Authorization header when it uses
+// scheme.
+func credentials(r *http.Request, scheme string) (string, bool) {
+	header := r.Header.Get("Authorization")
+	if len(header) < len(scheme) || !strings.EqualFold(header[:len(scheme)], scheme) {
+		return "", false
+	}
+
+	return strings.TrimSpace(header[len(scheme):]), true
+}
@@ -0,0 +1,57 @@
+package auth_test
+
+import (
+	"net/http/httptest"
+	"tensor-graphql/internal/auth"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrustedHeaderExtractor(t *testing.T) {
+	extractor := auth.NewTrustedHeaderExtractor("", "", "")
+
+	var testCases = []struct {
+		caseName string
+		headers  map[string]string
+		results  func(principal *auth.Principal, err error)
+	}{
+		{
+			caseName: "Extract_Operator",
+			headers: map[string]string{
+				auth.DefaultSubjectHeader:      "user-1",
+				auth.DefaultOrganizationHeader: "7",
+				auth.DefaultRolesHeader:        "Operator, unknown",
+			},
+			results: func(principal *auth.Principal, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, "user-1", principal.Subject)
+				assert.Equal(t, "7", principal.OrganizationID)
+				assert.True(t, principal.HasRole(auth.RoleViewer))
+				assert.True(t, principal.HasRole(auth.RoleOperator))
+				assert.False(t, principal.HasRole(auth.RoleAdmin))
+			},
+		},
+		{
+			caseName: "Extract_Anonymous",
+			headers: map[string]string{
+				auth.DefaultRolesHeader: "admin",
+			},
+			results: func(principal *auth.Principal, err error) {
+				assert.NoError(t, err)
+				assert.Nil(t, principal)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/graphql", nil)
+			for key, value := range testCase.headers {
+				req.Header.Set(key, value)
+			}
+			principal, err := extractor.Extract(req)
+			testCase.results(principal, err)
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"tensor-graphql/infrastructure/config"
+	"tensor-graphql/pkg/derrors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const jwtLeeway = 30 * time.Second
+
+type (
+	// JWTValidator verifies HS256 and RS256 signed tokens.
+	JWTValidator struct {
+		secret    []byte
+		publicKey *rsa.PublicKey
+		jwks      map[string]*rsa.PublicKey
+		parser    *jwt.Parser
+	}
+
+	jwtClaims struct {
+		jwt.RegisteredClaims
+		OrganizationID string   `json:"org_id"`
+		Roles          []string `json:"roles"`
+	}
+
+	jwks struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	jwk struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	}
+)
+
+// NewJWTValidator loads the verification keys configured in conf.
+func NewJWTValidator(conf *config.Auth) (validator *JWTValidator, err error) {
+	defer derrors.Wrap(&err, "NewJWTValidator")
+
+	validator = &JWTValidator{
+		jwks: map[string]*rsa.PublicKey{},
+	}
+
+	if conf.JWTSecret != "" {
+		validator.secret = []byte(conf.JWTSecret)
+	}
+
+	if conf.JWTPublicKeyFile != "" {
+		pem, err := os.ReadFile(conf.JWTPublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		validator.publicKey, err = jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if conf.JWKSFile != "" {
+		validator.jwks, err = loadJWKS(conf.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(jwtLeeway),
+	}
+	if conf.JWTIssuer != "" {
+		options = append(options, jwt.WithIssuer(conf.JWTIssuer))
+	}
+	if conf.JWTAudience != "" {
+		options = append(options, jwt.WithAudience(conf.JWTAudience))
+	}
+	validator.parser = jwt.NewParser(options...)
+
+	return validator, nil
+}
+
+// Validate verifies token and returns the principal it was issued for.
+func (v *JWTValidator) Validate(token string) (*Principal, error) {
+	claims := jwtClaims{}
+	_, err := v.parser.ParseWithClaims(token, &claims, v.key)
+	if err != nil {
+		return nil, derrors.WrapStack(err, derrors.Unauthorized, "invalid token")
+	}
+
+	if claims.Subject == "" {
+		return nil, derrors.New(derrors.Unauthorized, "invalid token: missing subject")
+	}
+
+	return &Principal{
+		Subject:        claims.Subject,
+		OrganizationID: claims.OrganizationID,
+		Roles:          ParseRoles(claims.Roles),
+	}, nil
+}
+
+// key picks the verification key matching the signing method of token.
+func (v *JWTValidator) key(token *jwt.Token) (interface{}, error) {
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		if v.secret == nil {
+			return nil, derrors.New(derrors.Unauthorized, "HS256 tokens are not accepted")
+		}
+		return v.secret, nil
+	case jwt.SigningMethodRS256.Alg():
+		if kid, ok := token.Header["kid"].(string); ok && kid != "" {
+			if key, ok := v.jwks[kid]; ok {
+				return key, nil
+			}
+			return nil, derrors.New(derrors.Unauthorized, "unknown key id %q", kid)
+		}
+		if v.publicKey == nil {
+			return nil, derrors.New(derrors.Unauthorized, "RS256 tokens without a key id are not accepted")
+		}
+		return v.publicKey, nil
+	}
+
+	return nil, derrors.New(derrors.Unauthorized, "unexpected signing method %q", token.Method.Alg())
+}
+
+// loadJWKS reads the RSA signing keys of a local JWKS file indexed by key id.
+func loadJWKS(path string) (keys map[string]*rsa.PublicKey, err error) {
+	defer derrors.Wrap(&err, "loadJWKS(%q)", path)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var set jwks
+	err = json.Unmarshal(content, &set)
+	if err != nil {
+		return nil, err
+	}
+
+	keys = make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, key := range set.Keys {
+		if key.Kty != "RSA" || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil {
+			return nil, err
+		}
+
+		keys[key.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,119 @@
+package auth_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"tensor-graphql/infrastructure/config"
+	"tensor-graphql/internal/auth"
+	"tensor-graphql/pkg/derrors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWTValidatorValidate(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	jwksContent, err := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA",
+				"kid": "test-key",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(jwksFile, jwksContent, 0o600))
+
+	validator, err := auth.NewJWTValidator(&config.Auth{
+		JWTSecret: "secret",
+		JWKSFile:  jwksFile,
+		JWTIssuer: "https://issuer.test",
+	})
+	require.NoError(t, err)
+
+	claims := func(expiresAt time.Time) jwt.RegisteredClaims {
+		return jwt.RegisteredClaims{
+			Subject:   "user-1",
+			Issuer:    "https://issuer.test",
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		}
+	}
+
+	sign := func(method jwt.SigningMethod, kid string, claims jwt.RegisteredClaims, key interface{}) string {
+		token := jwt.NewWithClaims(method, claims)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		require.NoError(t, err)
+		return signed
+	}
+
+	var testCases = []struct {
+		caseName string
+		token    string
+		results  func(principal *auth.Principal, err error)
+	}{
+		{
+			caseName: "Validate_HS256",
+			token:    sign(jwt.SigningMethodHS256, "", claims(time.Now().Add(time.Hour)), []byte("secret")),
+			results: func(principal *auth.Principal, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, "user-1", principal.Subject)
+			},
+		},
+		{
+			caseName: "Validate_RS256_JWKS",
+			token:    sign(jwt.SigningMethodRS256, "test-key", claims(time.Now().Add(time.Hour)), rsaKey),
+			results: func(principal *auth.Principal, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, "user-1", principal.Subject)
+			},
+		},
+		{
+			caseName: "Validate_WrongSecret",
+			token:    sign(jwt.SigningMethodHS256, "", claims(time.Now().Add(time.Hour)), []byte("other")),
+			results: func(principal *auth.Principal, err error) {
+				assert.Nil(t, principal)
+				assert.True(t, derrors.IsErrCode(err, derrors.Unauthorized))
+			},
+		},
+		{
+			caseName: "Validate_Expired",
+			token:    sign(jwt.SigningMethodHS256, "", claims(time.Now().Add(-time.Hour)), []byte("secret")),
+			results: func(principal *auth.Principal, err error) {
+				assert.Nil(t, principal)
+				assert.True(t, derrors.IsErrCode(err, derrors.Unauthorized))
+			},
+		},
+		{
+			caseName: "Validate_UnknownKeyID",
+			token:    sign(jwt.SigningMethodRS256, "other-key", claims(time.Now().Add(time.Hour)), rsaKey),
+			results: func(principal *auth.Principal, err error) {
+				assert.Nil(t, principal)
+				assert.True(t, derrors.IsErrCode(err, derrors.Unauthorized))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			principal, err := validator.Validate(testCase.token)
+			testCase.results(principal, err)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+// Package auth authenticates API callers and carries the resulting principal
+// on the request context.
+package auth
+
+import (
+	"context"
+	"strings"
+	"tensor-graphql/pkg/derrors"
+)
+
+type principalKey struct{}
+
+// Role grants access to a set of operations. Roles are ordered, a role
+// includes the permissions of every role ranked below it.
+type Role string
+
+const (
+	RoleViewer   Role = "viewer"
+	RoleOperator Role = "operator"
+	RoleAdmin    Role = "admin"
+)
+
+var roleRanks = map[Role]int{
+	RoleViewer:   1,
+	RoleOperator: 2,
+	RoleAdmin:    3,
+}
+
+// ParseRole returns the role named name, ignoring case.
+func ParseRole(name string) (Role, bool) {
+	role := Role(strings.ToLower(strings.TrimSpace(name)))
+	_, ok := roleRanks[role]
+	return role, ok
+}
+
+// ParseRoles returns the known roles in names, unknown names are ignored.
+func ParseRoles(names []string) []Role {
+	roles := make([]Role, 0, len(names))
+	for _, name := range names {
+		if role, ok := ParseRole(name); ok {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// Principal is the authenticated caller of a request. OrganizationID is the
+// tenant whose data the caller may access.
+type Principal struct {
+	Subject        string
+	OrganizationID string
+	Roles          []Role
+}
+
+// HasRole reports whether the principal holds role or a role ranked above it.
+func (p *Principal) HasRole(role Role) bool {
+	required, ok := roleRanks[role]
+	if !ok {
+		return false
+	}
+	for _, granted := range p.Roles {
+		if roleRanks[granted] >= required {
+			return true
+		}
+	}
+	return false
+}
+
+// WithPrincipal returns a copy of ctx carrying principal.
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal carried by ctx, if any.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// RequirePrincipal returns the principal carried by ctx or an Unauthorized
+// error for anonymous callers.
+func RequirePrincipal(ctx context.Context) (*Principal, error) {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return nil, derrors.New(derrors.Unauthorized, "authentication required")
+	}
+	return principal, nil
+}
+
+// RequireOrganization returns the organization of the principal carried by ctx,
+// every tenant scoped query of the request is restricted to it.
+func RequireOrganization(ctx context.Context) (string, error) {
+	principal, err := RequirePrincipal(ctx)
+	if err != nil {
+		return "", err
+	}
+	if principal.OrganizationID == "" {
+		return "", derrors.New(derrors.Forbidden, "principal does not belong to an organization")
+	}
+	return principal.OrganizationID, nil
+}
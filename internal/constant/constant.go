@@ -2,5 +2,12 @@ package constant
 
 // List of internal constant
 const (
-	DBStringConnection = "%s:%s@tcp(%s:%s)/%s?charset=utf8mb4"
+	DBStringConnection = "%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=true"
+	// PostgresDBStringConnection keeps the session in UTC like the timestamps
+	// written by the repositories.
+	PostgresDBStringConnection = "postgres://%s@%s:%s/%s?sslmode=%s&timezone=UTC"
+	// SQLiteDBStringConnection enforces foreign keys, takes the write lock
+	// when a transaction begins so concurrent writers wait instead of failing,
+	// and writes times in a format SQLite date functions understand.
+	SQLiteDBStringConnection = "file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_txlock=immediate&_time_format=sqlite"
 )
@@ -2,19 +2,30 @@ package container
 
 import (
 	"tensor-graphql/infrastructure/config"
+	"tensor-graphql/internal/api/export"
 	"tensor-graphql/internal/api/graphql"
 	"tensor-graphql/internal/library/openmeteo"
+	apikeyrepository "tensor-graphql/internal/repository/api_key"
+	auditeventrepository "tensor-graphql/internal/repository/audit_event"
 	repository "tensor-graphql/internal/repository/common"
+	portfoliorepository "tensor-graphql/internal/repository/portfolio"
 	powerPlantrepository "tensor-graphql/internal/repository/power_plant"
+	apikeyusecase "tensor-graphql/internal/usecase/api_key"
+	forecastusecase "tensor-graphql/internal/usecase/forecast"
+	portfoliousecase "tensor-graphql/internal/usecase/portfolio"
 	powerplantusecase "tensor-graphql/internal/usecase/power_plant"
 )
 
 type HandlerComponent struct {
 	Config   *config.Config
 	Resolver *graphql.Resolver
+	Export   *export.Handler
 
 	// UseCase
 	PowerPlantUsecase powerplantusecase.PowerPlantUsecase
+	APIKeyUsecase     apikeyusecase.APIKeyUsecase
+	ForecastUsecase   forecastusecase.ForecastUsecase
+	PortfolioUsecase  portfoliousecase.PortfolioUsecase
 }
 
 func NewHandlerComponent(sc *SharedComponent) *HandlerComponent {
@@ -23,14 +34,28 @@ func NewHandlerComponent(sc *SharedComponent) *HandlerComponent {
 	openmeteoLib := openmeteo.NewOpenMeteo()
 
 	powerPlantrepository := powerPlantrepository.NewPowerPlantRepository(baseStore)
-	powerplantUsecase := powerplantusecase.NewPowerPlantUsecase(powerPlantrepository)
+	auditEventRepository := auditeventrepository.NewAuditEventRepository(baseStore)
+	txManager := repository.NewTransactionManager(sc.DB)
+	powerplantUsecase := powerplantusecase.NewPowerPlantUsecase(powerPlantrepository, auditEventRepository, txManager, &openmeteoLib, sc.Conf.Map)
 
-	resolver := graphql.NewResolver(powerplantUsecase, openmeteoLib)
+	forecastUsecase := forecastusecase.NewForecastUsecase(powerPlantrepository, &openmeteoLib)
+
+	apikeyRepository := apikeyrepository.NewAPIKeyRepository(baseStore)
+	apikeyUsecase := apikeyusecase.NewAPIKeyUsecase(apikeyRepository)
+
+	portfolioRepository := portfoliorepository.NewPortfolioRepository(baseStore)
+	portfolioUsecase := portfoliousecase.NewPortfolioUsecase(portfolioRepository, powerPlantrepository, txManager)
+
+	resolver := graphql.NewResolver(powerplantUsecase, apikeyUsecase, portfolioUsecase, openmeteoLib)
 
 	return &HandlerComponent{
 		Config:   sc.Conf,
 		Resolver: resolver,
+		Export:   export.NewHandler(powerplantUsecase, forecastUsecase),
 
 		PowerPlantUsecase: powerplantUsecase,
+		APIKeyUsecase:     apikeyUsecase,
+		ForecastUsecase:   forecastUsecase,
+		PortfolioUsecase:  portfolioUsecase,
 	}
 }
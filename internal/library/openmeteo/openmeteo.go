@@ -4,13 +4,31 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"tensor-graphql/pkg/datatype"
 	"tensor-graphql/pkg/derrors"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 )
 
 const (
 	openMeteoAPI = "https://api.open-meteo.com/v1/"
+
+	// timeLayout is the ISO8601 layout used for hourly times, they are
+	// reported in the requested timezone without an offset.
+	timeLayout = "2006-01-02T15:04"
+	dateLayout = "2006-01-02"
+
+	// requestTimeout bounds a request to openmeteo, a cancelled ctx stops it
+	// earlier.
+	requestTimeout = 10 * time.Second
+
+	// TimezoneAuto asks openmeteo to resolve the timezone from the coordinates,
+	// the resolved IANA name is returned in WeatherResponse.Timezone.
+	TimezoneAuto = "auto"
+	// TimezoneGMT is used when no timezone is requested.
+	TimezoneGMT = "GMT"
 )
 
 type (
@@ -19,31 +37,42 @@ type (
 	}
 
 	openmeteo interface {
-		GetWeatherForecast(ctx context.Context, latitude, longitude float64, days int) (weather *WeatherResponse, err error)
+		GetWeatherForecast(ctx context.Context, latitude, longitude float64, days int, timezone string) (weather *WeatherResponse, err error)
+		GetWeatherForecastBetween(ctx context.Context, latitude, longitude float64, startDate, endDate datatype.Date, timezone string) (weather *WeatherResponse, err error)
+		DetectTimezone(ctx context.Context, latitude, longitude float64) (timezone string, err error)
 	}
 )
 
 func NewOpenMeteo() OpenMeteo {
 	return OpenMeteo{
-		api: resty.New(),
+		api: resty.New().SetTimeout(requestTimeout),
 	}
 }
 
-// https://api.open-meteo.com/v1/forecast?latitude=52.52&longitude=13.41&hourly=temperature_2m,precipitation,wind_speed_10m,wind_direction_10m
-func (o *OpenMeteo) GetWeatherForecast(ctx context.Context, latitude, longitude float64, days int) (weather *WeatherResponse, err error) {
+// https://api.open-meteo.com/v1/forecast?latitude=52.52&longitude=13.41&hourly=temperature_2m,precipitation,wind_speed_10m,wind_direction_10m&timezone=auto
+func (o *OpenMeteo) GetWeatherForecast(ctx context.Context, latitude, longitude float64, days int, timezone string) (weather *WeatherResponse, err error) {
 	defer derrors.Wrap(&err, "GetWeatherForecast(%f,%f)", latitude, longitude)
 
 	if days == 0 {
 		days = 7
 	}
 
-	url := fmt.Sprintf("%sforecast?latitude=%f&longitude=%f&hourly=temperature_2m,precipitation,wind_speed_10m,wind_direction_10m&forecast_days=%d", openMeteoAPI, latitude, longitude, days)
+	if timezone == "" {
+		timezone = TimezoneGMT
+	}
+
+	endpoint := fmt.Sprintf("%sforecast?latitude=%f&longitude=%f&hourly=temperature_2m,precipitation,wind_speed_10m,wind_direction_10m&forecast_days=%d&timezone=%s", openMeteoAPI, latitude, longitude, days, url.QueryEscape(timezone))
 	resp, err := o.api.R().
-		Get(url)
+		SetContext(ctx).
+		Get(endpoint)
 	if err != nil {
 		return weather, err
 	}
 
+	if resp.IsError() {
+		return weather, derrors.New(derrors.Unknown, "openmeteo responded with %s", resp.Status())
+	}
+
 	err = json.Unmarshal(resp.Body(), &weather)
 	if err != nil {
 		return weather, err
@@ -51,3 +80,70 @@ func (o *OpenMeteo) GetWeatherForecast(ctx context.Context, latitude, longitude
 
 	return
 }
+
+// https://api.open-meteo.com/v1/forecast?latitude=52.52&longitude=13.41&hourly=temperature_2m,precipitation,wind_speed_10m,wind_direction_10m&start_date=2025-03-04&end_date=2025-03-05&timezone=Europe%2FBerlin
+func (o *OpenMeteo) GetWeatherForecastBetween(ctx context.Context, latitude, longitude float64, startDate, endDate datatype.Date, timezone string) (weather *WeatherResponse, err error) {
+	defer derrors.Wrap(&err, "GetWeatherForecastBetween(%f,%f)", latitude, longitude)
+
+	if startDate.IsNil() || endDate.IsNil() {
+		return nil, derrors.New(derrors.InvalidArgument, "startDate and endDate are required")
+	}
+
+	if timezone == "" {
+		timezone = TimezoneGMT
+	}
+
+	endpoint := fmt.Sprintf("%sforecast?latitude=%f&longitude=%f&hourly=temperature_2m,precipitation,wind_speed_10m,wind_direction_10m&start_date=%s&end_date=%s&timezone=%s", openMeteoAPI, latitude, longitude, startDate.Time().Format(dateLayout), endDate.Time().Format(dateLayout), url.QueryEscape(timezone))
+	resp, err := o.api.R().
+		SetContext(ctx).
+		Get(endpoint)
+	if err != nil {
+		return weather, err
+	}
+
+	if resp.IsError() {
+		return weather, derrors.New(derrors.Unknown, "openmeteo responded with %s", resp.Status())
+	}
+
+	err = json.Unmarshal(resp.Body(), &weather)
+	if err != nil {
+		return weather, err
+	}
+
+	return
+}
+
+// DetectTimezone resolves the IANA timezone of the given coordinates.
+func (o *OpenMeteo) DetectTimezone(ctx context.Context, latitude, longitude float64) (timezone string, err error) {
+	defer derrors.Wrap(&err, "DetectTimezone(%f,%f)", latitude, longitude)
+
+	weather, err := o.GetWeatherForecast(ctx, latitude, longitude, 1, TimezoneAuto)
+	if err != nil {
+		return "", err
+	}
+
+	return weather.Timezone, nil
+}
+
+// LoadLocation returns the location for an IANA timezone name as returned by
+// openmeteo, falling back to UTC for unknown or empty names.
+func LoadLocation(timezone string) *time.Location {
+	if timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// ParseTime parses a time of the hourly series in loc.
+func ParseTime(value string, loc *time.Location) (datatype.Time, error) {
+	tmp, err := time.ParseInLocation(timeLayout, value, loc)
+	if err != nil {
+		return datatype.Time{}, derrors.WrapStack(err, derrors.Unknown, "ParseTime(%q)", value)
+	}
+
+	return datatype.NewTime(&tmp), nil
+}
@@ -0,0 +1,19 @@
+package model
+
+import "tensor-graphql/pkg/datatype"
+
+// APIKey authenticates a machine client. Only the SHA-256 hash of the key is
+// stored, the plaintext key is handed out once when the key is created.
+type APIKey struct {
+	ID             string        `json:"id"`
+	OrganizationID string        `json:"-"`
+	Name           string        `json:"name"`
+	Prefix         string        `json:"prefix"`
+	KeyHash        string        `json:"-"`
+	Scopes         []Role        `json:"scopes"`
+	CreatedBy      string        `json:"createdBy"`
+	ExpiresAt      datatype.Time `json:"expiresAt"`
+	LastUsedAt     datatype.Time `json:"lastUsedAt"`
+	RevokedAt      datatype.Time `json:"revokedAt"`
+	CreatedAt      datatype.Time `json:"createdAt"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import "tensor-graphql/pkg/datatype"
+
+// AuditEvent records a change made to an entity. Before and After hold JSON
+// snapshots of the entity, Before is nil on create and After is nil on delete.
+type AuditEvent struct {
+	ID             string        `json:"id"`
+	OrganizationID string        `json:"-"`
+	EntityType     string        `json:"entityType"`
+	EntityID       string        `json:"entityId"`
+	Action         AuditAction   `json:"action"`
+	Actor          string        `json:"actor"`
+	RequestID      string        `json:"requestId"`
+	Before         *string       `json:"before,omitempty"`
+	After          *string       `json:"after,omitempty"`
+	CreatedAt      datatype.Time `json:"createdAt"`
+}
@@ -0,0 +1,41 @@
+package model
+
+import "tensor-graphql/pkg/datatype"
+
+// ForecastExport selects the hourly forecasts written by a forecast export,
+// one row per plant and hour between StartDate and EndDate inclusive, in the
+// local time of each plant.
+type ForecastExport struct {
+	PlantIDs  []string
+	StartDate datatype.Date
+	EndDate   datatype.Date
+	Variables []ForecastVariable
+	Format    ExportFormat
+}
+
+// ForecastVariable is a column of a forecast export, named like the fields of
+// WeatherForecast.
+type ForecastVariable string
+
+const (
+	ForecastVariableTemperature   ForecastVariable = "temperature"
+	ForecastVariablePrecipitation ForecastVariable = "precipitation"
+	ForecastVariableWindSpeed     ForecastVariable = "windSpeed"
+	ForecastVariableWindDirection ForecastVariable = "windDirection"
+)
+
+// ForecastVariables are the variables of an export, in their column order.
+var ForecastVariables = []ForecastVariable{
+	ForecastVariableTemperature,
+	ForecastVariablePrecipitation,
+	ForecastVariableWindSpeed,
+	ForecastVariableWindDirection,
+}
+
+// ExportFormat is the file format of an export.
+type ExportFormat string
+
+const (
+	ExportFormatCSV     ExportFormat = "csv"
+	ExportFormatParquet ExportFormat = "parquet"
+)
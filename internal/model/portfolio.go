@@ -0,0 +1,39 @@
+package model
+
+import "tensor-graphql/pkg/datatype"
+
+// Portfolio groups power plants, such as the wind farms of a region. Its
+// plants and weather are resolved from the members when they are asked for.
+type Portfolio struct {
+	ID             string             `json:"id"`
+	OrganizationID string             `json:"-"`
+	Name           string             `json:"name"`
+	Members        []*PortfolioMember `json:"members"`
+	CreatedAt      datatype.Time      `json:"createdAt"`
+	UpdatedAt      datatype.Time      `json:"updatedAt"`
+}
+
+// CapacityMw is the total capacity of the members in megawatt.
+func (p *Portfolio) CapacityMw() float64 {
+	var capacity float64
+	for _, member := range p.Members {
+		capacity += member.CapacityMw
+	}
+	return capacity
+}
+
+// PortfolioWeather aggregates the forecasts of the members of a portfolio, the
+// forecast of every member is weighed by its capacity. The fields are nil when
+// there are no members.
+type PortfolioWeather struct {
+	MeanTemperature    *float64
+	TotalPrecipitation *float64
+	MaxWindSpeed       *float64
+}
+
+// PortfolioMember is a power plant of a portfolio with the capacity it
+// contributes to it.
+type PortfolioMember struct {
+	PlantID    string  `json:"plantId"`
+	CapacityMw float64 `json:"capacityMw"`
+}
@@ -2,6 +2,31 @@
 
 package model
 
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"tensor-graphql/pkg/datatype"
+)
+
+type CreatePowerPlantInput struct {
+	Name      string  `json:"name"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type CreatedAPIKey struct {
+	APIKey *APIKey `json:"apiKey"`
+	// Plaintext key, it is only returned once and cannot be recovered later
+	Key string `json:"key"`
+}
+
+type FieldError struct {
+	// Column of the problem, null when it concerns the whole row
+	Field   *string `json:"field,omitempty"`
+	Message string  `json:"message"`
+}
+
 type Mutation struct {
 }
 
@@ -14,12 +39,85 @@ type PowerPlant struct {
 	Latitude float64 `json:"latitude"`
 	// Longitude in degrees
 	Longitude float64 `json:"longitude"`
-	// Provided forecasts from openmeteo for the weather
+	// IANA timezone of the power plant, detected from its coordinates
+	Timezone string `json:"timezone"`
+	// Provided forecasts from openmeteo for the weather, either for the next
+	// forecastDays days or for the dates between startDate and endDate inclusive
 	WeatherForecasts []*WeatherForecast `json:"weatherForecasts"`
-	// Is there precipitation at the power plant today?
+	// Is there precipitation at the power plant today, in its local timezone?
 	HasPrecipitationToday bool `json:"hasPrecipitationToday"`
+	// Total precipitation over all hours of the local day in millimeter
+	PrecipitationTodayMm float64 `json:"precipitationTodayMm"`
+	// First hour of the local day with precipitation, null when it stays dry
+	FirstPrecipitationAt *datatype.Time `json:"firstPrecipitationAt,omitempty"`
 	// Elevation of the power plant
 	Elevation float64 `json:"elevation"`
+	// Time the power plant was created
+	CreatedAt datatype.Time `json:"createdAt"`
+	// Time the power plant was last updated
+	UpdatedAt datatype.Time `json:"updatedAt"`
+	// Version of the power plant, incremented on every update
+	Version int `json:"version"`
+	// Great-circle distance in kilometer to the location searched by nearbyPowerPlants, null elsewhere
+	DistanceKm *float64 `json:"distanceKm,omitempty"`
+}
+
+type PowerPlantBatch struct {
+	// True when every item was applied, otherwise none was
+	Success bool `json:"success"`
+	// Outcome of every item, in the order of the input
+	Items []*PowerPlantBatchItem `json:"items"`
+}
+
+type PowerPlantBatchItem struct {
+	// Position of the item in the input, starting at 0
+	Index int `json:"index"`
+	// True when the item was applied
+	Success bool `json:"success"`
+	// The created or updated plant, null unless the batch succeeded
+	Plant *PowerPlant `json:"plant,omitempty"`
+	// Problems of the item, empty when it was only held back by other items
+	Errors []*FieldError `json:"errors"`
+}
+
+// Plants inside one grid cell of a clustered region
+type PowerPlantCluster struct {
+	// Number of plants in the cell
+	Count int `json:"count"`
+	// Mean latitude of the plants in the cell
+	Latitude float64 `json:"latitude"`
+	// Mean longitude of the plants in the cell
+	Longitude float64 `json:"longitude"`
+	// Southern edge of the cell
+	South float64 `json:"south"`
+	// Western edge of the cell
+	West float64 `json:"west"`
+	// Northern edge of the cell
+	North float64 `json:"north"`
+	// Eastern edge of the cell
+	East float64 `json:"east"`
+}
+
+type PowerPlantImport struct {
+	// True when nothing was created
+	DryRun bool `json:"dryRun"`
+	// Number of rows without errors, they were created unless dryRun
+	ValidCount int `json:"validCount"`
+	// Number of rows with errors, they were skipped
+	ErrorCount int `json:"errorCount"`
+	// Outcome of every row of the file
+	Rows []*PowerPlantImportRow `json:"rows"`
+}
+
+type PowerPlantImportRow struct {
+	// Line of the row in the file, the header is line 1
+	Line int `json:"line"`
+	// Name given by the row
+	Name string `json:"name"`
+	// ID of the created plant, null on a dry run or when the row has errors
+	ID *string `json:"id,omitempty"`
+	// Problems that made the row be skipped
+	Errors []*FieldError `json:"errors"`
 }
 
 type PowerPlantPage struct {
@@ -29,12 +127,34 @@ type PowerPlantPage struct {
 	PageSize   int           `json:"pageSize"`
 }
 
+// Plants inside a map region, grouped into clusters when there are more than the
+// configured maximum
+type PowerPlantRegion struct {
+	// Number of plants inside the region
+	TotalCount int `json:"totalCount"`
+	// True when the plants are grouped into clusters instead of being listed
+	Clustered bool `json:"clustered"`
+	// Plants inside the region, empty when clustered
+	Plants []*PowerPlant `json:"plants"`
+	// Grid cells of the region holding plants, empty unless clustered
+	Clusters []*PowerPlantCluster `json:"clusters"`
+}
+
 type Query struct {
 }
 
+type UpdatePowerPlantInput struct {
+	ID string `json:"id"`
+	// Must match the current version of the plant
+	Version   int      `json:"version"`
+	Name      *string  `json:"name,omitempty"`
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+}
+
 type WeatherForecast struct {
-	// Time of the forecast in UTC/GMT
-	Time string `json:"time"`
+	// Time of the forecast with the local offset of the power plant
+	Time datatype.Time `json:"time"`
 	// Temperature (2 m) in celsius
 	Temperature float64 `json:"temperature"`
 	// Precipitation (rain + showers + snow) in millimeter
@@ -44,3 +164,93 @@ type WeatherForecast struct {
 	// Wind Direction (10 m) in degrees
 	WindDirection float64 `json:"windDirection"`
 }
+
+type AuditAction string
+
+const (
+	AuditActionCreate AuditAction = "CREATE"
+	AuditActionUpdate AuditAction = "UPDATE"
+	AuditActionDelete AuditAction = "DELETE"
+)
+
+var AllAuditAction = []AuditAction{
+	AuditActionCreate,
+	AuditActionUpdate,
+	AuditActionDelete,
+}
+
+func (e AuditAction) IsValid() bool {
+	switch e {
+	case AuditActionCreate, AuditActionUpdate, AuditActionDelete:
+		return true
+	}
+	return false
+}
+
+func (e AuditAction) String() string {
+	return string(e)
+}
+
+func (e *AuditAction) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AuditAction(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AuditAction", str)
+	}
+	return nil
+}
+
+func (e AuditAction) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// Roles are ordered, every role includes the permissions of the roles before it
+type Role string
+
+const (
+	// Can query power plants
+	RoleViewer Role = "VIEWER"
+	// Can create and update power plants
+	RoleOperator Role = "OPERATOR"
+	// Can delete power plants
+	RoleAdmin Role = "ADMIN"
+)
+
+var AllRole = []Role{
+	RoleViewer,
+	RoleOperator,
+	RoleAdmin,
+}
+
+func (e Role) IsValid() bool {
+	switch e {
+	case RoleViewer, RoleOperator, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+func (e Role) String() string {
+	return string(e)
+}
+
+func (e *Role) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Role(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Role", str)
+	}
+	return nil
+}
+
+func (e Role) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
@@ -0,0 +1,237 @@
+package apikeyrepository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strconv"
+	"strings"
+	"tensor-graphql/internal/auth"
+	"tensor-graphql/internal/model"
+	repository "tensor-graphql/internal/repository/common"
+	"tensor-graphql/pkg/derrors"
+)
+
+const apiKeyColumns = `id, organization_id, name, key_prefix, key_hash, scopes, created_by, expires_at, last_used_at, revoked_at, created_at`
+
+type (
+	apiKeyRepository struct {
+		repository.Repository
+	}
+
+	// APIKeyRepository stores API keys. Apart from GetAPIKeyByHash, which
+	// authenticates requests, every method is scoped to the organization of the
+	// principal on the context. GetAPIKeyByHash reads from the master, so a
+	// revoked key is rejected despite replica lag.
+	APIKeyRepository interface {
+		repository.Repository
+		CreateAPIKey(ctx context.Context, tx *sql.Tx, apiKey *model.APIKey) (err error)
+		GetAPIKeyByID(ctx context.Context, id string) (apiKey *model.APIKey, err error)
+		GetAPIKeyByHash(ctx context.Context, keyHash string) (apiKey *model.APIKey, err error)
+		GetAPIKeys(ctx context.Context) (apiKeys []*model.APIKey, err error)
+		RevokeAPIKey(ctx context.Context, tx *sql.Tx, id string) (err error)
+		// TouchAPIKey records that the key was used. The write is not tracked
+		// on ctx, so the later reads of the request still go to the slave.
+		TouchAPIKey(ctx context.Context, tx *sql.Tx, id string) (err error)
+	}
+
+	// roleList stores roles as a comma separated column.
+	roleList []model.Role
+)
+
+func NewAPIKeyRepository(store repository.Repository) APIKeyRepository {
+	return &apiKeyRepository{
+		Repository: store,
+	}
+}
+
+func (r *apiKeyRepository) CreateAPIKey(ctx context.Context, tx *sql.Tx, apiKey *model.APIKey) (err error) {
+	defer derrors.Wrap(&err, "CreateAPIKey(%q)", apiKey.Name)
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO api_key (organization_id, name, key_prefix, key_hash, scopes, created_by, expires_at) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	args := []interface{}{
+		organizationID,
+		apiKey.Name,
+		apiKey.Prefix,
+		apiKey.KeyHash,
+		roleList(apiKey.Scopes),
+		apiKey.CreatedBy,
+		&apiKey.ExpiresAt,
+	}
+
+	id, err := r.Insert(ctx, tx, query, args)
+	if err != nil {
+		return derrors.HandleSQLError(err, "r.Insert")
+	}
+	apiKey.ID = strconv.FormatInt(id, 10)
+
+	// Re-read the row so the database generated timestamps are returned as well.
+	query = `SELECT ` + apiKeyColumns + ` FROM api_key WHERE id = ?`
+	err = r.QueryRowPrimary(ctx, tx, query, apiKey.ID).Scan(r.getDest(apiKey)...)
+	if err != nil {
+		return derrors.HandleSQLError(err, "r.QueryRowPrimary")
+	}
+
+	return nil
+}
+
+func (r *apiKeyRepository) GetAPIKeyByID(ctx context.Context, id string) (apiKey *model.APIKey, err error) {
+	defer derrors.Wrap(&err, "GetAPIKeyByID(%q)", id)
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.getAPIKey(ctx, `SELECT `+apiKeyColumns+` FROM api_key WHERE id = ? AND organization_id = ?`, id, organizationID)
+}
+
+func (r *apiKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (apiKey *model.APIKey, err error) {
+	defer derrors.Wrap(&err, "GetAPIKeyByHash")
+
+	apiKey = &model.APIKey{}
+	query := `SELECT ` + apiKeyColumns + ` FROM api_key WHERE key_hash = ?`
+	err = r.QueryRowPrimary(ctx, nil, query, keyHash).Scan(r.getDest(apiKey)...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, derrors.HandleSQLError(err, "r.QueryRowPrimary")
+	}
+
+	return apiKey, nil
+}
+
+func (r *apiKeyRepository) getAPIKey(ctx context.Context, query string, args ...interface{}) (apiKey *model.APIKey, err error) {
+	apiKey = &model.APIKey{}
+
+	err = r.Query(ctx, query, r.getDest(apiKey), args)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, derrors.HandleSQLError(err, "r.Query")
+	}
+
+	return apiKey, nil
+}
+
+func (r *apiKeyRepository) GetAPIKeys(ctx context.Context) (apiKeys []*model.APIKey, err error) {
+	defer derrors.Wrap(&err, "GetAPIKeys")
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + apiKeyColumns + ` FROM api_key WHERE organization_id = ? ORDER BY id`
+
+	rows, err := r.QueryContext(ctx, query, organizationID)
+	if err != nil {
+		return nil, derrors.HandleSQLError(err, "QueryContext")
+	}
+	defer rows.Close()
+
+	apiKeys = make([]*model.APIKey, 0)
+	for rows.Next() {
+		apiKey := &model.APIKey{}
+		err = rows.Scan(r.getDest(apiKey)...)
+		if err != nil {
+			return nil, derrors.WrapStack(err, derrors.Unknown, "rows.Scan")
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+
+	return apiKeys, rows.Err()
+}
+
+func (r *apiKeyRepository) RevokeAPIKey(ctx context.Context, tx *sql.Tx, id string) (err error) {
+	defer derrors.Wrap(&err, "RevokeAPIKey(%q)", id)
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE api_key SET revoked_at = CURRENT_TIMESTAMP WHERE id = ? AND organization_id = ? AND revoked_at IS NULL`
+	args := []interface{}{
+		id,
+		organizationID,
+	}
+
+	_, err = r.Exec(ctx, tx, query, args)
+	if err != nil {
+		return derrors.WrapStack(err, derrors.Unknown, "r.Exec")
+	}
+
+	return nil
+}
+
+func (r *apiKeyRepository) TouchAPIKey(ctx context.Context, tx *sql.Tx, id string) (err error) {
+	defer derrors.Wrap(&err, "TouchAPIKey(%q)", id)
+
+	query := `UPDATE api_key SET last_used_at = CURRENT_TIMESTAMP WHERE id = ?`
+	args := []interface{}{
+		id,
+	}
+
+	_, err = r.Exec(repository.WithoutReadYourWrites(ctx), tx, query, args)
+	if err != nil {
+		return derrors.WrapStack(err, derrors.Unknown, "r.Exec")
+	}
+
+	return nil
+}
+
+func (r *apiKeyRepository) getDest(apiKey *model.APIKey) []interface{} {
+	return []interface{}{
+		&apiKey.ID,
+		&apiKey.OrganizationID,
+		&apiKey.Name,
+		&apiKey.Prefix,
+		&apiKey.KeyHash,
+		(*roleList)(&apiKey.Scopes),
+		&apiKey.CreatedBy,
+		&apiKey.ExpiresAt,
+		&apiKey.LastUsedAt,
+		&apiKey.RevokedAt,
+		&apiKey.CreatedAt,
+	}
+}
+
+// Scan implements the Scanner interface.
+func (l *roleList) Scan(value interface{}) error {
+	var str string
+	switch v := value.(type) {
+	case nil:
+	case []byte:
+		str = string(v)
+	case string:
+		str = v
+	default:
+		return derrors.New(derrors.Unknown, "roleList: unsupported scan source type %T", value)
+	}
+
+	*l = roleList{}
+	for _, role := range strings.Split(str, ",") {
+		if role != "" {
+			*l = append(*l, model.Role(role))
+		}
+	}
+
+	return nil
+}
+
+// Value implements the driver Valuer interface.
+func (l roleList) Value() (driver.Value, error) {
+	roles := make([]string, 0, len(l))
+	for _, role := range l {
+		roles = append(roles, string(role))
+	}
+	return strings.Join(roles, ","), nil
+}
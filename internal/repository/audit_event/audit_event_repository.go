@@ -0,0 +1,108 @@
+package auditeventrepository
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"tensor-graphql/internal/auth"
+	"tensor-graphql/internal/model"
+	repository "tensor-graphql/internal/repository/common"
+	"tensor-graphql/pkg/derrors"
+)
+
+const auditEventColumns = `id, organization_id, entity_type, entity_id, action, actor, request_id, before_state, after_state, created_at`
+
+type (
+	auditEventRepository struct {
+		repository.Repository
+	}
+
+	// AuditEventRepository stores the audit trail of changes. Every method is
+	// scoped to the organization of the principal on the context.
+	AuditEventRepository interface {
+		repository.Repository
+		CreateAuditEvent(ctx context.Context, tx *sql.Tx, event *model.AuditEvent) (err error)
+		GetAuditEvents(ctx context.Context, entityType, entityID string) (events []*model.AuditEvent, err error)
+	}
+)
+
+func NewAuditEventRepository(store repository.Repository) AuditEventRepository {
+	return &auditEventRepository{
+		Repository: store,
+	}
+}
+
+func (r *auditEventRepository) CreateAuditEvent(ctx context.Context, tx *sql.Tx, event *model.AuditEvent) (err error) {
+	defer derrors.Wrap(&err, "CreateAuditEvent(%q, %q)", event.EntityType, event.EntityID)
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO audit_event (organization_id, entity_type, entity_id, action, actor, request_id, before_state, after_state) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	args := []interface{}{
+		organizationID,
+		event.EntityType,
+		event.EntityID,
+		event.Action,
+		event.Actor,
+		event.RequestID,
+		event.Before,
+		event.After,
+	}
+
+	id, err := r.Insert(ctx, tx, query, args)
+	if err != nil {
+		return derrors.HandleSQLError(err, "r.Insert")
+	}
+	event.ID = strconv.FormatInt(id, 10)
+	event.OrganizationID = organizationID
+
+	return nil
+}
+
+// GetAuditEvents returns the audit trail of an entity, oldest event first.
+func (r *auditEventRepository) GetAuditEvents(ctx context.Context, entityType, entityID string) (events []*model.AuditEvent, err error) {
+	defer derrors.Wrap(&err, "GetAuditEvents(%q, %q)", entityType, entityID)
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + auditEventColumns + ` FROM audit_event WHERE organization_id = ? AND entity_type = ? AND entity_id = ? ORDER BY id`
+
+	rows, err := r.QueryContext(ctx, query, organizationID, entityType, entityID)
+	if err != nil {
+		return nil, derrors.HandleSQLError(err, "QueryContext")
+	}
+	defer rows.Close()
+
+	events = make([]*model.AuditEvent, 0)
+	for rows.Next() {
+		event := &model.AuditEvent{}
+		err = rows.Scan(r.getDest(event)...)
+		if err != nil {
+			return nil, derrors.WrapStack(err, derrors.Unknown, "rows.Scan")
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+func (r *auditEventRepository) getDest(event *model.AuditEvent) []interface{} {
+	return []interface{}{
+		&event.ID,
+		&event.OrganizationID,
+		&event.EntityType,
+		&event.EntityID,
+		&event.Action,
+		&event.Actor,
+		&event.RequestID,
+		&event.Before,
+		&event.After,
+		&event.CreatedAt,
+	}
+}
@@ -0,0 +1,85 @@
+package repository_test
+
+import (
+	"context"
+	"tensor-graphql/infrastructure/database"
+	repository "tensor-graphql/internal/repository/common"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDialect(t *testing.T) {
+	query := "INSERT INTO power_plant (name, timezone) VALUES (?, ?)"
+	args := []interface{}{"name", "UTC"}
+
+	t.Run("Insert_MySQL", func(t *testing.T) {
+		db, dbMock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+
+		dbMock.ExpectExec(query).WithArgs("name", "UTC").WillReturnResult(sqlmock.NewResult(5, 1))
+
+		repo := repository.NewRepository(&database.DB{Master: db, Slave: db, Dialect: database.MySQL})
+		id, err := repo.Insert(context.Background(), nil, query, args)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), id)
+		assert.NoError(t, dbMock.ExpectationsWereMet())
+	})
+
+	t.Run("Insert_Postgres", func(t *testing.T) {
+		db, dbMock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+
+		dbMock.ExpectQuery("INSERT INTO power_plant (name, timezone) VALUES ($1, $2) RETURNING id").
+			WithArgs("name", "UTC").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+		repo := repository.NewRepository(&database.DB{Master: db, Slave: db, Dialect: database.Postgres})
+		id, err := repo.Insert(context.Background(), nil, query, args)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), id)
+		assert.NoError(t, dbMock.ExpectationsWereMet())
+	})
+
+	manyQuery := "INSERT INTO power_plant (name, timezone) VALUES (?, ?), (?, ?)"
+	manyArgs := []interface{}{"a", "UTC", "b", "UTC"}
+
+	t.Run("InsertMany_MySQL", func(t *testing.T) {
+		db, dbMock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+
+		dbMock.ExpectExec(manyQuery).WithArgs("a", "UTC", "b", "UTC").WillReturnResult(sqlmock.NewResult(5, 2))
+
+		repo := repository.NewRepository(&database.DB{Master: db, Slave: db, Dialect: database.MySQL})
+		ids, err := repo.InsertMany(context.Background(), nil, manyQuery, manyArgs, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{5, 6}, ids)
+		assert.NoError(t, dbMock.ExpectationsWereMet())
+	})
+
+	t.Run("InsertMany_Postgres", func(t *testing.T) {
+		db, dbMock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+
+		dbMock.ExpectQuery("INSERT INTO power_plant (name, timezone) VALUES ($1, $2), ($3, $4) RETURNING id").
+			WithArgs("a", "UTC", "b", "UTC").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(6).AddRow(5))
+		repo := repository.NewRepository(&database.DB{Master: db, Slave: db, Dialect: database.Postgres})
+		ids, err := repo.InsertMany(context.Background(), nil, manyQuery, manyArgs, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{5, 6}, ids)
+		assert.NoError(t, dbMock.ExpectationsWereMet())
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sort"
 	"strings"
 	"tensor-graphql/infrastructure/database"
 	"tensor-graphql/pkg/derrors"
@@ -21,8 +22,15 @@ type Repository interface {
 	Begin() (tx *sql.Tx, err error)
 	Commit(tx *sql.Tx) error
 	Rollback(tx *sql.Tx) error
+	Dialect() database.Dialect
 	Exec(ctx context.Context, tx *sql.Tx, query string, args []interface{}) (result sql.Result, err error)
+	Insert(ctx context.Context, tx *sql.Tx, query string, args []interface{}) (id int64, err error)
+	InsertMany(ctx context.Context, tx *sql.Tx, query string, args []interface{}, count int) (ids []int64, err error)
+	QueryPrimary(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowPrimary(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) *sql.Row
 	Query(ctx context.Context, query string, dest []interface{}, args []interface{}) error
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
 	GetOffset(page int, limit int) int
 	AddSortQuery(query string, allowedFields []string, sortBy string) (string, error)
 	AddSortQueryWithPrefix(query string, allowedFields map[string]string, sortBy string) (string, error)
@@ -61,8 +69,18 @@ func (s *repository) Rollback(tx *sql.Tx) error {
 	return tx.Rollback()
 }
 
-// Insert Data
+// Dialect return the SQL dialect of the database
+func (s *repository) Dialect() database.Dialect {
+	return s.db.Dialect
+}
+
+// Insert Data, it runs in tx or else in the transaction carried by ctx
 func (s *repository) Exec(ctx context.Context, tx *sql.Tx, query string, args []interface{}) (result sql.Result, err error) {
+	if tx == nil {
+		tx = TxFromContext(ctx)
+	}
+
+	query = s.db.Dialect.Rebind(query)
 	if tx != nil {
 		result, err = tx.ExecContext(ctx, query, args...)
 	} else {
@@ -72,13 +90,108 @@ func (s *repository) Exec(ctx context.Context, tx *sql.Tx, query string, args []
 	if err != nil {
 		return result, err
 	}
+	markWritten(ctx)
 
 	return result, nil
 }
 
-// Select Data
+// Insert runs an INSERT statement and returns the ID generated for the row,
+// with LastInsertId or RETURNING depending on the dialect.
+func (s *repository) Insert(ctx context.Context, tx *sql.Tx, query string, args []interface{}) (id int64, err error) {
+	if s.db.Dialect.SupportsLastInsertID() {
+		result, err := s.Exec(ctx, tx, query, args)
+		if err != nil {
+			return 0, err
+		}
+		return result.LastInsertId()
+	}
+
+	err = s.QueryRowPrimary(ctx, tx, query+" RETURNING id", args...).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	markWritten(ctx)
+
+	return id, nil
+}
+
+// InsertMany runs an INSERT statement of count rows and returns the IDs
+// generated for them in ascending order, which is the order of the rows.
+func (s *repository) InsertMany(ctx context.Context, tx *sql.Tx, query string, args []interface{}, count int) (ids []int64, err error) {
+	if s.db.Dialect.ConsecutiveInsertIDs() {
+		result, err := s.Exec(ctx, tx, query, args)
+		if err != nil {
+			return nil, err
+		}
+		first, err := result.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < count; i++ {
+			ids = append(ids, first+int64(i))
+		}
+		return ids, nil
+	}
+
+	rows, err := s.QueryPrimary(ctx, tx, query+" RETURNING id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	markWritten(ctx)
+
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) != count {
+		return nil, fmt.Errorf("inserted %d rows, expected %d", len(ids), count)
+	}
+
+	// RETURNING does not promise an order, the IDs are handed out in the
+	// order of the rows.
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids, nil
+}
+
+// QueryPrimary reads rows from the master, in tx or else in the transaction
+// carried by ctx.
+func (s *repository) QueryPrimary(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (*sql.Rows, error) {
+	if tx == nil {
+		tx = TxFromContext(ctx)
+	}
+
+	query = s.db.Dialect.Rebind(query)
+	if tx != nil {
+		return tx.QueryContext(ctx, query, args...)
+	}
+	return s.Master().QueryContext(ctx, query, args...)
+}
+
+// QueryRowPrimary reads a single row from the master, in tx or else in the
+// transaction carried by ctx, e.g. to read back a row that was just written.
+func (s *repository) QueryRowPrimary(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) *sql.Row {
+	if tx == nil {
+		tx = TxFromContext(ctx)
+	}
+
+	query = s.db.Dialect.Rebind(query)
+	if tx != nil {
+		return tx.QueryRowContext(ctx, query, args...)
+	}
+	return s.Master().QueryRowContext(ctx, query, args...)
+}
+
+// Select Data, the connection is picked by the read routing policy
 func (s *repository) Query(ctx context.Context, query string, dest []interface{}, args []interface{}) (err error) {
-	err = s.Master().QueryRowContext(ctx, query, args...).Scan(dest...)
+	err = s.QueryRowContext(ctx, query, args...).Scan(dest...)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+)
+
+type writeTrackerKey struct{}
+
+// WithReadYourWrites returns a copy of ctx that remembers whether a write was
+// made with it. Once a repository writes, later reads with the context go to
+// the master, so a request reads its own writes despite replica lag.
+func WithReadYourWrites(ctx context.Context) context.Context {
+	if written, ok := ctx.Value(writeTrackerKey{}).(*atomic.Bool); ok && written != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, writeTrackerKey{}, new(atomic.Bool))
+}
+
+// WithoutReadYourWrites returns a copy of ctx whose writes are not tracked, for
+// bookkeeping writes the later reads of a request do not depend on. Reads with
+// it go to the slave.
+func WithoutReadYourWrites(ctx context.Context) context.Context {
+	return context.WithValue(ctx, writeTrackerKey{}, (*atomic.Bool)(nil))
+}
+
+// markWritten records a write on the tracker of ctx, if there is one.
+func markWritten(ctx context.Context) {
+	if written, ok := ctx.Value(writeTrackerKey{}).(*atomic.Bool); ok && written != nil {
+		written.Store(true)
+	}
+}
+
+func hasWritten(ctx context.Context) bool {
+	written, ok := ctx.Value(writeTrackerKey{}).(*atomic.Bool)
+	return ok && written != nil && written.Load()
+}
+
+// reader picks where a read with ctx goes: the transaction on ctx, the master
+// after a write made with ctx, and the slave otherwise. database.DB falls back
+// to the master when the slave is down.
+func (s *repository) reader(ctx context.Context) interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+} {
+	if tx := TxFromContext(ctx); tx != nil {
+		return tx
+	}
+	if hasWritten(ctx) {
+		return s.db.Master
+	}
+	return s.db.Reader()
+}
+
+// QueryContext runs a query returning rows on the connection picked by the
+// read routing policy.
+func (s *repository) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return s.reader(ctx).QueryContext(ctx, s.db.Dialect.Rebind(query), args...)
+}
+
+// QueryRowContext runs a query returning a single row on the connection
+// picked by the read routing policy.
+func (s *repository) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return s.reader(ctx).QueryRowContext(ctx, s.db.Dialect.Rebind(query), args...)
+}
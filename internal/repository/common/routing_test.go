@@ -0,0 +1,119 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"tensor-graphql/infrastructure/database"
+	repository "tensor-graphql/internal/repository/common"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func initRouting(t *testing.T) (*database.DB, repository.Repository, sqlmock.Sqlmock, sqlmock.Sqlmock) {
+	master, masterMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	slave, slaveMock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		assert.NoError(t, masterMock.ExpectationsWereMet())
+		assert.NoError(t, slaveMock.ExpectationsWereMet())
+		master.Close()
+		slave.Close()
+	})
+
+	db := &database.DB{Master: master, Slave: slave}
+	return db, repository.NewRepository(db), masterMock, slaveMock
+}
+
+func TestReadRouting(t *testing.T) {
+	query := "SELECT name FROM power_plant WHERE id = ?"
+
+	t.Run("Read_Slave", func(t *testing.T) {
+		_, repo, _, slaveMock := initRouting(t)
+		slaveMock.ExpectQuery("SELECT name").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("slave"))
+
+		var name string
+		err := repo.Query(context.Background(), query, []interface{}{&name}, []interface{}{"1"})
+		assert.NoError(t, err)
+		assert.Equal(t, "slave", name)
+	})
+
+	t.Run("Read_MasterAfterWrite", func(t *testing.T) {
+		_, repo, masterMock, slaveMock := initRouting(t)
+		slaveMock.ExpectQuery("SELECT name").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("slave"))
+		masterMock.ExpectExec("UPDATE power_plant").WillReturnResult(sqlmock.NewResult(0, 1))
+		masterMock.ExpectQuery("SELECT name").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("master"))
+
+		ctx := repository.WithReadYourWrites(context.Background())
+
+		var name string
+		err := repo.Query(ctx, query, []interface{}{&name}, []interface{}{"1"})
+		assert.NoError(t, err)
+		assert.Equal(t, "slave", name)
+
+		_, err = repo.Exec(ctx, nil, "UPDATE power_plant SET name = ? WHERE id = ?", []interface{}{"master", "1"})
+		assert.NoError(t, err)
+
+		err = repo.Query(ctx, query, []interface{}{&name}, []interface{}{"1"})
+		assert.NoError(t, err)
+		assert.Equal(t, "master", name)
+	})
+
+	t.Run("Read_SlaveAfterUntrackedWrite", func(t *testing.T) {
+		_, repo, masterMock, slaveMock := initRouting(t)
+		masterMock.ExpectExec("UPDATE api_key").WillReturnResult(sqlmock.NewResult(0, 1))
+		slaveMock.ExpectQuery("SELECT name").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("slave"))
+
+		ctx := repository.WithReadYourWrites(context.Background())
+
+		_, err := repo.Exec(repository.WithoutReadYourWrites(ctx), nil, "UPDATE api_key SET last_used_at = CURRENT_TIMESTAMP WHERE id = ?", []interface{}{"1"})
+		assert.NoError(t, err)
+
+		var name string
+		err = repo.Query(ctx, query, []interface{}{&name}, []interface{}{"1"})
+		assert.NoError(t, err)
+		assert.Equal(t, "slave", name)
+	})
+
+	t.Run("Read_MasterInTransaction", func(t *testing.T) {
+		db, repo, masterMock, _ := initRouting(t)
+		masterMock.ExpectBegin()
+		masterMock.ExpectQuery("SELECT name").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("master"))
+		masterMock.ExpectCommit()
+
+		err := repository.NewTransactionManager(db).WithinTransaction(context.Background(), func(ctx context.Context) error {
+			var name string
+			return repo.Query(ctx, query, []interface{}{&name}, []interface{}{"1"})
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Read_MasterWhileSlaveDown", func(t *testing.T) {
+		db, repo, masterMock, slaveMock := initRouting(t)
+		slaveMock.ExpectPing().WillReturnError(errors.New("connection refused"))
+		masterMock.ExpectQuery("SELECT name").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("master"))
+		slaveMock.ExpectPing()
+		slaveMock.ExpectQuery("SELECT name").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("slave"))
+
+		db.CheckSlave(context.Background())
+		assert.False(t, db.SlaveHealthy())
+
+		var name string
+		err := repo.Query(context.Background(), query, []interface{}{&name}, []interface{}{"1"})
+		assert.NoError(t, err)
+		assert.Equal(t, "master", name)
+
+		db.CheckSlave(context.Background())
+		assert.True(t, db.SlaveHealthy())
+
+		err = repo.Query(context.Background(), query, []interface{}{&name}, []interface{}{"1"})
+		assert.NoError(t, err)
+		assert.Equal(t, "slave", name)
+	})
+}
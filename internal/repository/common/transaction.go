@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"tensor-graphql/infrastructure/database"
+	"tensor-graphql/pkg/derrors"
+)
+
+type (
+	txContextKey struct{}
+
+	transactionManager struct {
+		db *database.DB
+	}
+
+	// TransactionManager runs units of work in a database transaction.
+	TransactionManager interface {
+		// WithinTransaction calls fn with a context carrying a transaction on
+		// the master. Repository methods called with that context use the
+		// transaction when they are passed a nil tx. The transaction is
+		// committed when fn returns nil and rolled back otherwise. Nested calls
+		// join the transaction that is already on the context.
+		WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error)
+	}
+)
+
+// NewTransactionManager init transaction manager
+func NewTransactionManager(db *database.DB) TransactionManager {
+	return &transactionManager{
+		db: db,
+	}
+}
+
+func (m *transactionManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if TxFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	tx, err := m.db.Master.BeginTx(ctx, nil)
+	if err != nil {
+		return derrors.WrapStack(err, derrors.Unknown, "BeginTx")
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	err = fn(context.WithValue(ctx, txContextKey{}, tx))
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return derrors.WrapStack(err, derrors.Unknown, "tx.Commit")
+	}
+
+	return nil
+}
+
+// TxFromContext returns the transaction started by WithinTransaction, or nil
+// when ctx does not carry one.
+func TxFromContext(ctx context.Context) *sql.Tx {
+	tx, _ := ctx.Value(txContextKey{}).(*sql.Tx)
+	return tx
+}
@@ -0,0 +1,90 @@
+package repository_test
+
+import (
+	"context"
+	"tensor-graphql/infrastructure/database"
+	repository "tensor-graphql/internal/repository/common"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func initTransactionManager(t *testing.T) (repository.TransactionManager, repository.Repository, sqlmock.Sqlmock) {
+	db, dbMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		assert.NoError(t, dbMock.ExpectationsWereMet())
+		db.Close()
+	})
+
+	store := &database.DB{Master: db, Slave: db}
+	return repository.NewTransactionManager(store), repository.NewRepository(store), dbMock
+}
+
+func TestWithinTransaction(t *testing.T) {
+	query := "UPDATE power_plant SET name = ? WHERE id = ?"
+	args := []interface{}{"name", "1"}
+
+	t.Run("WithinTransaction_Commit", func(t *testing.T) {
+		txManager, repo, dbMock := initTransactionManager(t)
+		dbMock.ExpectBegin()
+		dbMock.ExpectExec("UPDATE power_plant").WithArgs("name", "1").WillReturnResult(sqlmock.NewResult(0, 1))
+		dbMock.ExpectExec("UPDATE power_plant").WithArgs("name", "1").WillReturnResult(sqlmock.NewResult(0, 1))
+		dbMock.ExpectCommit()
+
+		err := txManager.WithinTransaction(context.Background(), func(ctx context.Context) error {
+			assert.NotNil(t, repository.TxFromContext(ctx))
+
+			_, err := repo.Exec(ctx, nil, query, args)
+			if err != nil {
+				return err
+			}
+
+			// Nested units of work join the outer transaction.
+			return txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+				_, err := repo.Exec(ctx, nil, query, args)
+				return err
+			})
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("WithinTransaction_Rollback", func(t *testing.T) {
+		txManager, repo, dbMock := initTransactionManager(t)
+		dbMock.ExpectBegin()
+		dbMock.ExpectExec("UPDATE power_plant").WithArgs("name", "1").WillReturnResult(sqlmock.NewResult(0, 1))
+		dbMock.ExpectRollback()
+
+		err := txManager.WithinTransaction(context.Background(), func(ctx context.Context) error {
+			_, err := repo.Exec(ctx, nil, query, args)
+			if err != nil {
+				return err
+			}
+			return assert.AnError
+		})
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+
+	t.Run("WithinTransaction_Panic", func(t *testing.T) {
+		txManager, _, dbMock := initTransactionManager(t)
+		dbMock.ExpectBegin()
+		dbMock.ExpectRollback()
+
+		assert.Panics(t, func() {
+			_ = txManager.WithinTransaction(context.Background(), func(ctx context.Context) error {
+				panic("boom")
+			})
+		})
+	})
+
+	t.Run("Exec_WithoutTransaction", func(t *testing.T) {
+		_, repo, dbMock := initTransactionManager(t)
+		dbMock.ExpectExec("UPDATE power_plant").WithArgs("name", "1").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		_, err := repo.Exec(context.Background(), nil, query, args)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,328 @@
+package portfoliorepository
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"tensor-graphql/infrastructure/database"
+	"tensor-graphql/internal/auth"
+	"tensor-graphql/internal/model"
+	repository "tensor-graphql/internal/repository/common"
+	"tensor-graphql/pkg/derrors"
+)
+
+const portfolioColumns = `id, organization_id, name, created_at, updated_at`
+
+type (
+	portfolioRepository struct {
+		repository.Repository
+	}
+
+	// PortfolioRepository stores portfolios and their members. Every method is
+	// scoped to the organization of the principal on the context. Portfolios
+	// are returned with their members ordered by plant ID.
+	PortfolioRepository interface {
+		repository.Repository
+		// CreatePortfolio inserts the portfolio, SetPortfolioMembers stores its
+		// members.
+		CreatePortfolio(ctx context.Context, tx *sql.Tx, portfolio *model.Portfolio) (err error)
+		GetPortfolioByID(ctx context.Context, id string) (portfolio *model.Portfolio, err error)
+		GetPortfolioByName(ctx context.Context, name string) (portfolio *model.Portfolio, err error)
+		// GetPortfolios returns every portfolio, ordered by ID.
+		GetPortfolios(ctx context.Context) (portfolios []*model.Portfolio, err error)
+		UpdatePortfolio(ctx context.Context, tx *sql.Tx, portfolio *model.Portfolio) (err error)
+		// SetPortfolioMembers replaces the members of the portfolio, the caller
+		// checks that the plants belong to the organization.
+		SetPortfolioMembers(ctx context.Context, tx *sql.Tx, portfolio *model.Portfolio) (err error)
+		DeletePortfolio(ctx context.Context, tx *sql.Tx, id string) (err error)
+	}
+)
+
+func NewPortfolioRepository(store repository.Repository) PortfolioRepository {
+	return &portfolioRepository{
+		Repository: store,
+	}
+}
+
+func (r *portfolioRepository) CreatePortfolio(ctx context.Context, tx *sql.Tx, portfolio *model.Portfolio) (err error) {
+	defer derrors.Wrap(&err, "CreatePortfolio(%q)", portfolio.Name)
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO portfolio (organization_id, name) VALUES (?, ?)`
+	args := []interface{}{
+		organizationID,
+		portfolio.Name,
+	}
+
+	id, err := r.Insert(ctx, tx, query, args)
+	if err != nil {
+		if database.IsDuplicateKey(err) {
+			return duplicateNameError(portfolio.Name)
+		}
+		return derrors.WrapStack(err, derrors.Unknown, "r.Insert")
+	}
+	portfolio.ID = strconv.FormatInt(id, 10)
+
+	// Re-read the row so the database generated timestamps are returned as well.
+	query = `SELECT ` + portfolioColumns + ` FROM portfolio WHERE id = ?`
+	err = r.QueryRowPrimary(ctx, tx, query, portfolio.ID).Scan(r.getDest(portfolio)...)
+	if err != nil {
+		return derrors.HandleSQLError(err, "r.QueryRowPrimary")
+	}
+
+	return nil
+}
+
+func (r *portfolioRepository) GetPortfolioByID(ctx context.Context, id string) (portfolio *model.Portfolio, err error) {
+	defer derrors.Wrap(&err, "GetPortfolioByID(%q)", id)
+
+	return r.getPortfolio(ctx, `id = ?`, id)
+}
+
+func (r *portfolioRepository) GetPortfolioByName(ctx context.Context, name string) (portfolio *model.Portfolio, err error) {
+	defer derrors.Wrap(&err, "GetPortfolioByName(%q)", name)
+
+	return r.getPortfolio(ctx, `name = ?`, name)
+}
+
+// getPortfolio returns the portfolio of the organization matching where, or
+// nil when there is none.
+func (r *portfolioRepository) getPortfolio(ctx context.Context, where string, arg interface{}) (*model.Portfolio, error) {
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + portfolioColumns + ` FROM portfolio WHERE organization_id = ? AND ` + where
+	portfolio := &model.Portfolio{}
+	args := []any{
+		organizationID,
+		arg,
+	}
+
+	err = r.Query(ctx, query, r.getDest(portfolio), args)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, derrors.HandleSQLError(err, "r.Query")
+	}
+
+	err = r.getMembers(ctx, []*model.Portfolio{portfolio})
+	if err != nil {
+		return nil, err
+	}
+
+	return portfolio, nil
+}
+
+func (r *portfolioRepository) GetPortfolios(ctx context.Context) (portfolios []*model.Portfolio, err error) {
+	defer derrors.Wrap(&err, "GetPortfolios")
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + portfolioColumns + ` FROM portfolio WHERE organization_id = ? ORDER BY id`
+	rows, err := r.QueryContext(ctx, query, organizationID)
+	if err != nil {
+		return nil, derrors.HandleSQLError(err, "QueryContext")
+	}
+	defer rows.Close()
+
+	portfolios = make([]*model.Portfolio, 0)
+	for rows.Next() {
+		portfolio := &model.Portfolio{}
+		err = rows.Scan(r.getDest(portfolio)...)
+		if err != nil {
+			return nil, derrors.WrapStack(err, derrors.Unknown, "rows.Scan")
+		}
+		portfolios = append(portfolios, portfolio)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, derrors.WrapStack(err, derrors.Unknown, "rows.Err")
+	}
+
+	err = r.getMembers(ctx, portfolios)
+	if err != nil {
+		return nil, err
+	}
+
+	return portfolios, nil
+}
+
+// getMembers sets the members of portfolios with a single query.
+func (r *portfolioRepository) getMembers(ctx context.Context, portfolios []*model.Portfolio) error {
+	if len(portfolios) == 0 {
+		return nil
+	}
+
+	byID := make(map[string]*model.Portfolio, len(portfolios))
+	args := make([]interface{}, 0, len(portfolios))
+	for _, portfolio := range portfolios {
+		portfolio.Members = make([]*model.PortfolioMember, 0)
+		byID[portfolio.ID] = portfolio
+		args = append(args, portfolio.ID)
+	}
+
+	query := `SELECT portfolio_id, power_plant_id, capacity_mw FROM portfolio_member WHERE portfolio_id IN (?` + strings.Repeat(", ?", len(args)-1) + `) ORDER BY portfolio_id, power_plant_id`
+	rows, err := r.QueryContext(ctx, query, args...)
+	if err != nil {
+		return derrors.HandleSQLError(err, "QueryContext")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var portfolioID string
+		member := &model.PortfolioMember{}
+		err = rows.Scan(&portfolioID, &member.PlantID, &member.CapacityMw)
+		if err != nil {
+			return derrors.WrapStack(err, derrors.Unknown, "rows.Scan")
+		}
+
+		portfolio := byID[portfolioID]
+		portfolio.Members = append(portfolio.Members, member)
+	}
+	if err = rows.Err(); err != nil {
+		return derrors.WrapStack(err, derrors.Unknown, "rows.Err")
+	}
+
+	return nil
+}
+
+func (r *portfolioRepository) UpdatePortfolio(ctx context.Context, tx *sql.Tx, portfolio *model.Portfolio) (err error) {
+	defer derrors.Wrap(&err, "UpdatePortfolio(%q)", portfolio.ID)
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The row is looked up and locked first, MySQL reports no affected rows
+	// for an update that changes nothing.
+	var id string
+	query := `SELECT id FROM portfolio WHERE id = ? AND organization_id = ?` + r.Dialect().ForUpdate()
+	err = r.QueryRowPrimary(ctx, tx, query, portfolio.ID, organizationID).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return derrors.New(derrors.NotFound, "portfolio not found")
+		}
+		return derrors.HandleSQLError(err, "r.QueryRowPrimary")
+	}
+
+	query = `UPDATE portfolio SET name = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND organization_id = ?`
+	args := []interface{}{
+		portfolio.Name,
+		portfolio.ID,
+		organizationID,
+	}
+
+	_, err = r.Exec(ctx, tx, query, args)
+	if err != nil {
+		if database.IsDuplicateKey(err) {
+			return duplicateNameError(portfolio.Name)
+		}
+		return derrors.WrapStack(err, derrors.Unknown, "r.Exec")
+	}
+
+	// Re-read the row for the timestamp maintained by the database.
+	query = `SELECT ` + portfolioColumns + ` FROM portfolio WHERE id = ?`
+	err = r.QueryRowPrimary(ctx, tx, query, portfolio.ID).Scan(r.getDest(portfolio)...)
+	if err != nil {
+		return derrors.HandleSQLError(err, "r.QueryRowPrimary")
+	}
+
+	return nil
+}
+
+func (r *portfolioRepository) SetPortfolioMembers(ctx context.Context, tx *sql.Tx, portfolio *model.Portfolio) (err error) {
+	defer derrors.Wrap(&err, "SetPortfolioMembers(%q, %d)", portfolio.ID, len(portfolio.Members))
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The portfolio is checked first, the members carry no organization.
+	var id string
+	query := `SELECT id FROM portfolio WHERE id = ? AND organization_id = ?`
+	err = r.QueryRowPrimary(ctx, tx, query, portfolio.ID, organizationID).Scan(&id)
+	if err != nil {
+		return derrors.HandleSQLError(err, "r.QueryRowPrimary")
+	}
+
+	query = `DELETE FROM portfolio_member WHERE portfolio_id = ?`
+	_, err = r.Exec(ctx, tx, query, []interface{}{portfolio.ID})
+	if err != nil {
+		return derrors.WrapStack(err, derrors.Unknown, "r.Exec")
+	}
+	if len(portfolio.Members) == 0 {
+		return nil
+	}
+
+	values := make([]string, 0, len(portfolio.Members))
+	args := make([]interface{}, 0, len(portfolio.Members)*3)
+	for _, member := range portfolio.Members {
+		values = append(values, "(?, ?, ?)")
+		args = append(args,
+			portfolio.ID,
+			member.PlantID,
+			member.CapacityMw,
+		)
+	}
+	query = `INSERT INTO portfolio_member (portfolio_id, power_plant_id, capacity_mw) VALUES ` + strings.Join(values, ", ")
+
+	_, err = r.Exec(ctx, tx, query, args)
+	if err != nil {
+		return derrors.WrapStack(err, derrors.Unknown, "r.Exec")
+	}
+
+	return nil
+}
+
+func (r *portfolioRepository) DeletePortfolio(ctx context.Context, tx *sql.Tx, id string) (err error) {
+	defer derrors.Wrap(&err, "DeletePortfolio(%q)", id)
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The members are deleted with the portfolio by the foreign key.
+	query := `DELETE FROM portfolio WHERE id = ? AND organization_id = ?`
+	args := []interface{}{
+		id,
+		organizationID,
+	}
+
+	_, err = r.Exec(ctx, tx, query, args)
+	if err != nil {
+		return derrors.WrapStack(err, derrors.Unknown, "r.Exec")
+	}
+
+	return nil
+}
+
+// duplicateNameError is returned when the unique key on the organization and
+// name rejects a portfolio.
+func duplicateNameError(name string) error {
+	return derrors.NewWithFields(derrors.Duplicate, []derrors.FieldError{
+		{Field: "name", Message: "a portfolio with this name already exists"},
+	}, "duplicate portfolio name %q", name)
+}
+
+func (r *portfolioRepository) getDest(portfolio *model.Portfolio) []interface{} {
+	return []interface{}{
+		&portfolio.ID,
+		&portfolio.OrganizationID,
+		&portfolio.Name,
+		&portfolio.CreatedAt,
+		&portfolio.UpdatedAt,
+	}
+}
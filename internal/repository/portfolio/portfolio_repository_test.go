@@ -0,0 +1,274 @@
+package portfoliorepository_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"tensor-graphql/infrastructure/config"
+	"tensor-graphql/infrastructure/database"
+	"tensor-graphql/internal/auth"
+	"tensor-graphql/internal/constant"
+	"tensor-graphql/internal/model"
+	repository "tensor-graphql/internal/repository/common"
+	portfoliorepository "tensor-graphql/internal/repository/portfolio"
+	powerPlantrepository "tensor-graphql/internal/repository/power_plant"
+	"tensor-graphql/pkg/derrors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	portfolioColumns = []string{"id", "organization_id", "name", "created_at", "updated_at"}
+	memberColumns    = []string{"portfolio_id", "power_plant_id", "capacity_mw"}
+)
+
+func initRepository(t *testing.T) (portfoliorepository.PortfolioRepository, sqlmock.Sqlmock) {
+	db, dbMock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		assert.NoError(t, dbMock.ExpectationsWereMet())
+		db.Close()
+	})
+
+	store := repository.NewRepository(&database.DB{Master: db, Slave: db})
+	return portfoliorepository.NewPortfolioRepository(store), dbMock
+}
+
+func tenantContext(organizationID string) context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{
+		Subject:        "user-1",
+		OrganizationID: organizationID,
+		Roles:          []auth.Role{auth.RoleAdmin},
+	})
+}
+
+func TestPortfolioRepositoryTenantScope(t *testing.T) {
+	now := time.Now()
+	ctx := tenantContext("7")
+
+	t.Run("CreatePortfolio_StoresTenant", func(t *testing.T) {
+		repo, dbMock := initRepository(t)
+		dbMock.ExpectExec(regexp.QuoteMeta(`INSERT INTO portfolio (organization_id, name) VALUES (?, ?)`)).
+			WithArgs("7", "North Sea wind").
+			WillReturnResult(sqlmock.NewResult(10, 1))
+		dbMock.ExpectQuery(`FROM portfolio WHERE id = \?`).
+			WithArgs("10").
+			WillReturnRows(sqlmock.NewRows(portfolioColumns).AddRow(10, 7, "North Sea wind", now, now))
+
+		portfolio := &model.Portfolio{Name: "North Sea wind"}
+		err := repo.CreatePortfolio(ctx, nil, portfolio)
+		assert.NoError(t, err)
+		assert.Equal(t, "10", portfolio.ID)
+	})
+
+	t.Run("GetPortfolios_WithMembers", func(t *testing.T) {
+		repo, dbMock := initRepository(t)
+		dbMock.ExpectQuery(`FROM portfolio WHERE organization_id = \? ORDER BY id`).
+			WithArgs("7").
+			WillReturnRows(sqlmock.NewRows(portfolioColumns).
+				AddRow(10, 7, "North Sea wind", now, now).
+				AddRow(11, 7, "Iberia PV", now, now))
+		dbMock.ExpectQuery(regexp.QuoteMeta(`FROM portfolio_member WHERE portfolio_id IN (?, ?) ORDER BY portfolio_id, power_plant_id`)).
+			WithArgs("10", "11").
+			WillReturnRows(sqlmock.NewRows(memberColumns).
+				AddRow(10, 1, 400.0).
+				AddRow(10, 2, 250.5))
+
+		portfolios, err := repo.GetPortfolios(ctx)
+		assert.NoError(t, err)
+		if assert.Len(t, portfolios, 2) {
+			assert.Equal(t, []*model.PortfolioMember{
+				{PlantID: "1", CapacityMw: 400},
+				{PlantID: "2", CapacityMw: 250.5},
+			}, portfolios[0].Members)
+			assert.Empty(t, portfolios[1].Members)
+		}
+	})
+
+	t.Run("GetPortfolioByID_OtherTenant", func(t *testing.T) {
+		repo, dbMock := initRepository(t)
+		dbMock.ExpectQuery(`FROM portfolio WHERE organization_id = \? AND id = \?`).
+			WithArgs("7", "12").
+			WillReturnRows(sqlmock.NewRows(portfolioColumns))
+
+		portfolio, err := repo.GetPortfolioByID(ctx, "12")
+		assert.NoError(t, err)
+		assert.Nil(t, portfolio)
+	})
+
+	t.Run("SetPortfolioMembers_ReplacesMembers", func(t *testing.T) {
+		repo, dbMock := initRepository(t)
+		dbMock.ExpectQuery(`SELECT id FROM portfolio WHERE id = \? AND organization_id = \?`).
+			WithArgs("10", "7").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+		dbMock.ExpectExec(`DELETE FROM portfolio_member WHERE portfolio_id = \?`).
+			WithArgs("10").
+			WillReturnResult(sqlmock.NewResult(0, 3))
+		dbMock.ExpectExec(regexp.QuoteMeta(`INSERT INTO portfolio_member (portfolio_id, power_plant_id, capacity_mw) VALUES (?, ?, ?), (?, ?, ?)`)).
+			WithArgs("10", "1", 400.0, "10", "2", 250.5).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		err := repo.SetPortfolioMembers(ctx, nil, &model.Portfolio{
+			ID: "10",
+			Members: []*model.PortfolioMember{
+				{PlantID: "1", CapacityMw: 400},
+				{PlantID: "2", CapacityMw: 250.5},
+			},
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("SetPortfolioMembers_OtherTenant", func(t *testing.T) {
+		repo, dbMock := initRepository(t)
+		dbMock.ExpectQuery(`SELECT id FROM portfolio WHERE id = \? AND organization_id = \?`).
+			WithArgs("12", "7").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		err := repo.SetPortfolioMembers(ctx, nil, &model.Portfolio{
+			ID:      "12",
+			Members: []*model.PortfolioMember{{PlantID: "1", CapacityMw: 400}},
+		})
+		assert.True(t, derrors.IsErrCode(err, derrors.NotFound))
+	})
+
+	t.Run("UpdatePortfolio_UnchangedName", func(t *testing.T) {
+		repo, dbMock := initRepository(t)
+		dbMock.ExpectQuery(`SELECT id FROM portfolio WHERE id = \? AND organization_id = \? FOR UPDATE`).
+			WithArgs("10", "7").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+		// MySQL counts no affected rows when the update changes nothing.
+		dbMock.ExpectExec(`UPDATE portfolio SET .* WHERE id = \? AND organization_id = \?`).
+			WithArgs("North Sea wind", "10", "7").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		dbMock.ExpectQuery(`FROM portfolio WHERE id = \?`).
+			WithArgs("10").
+			WillReturnRows(sqlmock.NewRows(portfolioColumns).AddRow(10, 7, "North Sea wind", now, now))
+
+		err := repo.UpdatePortfolio(ctx, nil, &model.Portfolio{ID: "10", Name: "North Sea wind"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("UpdatePortfolio_OtherTenant", func(t *testing.T) {
+		repo, dbMock := initRepository(t)
+		dbMock.ExpectQuery(`SELECT id FROM portfolio WHERE id = \? AND organization_id = \? FOR UPDATE`).
+			WithArgs("12", "7").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		err := repo.UpdatePortfolio(ctx, nil, &model.Portfolio{ID: "12", Name: "Iberia PV"})
+		assert.True(t, derrors.IsErrCode(err, derrors.NotFound))
+	})
+}
+
+func TestPortfolioRepositoryWithoutTenant(t *testing.T) {
+	repo, _ := initRepository(t)
+	ctx := context.Background()
+
+	_, err := repo.GetPortfolios(ctx)
+	assert.True(t, derrors.IsErrCode(err, derrors.Unauthorized))
+
+	err = repo.SetPortfolioMembers(ctx, nil, &model.Portfolio{ID: "10"})
+	assert.True(t, derrors.IsErrCode(err, derrors.Unauthorized))
+
+	err = repo.DeletePortfolio(ctx, nil, "10")
+	assert.True(t, derrors.IsErrCode(err, derrors.Unauthorized))
+}
+
+// TestPortfolioRepositorySQLite runs the statements against the migrated
+// schema, including the foreign keys that remove members.
+func TestPortfolioRepositorySQLite(t *testing.T) {
+	conf := &config.DB{
+		Driver:           "sqlite",
+		ConnectionString: fmt.Sprintf(constant.SQLiteDBStringConnection, filepath.Join(t.TempDir(), "tensor.db")),
+	}
+
+	migrator, err := database.NewMigrator(conf)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Up())
+	require.NoError(t, migrator.Close())
+
+	db, err := database.InitializeDatabase(&config.Config{DBMaster: conf, DBSlave: conf})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.Master.Close()
+		db.Slave.Close()
+	})
+
+	store := repository.NewRepository(db)
+	repo := portfoliorepository.NewPortfolioRepository(store)
+	plantRepo := powerPlantrepository.NewPowerPlantRepository(store)
+	ctx := tenantContext("1")
+
+	plantA := &model.PowerPlant{Name: "Plant A", Latitude: 1.5, Longitude: 2.5, Timezone: "UTC"}
+	plantB := &model.PowerPlant{Name: "Plant B", Latitude: 3.5, Longitude: 4.5, Timezone: "UTC"}
+	require.NoError(t, plantRepo.CreatePowerPlants(ctx, nil, []*model.PowerPlant{plantA, plantB}))
+
+	portfolio := &model.Portfolio{
+		Name: "North Sea wind",
+		Members: []*model.PortfolioMember{
+			{PlantID: plantB.ID, CapacityMw: 250.5},
+			{PlantID: plantA.ID, CapacityMw: 400},
+		},
+	}
+	require.NoError(t, repo.CreatePortfolio(ctx, nil, portfolio))
+	require.NoError(t, repo.SetPortfolioMembers(ctx, nil, portfolio))
+	assert.False(t, portfolio.CreatedAt.IsNil())
+
+	stored, err := repo.GetPortfolioByName(ctx, "North Sea wind")
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.Equal(t, portfolio.ID, stored.ID)
+	assert.Equal(t, []*model.PortfolioMember{
+		{PlantID: plantA.ID, CapacityMw: 400},
+		{PlantID: plantB.ID, CapacityMw: 250.5},
+	}, stored.Members)
+
+	other, err := repo.GetPortfolioByID(tenantContext("2"), portfolio.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, other)
+
+	// A deleted plant leaves the portfolio.
+	require.NoError(t, plantRepo.DeletePowerPlant(ctx, nil, plantA.ID))
+	stored, err = repo.GetPortfolioByID(ctx, portfolio.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []*model.PortfolioMember{{PlantID: plantB.ID, CapacityMw: 250.5}}, stored.Members)
+
+	// Keeping the name while the members change is not mistaken for a
+	// missing portfolio.
+	require.NoError(t, repo.UpdatePortfolio(ctx, nil, stored))
+	assert.Equal(t, "North Sea wind", stored.Name)
+
+	duplicate := &model.Portfolio{Name: "North Sea wind"}
+	err = repo.CreatePortfolio(ctx, nil, duplicate)
+	assert.True(t, derrors.IsErrCode(err, derrors.Duplicate))
+
+	stored.Name = "Baltic wind"
+	stored.Members = nil
+	require.NoError(t, repo.UpdatePortfolio(ctx, nil, stored))
+	require.NoError(t, repo.SetPortfolioMembers(ctx, nil, stored))
+	portfolios, err := repo.GetPortfolios(ctx)
+	require.NoError(t, err)
+	if assert.Len(t, portfolios, 1) {
+		assert.Equal(t, "Baltic wind", portfolios[0].Name)
+		assert.Empty(t, portfolios[0].Members)
+	}
+
+	require.NoError(t, repo.SetPortfolioMembers(ctx, nil, &model.Portfolio{
+		ID:      portfolio.ID,
+		Members: []*model.PortfolioMember{{PlantID: plantB.ID, CapacityMw: 100}},
+	}))
+	require.NoError(t, repo.DeletePortfolio(ctx, nil, portfolio.ID))
+	portfolios, err = repo.GetPortfolios(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, portfolios)
+
+	var members int
+	require.NoError(t, db.Master.QueryRow(`SELECT COUNT(*) FROM portfolio_member`).Scan(&members))
+	assert.Zero(t, members)
+}
@@ -0,0 +1,360 @@
+package powerPlantrepository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"tensor-graphql/infrastructure/database"
+	"tensor-graphql/internal/auth"
+	"tensor-graphql/internal/model"
+	"tensor-graphql/pkg/derrors"
+	"tensor-graphql/pkg/geo"
+)
+
+func (r *powerPlantRepository) GetNearbyPowerPlants(ctx context.Context, latitude, longitude, radiusKm float64, limit int) (powerPlants []*model.PowerPlant, err error) {
+	defer derrors.Wrap(&err, "GetNearbyPowerPlants(%v, %v, %v)", latitude, longitude, radiusKm)
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	center := geo.Point{Latitude: latitude, Longitude: longitude}
+	bounds := geo.BoundsAround(center, radiusKm)
+
+	if !r.Dialect().SupportsSpatial() {
+		// Without GIS the bounding box narrows the rows down on the coordinate
+		// index and the haversine distance is computed here.
+		powerPlants, err = r.getPowerPlantsInBounds(ctx, organizationID, bounds)
+		if err != nil {
+			return nil, err
+		}
+		return nearest(powerPlants, center, radiusKm, limit), nil
+	}
+
+	var (
+		query string
+		args  []interface{}
+	)
+	if r.Dialect() == database.Postgres {
+		// ST_DWithin finds the plants on the GiST index, the distances are
+		// spherical like those of the other backends.
+		query = `SELECT ` + powerPlantColumns + `, ST_Distance(location, ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography, false) / 1000 AS distance_km FROM power_plant WHERE organization_id = ? AND ST_DWithin(location, ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography, ?, false) ORDER BY distance_km, id LIMIT ?`
+		args = []interface{}{
+			longitude,
+			latitude,
+			organizationID,
+			longitude,
+			latitude,
+			radiusKm * 1000,
+			limit,
+		}
+	} else {
+		query = `SELECT ` + powerPlantColumns + `, ST_Distance_Sphere(location, ST_PointFromText(?, 4326, 'axis-order=lat-long')) / 1000 AS distance_km FROM power_plant WHERE organization_id = ?`
+		args = []interface{}{
+			pointWKT(center),
+			organizationID,
+		}
+		if spatialIndexable(bounds) {
+			// The box lets the spatial index skip plants far away.
+			query += ` AND MBRContains(ST_PolygonFromText(?, 4326, 'axis-order=lat-long'), location)`
+			args = append(args, boundsWKT(bounds))
+		}
+		query += ` HAVING distance_km <= ? ORDER BY distance_km, id LIMIT ?`
+		args = append(args, radiusKm, limit)
+	}
+
+	rows, err := r.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, derrors.HandleSQLError(err, "QueryContext")
+	}
+	defer rows.Close()
+
+	powerPlants = make([]*model.PowerPlant, 0)
+	for rows.Next() {
+		powerPlant := &model.PowerPlant{}
+		var distanceKm float64
+		err = rows.Scan(append(r.getDest(powerPlant), &distanceKm)...)
+		if err != nil {
+			return nil, derrors.WrapStack(err, derrors.Unknown, "rows.Scan")
+		}
+		powerPlant.DistanceKm = &distanceKm
+
+		powerPlants = append(powerPlants, powerPlant)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, derrors.WrapStack(err, derrors.Unknown, "rows.Err")
+	}
+
+	return powerPlants, nil
+}
+
+func (r *powerPlantRepository) GetPowerPlantsInBounds(ctx context.Context, bounds geo.Bounds) (powerPlants []*model.PowerPlant, err error) {
+	defer derrors.Wrap(&err, "GetPowerPlantsInBounds(%+v)", bounds)
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.getPowerPlantsInBounds(ctx, organizationID, bounds)
+}
+
+func (r *powerPlantRepository) GetPowerPlantsInPolygon(ctx context.Context, polygon geo.Polygon) (powerPlants []*model.PowerPlant, err error) {
+	defer derrors.Wrap(&err, "GetPowerPlantsInPolygon")
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	condition, args := polygonCondition(polygon)
+	return r.queryPowerPlantsInBounds(ctx, organizationID, polygon.Bounds(), condition, args)
+}
+
+func (r *powerPlantRepository) ClusterPowerPlantsInBounds(ctx context.Context, bounds geo.Bounds, gridSize int) (clusters []geo.Cluster, err error) {
+	defer derrors.Wrap(&err, "ClusterPowerPlantsInBounds(%+v, %d)", bounds, gridSize)
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.clusterPowerPlants(ctx, organizationID, bounds, gridSize, "", nil)
+}
+
+func (r *powerPlantRepository) ClusterPowerPlantsInPolygon(ctx context.Context, polygon geo.Polygon, gridSize int) (clusters []geo.Cluster, err error) {
+	defer derrors.Wrap(&err, "ClusterPowerPlantsInPolygon(%d)", gridSize)
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	condition, args := polygonCondition(polygon)
+	return r.clusterPowerPlants(ctx, organizationID, polygon.Bounds(), gridSize, condition, args)
+}
+
+// getPowerPlantsInBounds returns the plants of the organization inside bounds.
+func (r *powerPlantRepository) getPowerPlantsInBounds(ctx context.Context, organizationID string, bounds geo.Bounds) (powerPlants []*model.PowerPlant, err error) {
+	return r.queryPowerPlantsInBounds(ctx, organizationID, bounds, "", nil)
+}
+
+// queryPowerPlantsInBounds returns the plants of the organization inside
+// bounds that also match condition, when given.
+func (r *powerPlantRepository) queryPowerPlantsInBounds(ctx context.Context, organizationID string, bounds geo.Bounds, condition string, conditionArgs []interface{}) (powerPlants []*model.PowerPlant, err error) {
+	where, args := r.boundsCondition(organizationID, bounds)
+	if condition != "" {
+		where += ` AND ` + condition
+		args = append(args, conditionArgs...)
+	}
+	query := `SELECT ` + powerPlantColumns + ` FROM power_plant WHERE ` + where + ` ORDER BY id`
+
+	rows, err := r.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, derrors.HandleSQLError(err, "QueryContext")
+	}
+	defer rows.Close()
+
+	powerPlants = make([]*model.PowerPlant, 0)
+	for rows.Next() {
+		powerPlant := &model.PowerPlant{}
+		err = rows.Scan(r.getDest(powerPlant)...)
+		if err != nil {
+			return nil, derrors.WrapStack(err, derrors.Unknown, "rows.Scan")
+		}
+
+		powerPlants = append(powerPlants, powerPlant)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, derrors.WrapStack(err, derrors.Unknown, "rows.Err")
+	}
+
+	return powerPlants, nil
+}
+
+// clusterPowerPlants counts the plants of the organization inside bounds that
+// also match condition per cell of a grid over bounds. The database groups the
+// rows by cell, so only one row per cell is read.
+func (r *powerPlantRepository) clusterPowerPlants(ctx context.Context, organizationID string, bounds geo.Bounds, gridSize int, condition string, conditionArgs []interface{}) (clusters []geo.Cluster, err error) {
+	grid := geo.NewGrid(bounds, gridSize)
+
+	// Longitudes are offset east of West like in geo.ClusterGrid, across the
+	// antimeridian they wrap around.
+	offset := `CASE WHEN longitude >= ? THEN longitude - ? ELSE longitude - ? + 360 END`
+	query := `SELECT FLOOR((latitude - ?) / ?) AS cell_row, FLOOR((` + offset + `) / ?) AS cell_column, COUNT(*), SUM(latitude), SUM(` + offset + `) FROM power_plant WHERE `
+	args := []interface{}{
+		bounds.South,
+		cellDivisor(grid.CellHeight()),
+		bounds.West,
+		bounds.West,
+		bounds.West,
+		cellDivisor(grid.CellWidth()),
+		bounds.West,
+		bounds.West,
+		bounds.West,
+	}
+
+	where, whereArgs := r.boundsCondition(organizationID, bounds)
+	query += where
+	args = append(args, whereArgs...)
+	if condition != "" {
+		query += ` AND ` + condition
+		args = append(args, conditionArgs...)
+	}
+	query += ` GROUP BY cell_row, cell_column`
+
+	rows, err := r.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, derrors.HandleSQLError(err, "QueryContext")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			row, column         float64
+			count               int
+			latitude, offsetSum float64
+		)
+		err = rows.Scan(&row, &column, &count, &latitude, &offsetSum)
+		if err != nil {
+			return nil, derrors.WrapStack(err, derrors.Unknown, "rows.Scan")
+		}
+
+		// Plants on the north or east edge get an index past the grid, Add
+		// counts them for the last cell.
+		grid.Add(int(row), int(column), count, latitude, offsetSum)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, derrors.WrapStack(err, derrors.Unknown, "rows.Err")
+	}
+
+	return grid.Clusters(), nil
+}
+
+// boundsCondition matches the plants of the organization inside bounds by
+// comparing the latitude and longitude columns, on MySQL the spatial index
+// narrows the rows down first. PostgreSQL and SQLite use the coordinate index.
+func (r *powerPlantRepository) boundsCondition(organizationID string, bounds geo.Bounds) (condition string, args []interface{}) {
+	condition = `organization_id = ?`
+	args = []interface{}{
+		organizationID,
+	}
+	if dialect := r.Dialect(); dialect.SupportsSpatial() && dialect != database.Postgres && spatialIndexable(bounds) {
+		// The envelope of the geodesic box holds the latitude and longitude box.
+		condition += ` AND MBRContains(ST_PolygonFromText(?, 4326, 'axis-order=lat-long'), location)`
+		args = append(args, boundsWKT(bounds))
+	}
+	condition += ` AND latitude BETWEEN ? AND ?`
+	args = append(args, bounds.South, bounds.North)
+	if bounds.CrossesAntimeridian() {
+		condition += ` AND (longitude >= ? OR longitude <= ?)`
+	} else {
+		condition += ` AND longitude BETWEEN ? AND ?`
+	}
+	args = append(args, bounds.West, bounds.East)
+
+	return condition, args
+}
+
+// polygonCondition matches the plants inside polygon like geo.Polygon.Contains:
+// a ray cast from the plant crosses the edges of the outer ring an odd number
+// of times and those of every hole an even number of times. GeoJSON edges are
+// straight in latitude and longitude, unlike the geodesic edges of MySQL, so
+// the spatial functions are not used.
+func polygonCondition(polygon geo.Polygon) (condition string, args []interface{}) {
+	conditions := make([]string, 0, len(polygon.Rings))
+	for k, ring := range polygon.Rings {
+		crossings := []string{"0"}
+		for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+			a, b := ring[i], ring[j]
+			// The ray never crosses an edge along a parallel.
+			if a.Latitude == b.Latitude {
+				continue
+			}
+			crossings = append(crossings, `CASE WHEN (latitude < ?) <> (latitude < ?) AND longitude < (? * (latitude - ?) / ? + ?) THEN 1 ELSE 0 END`)
+			args = append(args, a.Latitude, b.Latitude, b.Longitude-a.Longitude, a.Latitude, b.Latitude-a.Latitude, a.Longitude)
+		}
+
+		parity := "0"
+		if k == 0 {
+			parity = "1"
+		}
+		conditions = append(conditions, `(`+strings.Join(crossings, " + ")+`) % 2 = `+parity)
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// cellDivisor keeps a grid over bounds without height or width from dividing
+// by zero, every plant inside them has an offset of 0 then.
+func cellDivisor(size float64) float64 {
+	if size <= 0 {
+		return 1
+	}
+	return size
+}
+
+// nearest sets the distance of the plants to center and returns at most limit
+// of them within radiusKm, nearest first.
+func nearest(powerPlants []*model.PowerPlant, center geo.Point, radiusKm float64, limit int) []*model.PowerPlant {
+	found := make([]*model.PowerPlant, 0)
+	for _, powerPlant := range powerPlants {
+		distanceKm := geo.DistanceKm(center, geo.Point{Latitude: powerPlant.Latitude, Longitude: powerPlant.Longitude})
+		if distanceKm > radiusKm {
+			continue
+		}
+		powerPlant.DistanceKm = &distanceKm
+		found = append(found, powerPlant)
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		if *found[i].DistanceKm != *found[j].DistanceKm {
+			return *found[i].DistanceKm < *found[j].DistanceKm
+		}
+		return idLess(found[i].ID, found[j].ID)
+	})
+
+	if len(found) > limit {
+		found = found[:limit]
+	}
+	return found
+}
+
+// inPolygon returns the plants inside polygon.
+func inPolygon(powerPlants []*model.PowerPlant, polygon geo.Polygon) []*model.PowerPlant {
+	found := make([]*model.PowerPlant, 0)
+	for _, powerPlant := range powerPlants {
+		if polygon.Contains(geo.Point{Latitude: powerPlant.Latitude, Longitude: powerPlant.Longitude}) {
+			found = append(found, powerPlant)
+		}
+	}
+	return found
+}
+
+// idLess orders IDs numerically like the id column.
+func idLess(a, b string) bool {
+	x, _ := strconv.ParseInt(a, 10, 64)
+	y, _ := strconv.ParseInt(b, 10, 64)
+	return x < y
+}
+
+// spatialIndexable reports whether bounds makes a valid MySQL polygon: one
+// that does not wrap around the earth, touch a pole or have no area.
+func spatialIndexable(bounds geo.Bounds) bool {
+	return bounds.South > -90 && bounds.North < 90 && bounds.South < bounds.North &&
+		bounds.West > -180 && bounds.East < 180 && bounds.West < bounds.East
+}
+
+// pointWKT and boundsWKT write coordinates in the latitude, longitude order
+// passed to MySQL with axis-order=lat-long.
+func pointWKT(p geo.Point) string {
+	return fmt.Sprintf("POINT(%v %v)", p.Latitude, p.Longitude)
+}
+
+func boundsWKT(b geo.Bounds) string {
+	return fmt.Sprintf("POLYGON((%[1]v %[2]v, %[3]v %[2]v, %[3]v %[4]v, %[1]v %[4]v, %[1]v %[2]v))",
+		b.South, b.West, b.North, b.East)
+}
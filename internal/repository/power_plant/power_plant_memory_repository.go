@@ -0,0 +1,474 @@
+package powerPlantrepository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"tensor-graphql/infrastructure/database"
+	"tensor-graphql/internal/auth"
+	"tensor-graphql/internal/model"
+	repository "tensor-graphql/internal/repository/common"
+	"tensor-graphql/pkg/datatype"
+	"tensor-graphql/pkg/derrors"
+	"tensor-graphql/pkg/geo"
+	"time"
+)
+
+type (
+	memoryPowerPlantRepository struct {
+		repository.Repository
+
+		mu          sync.RWMutex
+		lastID      int64
+		powerPlants map[string]*memoryPowerPlant
+	}
+
+	memoryPowerPlant struct {
+		organizationID string
+		powerPlant     model.PowerPlant
+	}
+)
+
+// NewMemoryPowerPlantRepository returns a PowerPlantRepository that keeps the
+// plants in memory, e.g. for tests. It is safe for concurrent use and behaves
+// like the SQL implementation, but the tx arguments are ignored, so writes are
+// not rolled back with a transaction. The SQL helpers of the embedded
+// Repository have no database behind them.
+func NewMemoryPowerPlantRepository() PowerPlantRepository {
+	return &memoryPowerPlantRepository{
+		Repository:  repository.NewRepository(&database.DB{}),
+		powerPlants: make(map[string]*memoryPowerPlant),
+	}
+}
+
+func (r *memoryPowerPlantRepository) CreatePowerPlant(ctx context.Context, tx *sql.Tx, powerPlant *model.PowerPlant) (err error) {
+	defer derrors.Wrap(&err, "CreatePowerPlant(%q)", powerPlant.ID)
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, taken := r.names(organizationID)[powerPlant.Name]; taken {
+		return duplicateNameError(powerPlant.Name)
+	}
+
+	// Like the database, only the stored columns are kept and returned.
+	now := datatype.NewTime(memoryNow())
+	r.lastID++
+	stored := &memoryPowerPlant{
+		organizationID: organizationID,
+		powerPlant: model.PowerPlant{
+			ID:        strconv.FormatInt(r.lastID, 10),
+			Name:      powerPlant.Name,
+			Latitude:  powerPlant.Latitude,
+			Longitude: powerPlant.Longitude,
+			Timezone:  powerPlant.Timezone,
+			Version:   1,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+	}
+	r.powerPlants[stored.powerPlant.ID] = stored
+	*powerPlant = stored.powerPlant
+
+	return nil
+}
+
+func (r *memoryPowerPlantRepository) CreatePowerPlants(ctx context.Context, tx *sql.Tx, powerPlants []*model.PowerPlant) (err error) {
+	defer derrors.Wrap(&err, "CreatePowerPlants(%d)", len(powerPlants))
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Like the multi-row INSERT, a name already used fails every row.
+	names := r.names(organizationID)
+	var itemErrs []error
+	for i, powerPlant := range powerPlants {
+		if _, taken := names[powerPlant.Name]; taken {
+			itemErrs = append(itemErrs, &ItemError{Index: i, Err: duplicateNameError(powerPlant.Name)})
+		}
+		names[powerPlant.Name] = ""
+	}
+	if len(itemErrs) > 0 {
+		return errors.Join(itemErrs...)
+	}
+
+	now := datatype.NewTime(memoryNow())
+	for _, powerPlant := range powerPlants {
+		r.lastID++
+		stored := &memoryPowerPlant{
+			organizationID: organizationID,
+			powerPlant: model.PowerPlant{
+				ID:        strconv.FormatInt(r.lastID, 10),
+				Name:      powerPlant.Name,
+				Latitude:  powerPlant.Latitude,
+				Longitude: powerPlant.Longitude,
+				Timezone:  powerPlant.Timezone,
+				Version:   1,
+				CreatedAt: now,
+				UpdatedAt: now,
+			},
+		}
+		r.powerPlants[stored.powerPlant.ID] = stored
+		*powerPlant = stored.powerPlant
+	}
+
+	return nil
+}
+
+func (r *memoryPowerPlantRepository) GetPowerPlantByID(ctx context.Context, id string) (powerPlant *model.PowerPlant, err error) {
+	defer derrors.Wrap(&err, "GetPowerPlantByID(%q)", id)
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.powerPlants[id]
+	if !ok || stored.organizationID != organizationID {
+		return nil, nil
+	}
+	found := stored.powerPlant
+
+	return &found, nil
+}
+
+func (r *memoryPowerPlantRepository) GetPowerPlantsByIDs(ctx context.Context, ids []string) (powerPlants []*model.PowerPlant, err error) {
+	defer derrors.Wrap(&err, "GetPowerPlantsByIDs(%d)", len(ids))
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	powerPlants = make([]*model.PowerPlant, 0, len(ids))
+	for _, stored := range r.sorted(organizationID) {
+		if wanted[stored.powerPlant.ID] {
+			found := stored.powerPlant
+			powerPlants = append(powerPlants, &found)
+		}
+	}
+
+	return powerPlants, nil
+}
+
+func (r *memoryPowerPlantRepository) GetPowerPlantByName(ctx context.Context, name string) (powerPlant *model.PowerPlant, err error) {
+	defer derrors.Wrap(&err, "GetPowerPlantByName(%q)", name)
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, stored := range r.sorted(organizationID) {
+		if stored.powerPlant.Name == name {
+			found := stored.powerPlant
+			return &found, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *memoryPowerPlantRepository) GetPowerPlants(ctx context.Context, page, limit int) (powerPlants []*model.PowerPlant, total int, err error) {
+	defer derrors.Wrap(&err, "GetPowerPlants")
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	all := r.sorted(organizationID)
+	offset := r.GetOffset(page, limit)
+	if offset < 0 {
+		offset = 0
+	}
+
+	powerPlants = make([]*model.PowerPlant, 0)
+	for i := offset; i < len(all) && i < offset+limit; i++ {
+		found := all[i].powerPlant
+		powerPlants = append(powerPlants, &found)
+	}
+
+	return powerPlants, len(all), nil
+}
+
+func (r *memoryPowerPlantRepository) StreamPowerPlants(ctx context.Context, fn func(powerPlant *model.PowerPlant) error) (err error) {
+	defer derrors.Wrap(&err, "StreamPowerPlants")
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return err
+	}
+
+	// fn runs without the lock, so it may use the repository itself.
+	r.mu.RLock()
+	var powerPlants []model.PowerPlant
+	for _, stored := range r.sorted(organizationID) {
+		powerPlants = append(powerPlants, stored.powerPlant)
+	}
+	r.mu.RUnlock()
+
+	for i := range powerPlants {
+		err = fn(&powerPlants[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *memoryPowerPlantRepository) GetNearbyPowerPlants(ctx context.Context, latitude, longitude, radiusKm float64, limit int) (powerPlants []*model.PowerPlant, err error) {
+	defer derrors.Wrap(&err, "GetNearbyPowerPlants(%v, %v, %v)", latitude, longitude, radiusKm)
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, stored := range r.sorted(organizationID) {
+		found := stored.powerPlant
+		powerPlants = append(powerPlants, &found)
+	}
+
+	return nearest(powerPlants, geo.Point{Latitude: latitude, Longitude: longitude}, radiusKm, limit), nil
+}
+
+func (r *memoryPowerPlantRepository) GetPowerPlantsInBounds(ctx context.Context, bounds geo.Bounds) (powerPlants []*model.PowerPlant, err error) {
+	defer derrors.Wrap(&err, "GetPowerPlantsInBounds(%+v)", bounds)
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	powerPlants = make([]*model.PowerPlant, 0)
+	for _, stored := range r.sorted(organizationID) {
+		if bounds.Contains(geo.Point{Latitude: stored.powerPlant.Latitude, Longitude: stored.powerPlant.Longitude}) {
+			found := stored.powerPlant
+			powerPlants = append(powerPlants, &found)
+		}
+	}
+
+	return powerPlants, nil
+}
+
+func (r *memoryPowerPlantRepository) GetPowerPlantsInPolygon(ctx context.Context, polygon geo.Polygon) (powerPlants []*model.PowerPlant, err error) {
+	defer derrors.Wrap(&err, "GetPowerPlantsInPolygon")
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, stored := range r.sorted(organizationID) {
+		found := stored.powerPlant
+		powerPlants = append(powerPlants, &found)
+	}
+
+	return inPolygon(powerPlants, polygon), nil
+}
+
+func (r *memoryPowerPlantRepository) ClusterPowerPlantsInBounds(ctx context.Context, bounds geo.Bounds, gridSize int) (clusters []geo.Cluster, err error) {
+	powerPlants, err := r.GetPowerPlantsInBounds(ctx, bounds)
+	if err != nil {
+		return nil, err
+	}
+
+	return geo.ClusterGrid(points(powerPlants), bounds, gridSize), nil
+}
+
+func (r *memoryPowerPlantRepository) ClusterPowerPlantsInPolygon(ctx context.Context, polygon geo.Polygon, gridSize int) (clusters []geo.Cluster, err error) {
+	powerPlants, err := r.GetPowerPlantsInPolygon(ctx, polygon)
+	if err != nil {
+		return nil, err
+	}
+
+	return geo.ClusterGrid(points(powerPlants), polygon.Bounds(), gridSize), nil
+}
+
+func (r *memoryPowerPlantRepository) UpdatePowerPlant(ctx context.Context, tx *sql.Tx, powerPlant *model.PowerPlant) (err error) {
+	defer derrors.Wrap(&err, "UpdatePowerPlant(%q)", powerPlant.ID)
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.powerPlants[powerPlant.ID]
+	if !ok || stored.organizationID != organizationID || stored.powerPlant.Version != powerPlant.Version {
+		return derrors.New(derrors.Conflict, "power plant was changed or deleted since version %d", powerPlant.Version)
+	}
+	if id, taken := r.names(organizationID)[powerPlant.Name]; taken && id != powerPlant.ID {
+		return duplicateNameError(powerPlant.Name)
+	}
+
+	stored.powerPlant.Name = powerPlant.Name
+	stored.powerPlant.Latitude = powerPlant.Latitude
+	stored.powerPlant.Longitude = powerPlant.Longitude
+	stored.powerPlant.Timezone = powerPlant.Timezone
+	stored.powerPlant.Version++
+	stored.powerPlant.UpdatedAt = datatype.NewTime(memoryNow())
+	powerPlant.Version++
+
+	return nil
+}
+
+func (r *memoryPowerPlantRepository) UpdatePowerPlants(ctx context.Context, tx *sql.Tx, powerPlants []*model.PowerPlant) (err error) {
+	defer derrors.Wrap(&err, "UpdatePowerPlants(%d)", len(powerPlants))
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Every version and name is checked before anything changes, as a rolled
+	// back transaction would leave the plants.
+	versions := make(map[string]int)
+	names := r.names(organizationID)
+	for i, powerPlant := range powerPlants {
+		version, ok := versions[powerPlant.ID]
+		if !ok {
+			stored, found := r.powerPlants[powerPlant.ID]
+			if found && stored.organizationID == organizationID {
+				version = stored.powerPlant.Version
+			}
+		}
+		if version == 0 || version != powerPlant.Version {
+			return &ItemError{Index: i, Err: derrors.New(derrors.Conflict, "power plant was changed or deleted since version %d", powerPlant.Version)}
+		}
+		versions[powerPlant.ID] = version + 1
+
+		if id, taken := names[powerPlant.Name]; taken && id != powerPlant.ID {
+			return &ItemError{Index: i, Err: duplicateNameError(powerPlant.Name)}
+		}
+		for name, id := range names {
+			if id == powerPlant.ID {
+				delete(names, name)
+			}
+		}
+		names[powerPlant.Name] = powerPlant.ID
+	}
+
+	now := datatype.NewTime(memoryNow())
+	for _, powerPlant := range powerPlants {
+		stored := r.powerPlants[powerPlant.ID]
+		stored.powerPlant.Name = powerPlant.Name
+		stored.powerPlant.Latitude = powerPlant.Latitude
+		stored.powerPlant.Longitude = powerPlant.Longitude
+		stored.powerPlant.Timezone = powerPlant.Timezone
+		stored.powerPlant.Version++
+		stored.powerPlant.UpdatedAt = now
+		*powerPlant = stored.powerPlant
+	}
+
+	return nil
+}
+
+func (r *memoryPowerPlantRepository) DeletePowerPlant(ctx context.Context, tx *sql.Tx, id string) (err error) {
+	defer derrors.Wrap(&err, "DeletePowerPlant(%q)", id)
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stored, ok := r.powerPlants[id]; ok && stored.organizationID == organizationID {
+		delete(r.powerPlants, id)
+	}
+
+	return nil
+}
+
+// names maps the names of the plants of the organization to their IDs, the
+// caller must hold the lock.
+func (r *memoryPowerPlantRepository) names(organizationID string) map[string]string {
+	names := make(map[string]string)
+	for _, stored := range r.powerPlants {
+		if stored.organizationID == organizationID {
+			names[stored.powerPlant.Name] = stored.powerPlant.ID
+		}
+	}
+	return names
+}
+
+// sorted returns the plants of the organization ordered by ID, the caller
+// must hold the lock.
+func (r *memoryPowerPlantRepository) sorted(organizationID string) []*memoryPowerPlant {
+	var plants []*memoryPowerPlant
+	for _, stored := range r.powerPlants {
+		if stored.organizationID == organizationID {
+			plants = append(plants, stored)
+		}
+	}
+
+	sort.Slice(plants, func(i, j int) bool {
+		return idLess(plants[i].powerPlant.ID, plants[j].powerPlant.ID)
+	})
+
+	return plants
+}
+
+// points returns the locations of the plants.
+func points(powerPlants []*model.PowerPlant) []geo.Point {
+	points := make([]geo.Point, 0, len(powerPlants))
+	for _, powerPlant := range powerPlants {
+		points = append(points, geo.Point{Latitude: powerPlant.Latitude, Longitude: powerPlant.Longitude})
+	}
+	return points
+}
+
+// memoryNow returns the current time at the precision of the database
+// timestamps.
+func memoryNow() *time.Time {
+	now := time.Now().UTC().Truncate(time.Second)
+	return &now
+}
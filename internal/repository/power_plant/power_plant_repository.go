@@ -3,26 +3,102 @@ package powerPlantrepository
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"tensor-graphql/infrastructure/database"
+	"tensor-graphql/internal/auth"
 	"tensor-graphql/internal/model"
 	repository "tensor-graphql/internal/repository/common"
 	"tensor-graphql/pkg/derrors"
+	"tensor-graphql/pkg/geo"
 )
 
+const powerPlantColumns = `id, name, latitude, longitude, timezone, version, created_at, updated_at`
+
 type (
 	powerPlantRepository struct {
 		repository.Repository
 	}
 
+	// PowerPlantRepository stores power plants. Every method is scoped to the
+	// organization of the principal on the context, plants of other
+	// organizations are invisible to it.
 	PowerPlantRepository interface {
 		repository.Repository
 		CreatePowerPlant(ctx context.Context, tx *sql.Tx, powerPlant *model.PowerPlant) (err error)
+		// CreatePowerPlants inserts the plants with a single statement and sets
+		// their IDs and timestamps. Every plant whose name is taken is
+		// reported with an *ItemError, see ItemErrorsOf, nothing is inserted
+		// then.
+		CreatePowerPlants(ctx context.Context, tx *sql.Tx, powerPlants []*model.PowerPlant) (err error)
 		GetPowerPlantByID(ctx context.Context, id string) (powerPlant *model.PowerPlant, err error)
+		// GetPowerPlantsByIDs returns the plants with the given IDs in a single
+		// query, ordered by ID. Unknown IDs are left out.
+		GetPowerPlantsByIDs(ctx context.Context, ids []string) (powerPlants []*model.PowerPlant, err error)
+		GetPowerPlantByName(ctx context.Context, name string) (powerPlant *model.PowerPlant, err error)
 		GetPowerPlants(ctx context.Context, page, limit int) (powerPlants []*model.PowerPlant, total int, err error)
+		// StreamPowerPlants calls fn with every plant, ordered by ID, while the
+		// rows are read. It stops at the first error returned by fn.
+		StreamPowerPlants(ctx context.Context, fn func(powerPlant *model.PowerPlant) error) (err error)
+		// GetNearbyPowerPlants returns at most limit plants within radiusKm of
+		// the location, nearest first, with their DistanceKm set.
+		GetNearbyPowerPlants(ctx context.Context, latitude, longitude, radiusKm float64, limit int) (powerPlants []*model.PowerPlant, err error)
+		// GetPowerPlantsInBounds returns every plant inside bounds, ordered by ID.
+		GetPowerPlantsInBounds(ctx context.Context, bounds geo.Bounds) (powerPlants []*model.PowerPlant, err error)
+		// GetPowerPlantsInPolygon returns every plant inside polygon, ordered by ID.
+		GetPowerPlantsInPolygon(ctx context.Context, polygon geo.Polygon) (powerPlants []*model.PowerPlant, err error)
+		// ClusterPowerPlantsInBounds and ClusterPowerPlantsInPolygon count the
+		// plants inside the area per cell of a gridSize by gridSize grid over
+		// its bounds, see geo.ClusterGrid, without reading the plants.
+		ClusterPowerPlantsInBounds(ctx context.Context, bounds geo.Bounds, gridSize int) (clusters []geo.Cluster, err error)
+		ClusterPowerPlantsInPolygon(ctx context.Context, polygon geo.Polygon, gridSize int) (clusters []geo.Cluster, err error)
+		// UpdatePowerPlant updates the plant if its version still matches and
+		// increments the version, otherwise it returns a derrors.Conflict.
 		UpdatePowerPlant(ctx context.Context, tx *sql.Tx, powerPlant *model.PowerPlant) (err error)
+		// UpdatePowerPlants updates the plants like UpdatePowerPlant in one
+		// transaction, tx or else the one on ctx or else its own, and re-reads
+		// them. The first failing plant is reported with an *ItemError.
+		UpdatePowerPlants(ctx context.Context, tx *sql.Tx, powerPlants []*model.PowerPlant) (err error)
 		DeletePowerPlant(ctx context.Context, tx *sql.Tx, id string) (err error)
 	}
 )
 
+// ItemError reports the plant of a batch that failed, the batch is not written
+// when the caller rolls back its transaction.
+type ItemError struct {
+	// Index is the position of the plant in the batch.
+	Index int
+	Err   error
+}
+
+func (e *ItemError) Error() string {
+	return fmt.Sprintf("item %d: %v", e.Index, e.Err)
+}
+
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
+
+// ItemErrorsOf returns every *ItemError wrapped by err, in the order they were
+// joined.
+func ItemErrorsOf(err error) []*ItemError {
+	switch err := err.(type) {
+	case *ItemError:
+		return []*ItemError{err}
+	case interface{ Unwrap() []error }:
+		var itemErrs []*ItemError
+		for _, joined := range err.Unwrap() {
+			itemErrs = append(itemErrs, ItemErrorsOf(joined)...)
+		}
+		return itemErrs
+	case interface{ Unwrap() error }:
+		return ItemErrorsOf(err.Unwrap())
+	}
+	return nil
+}
+
 func NewPowerPlantRepository(store repository.Repository) PowerPlantRepository {
 	return &powerPlantRepository{
 		Repository: store,
@@ -32,28 +108,208 @@ func NewPowerPlantRepository(store repository.Repository) PowerPlantRepository {
 func (r *powerPlantRepository) CreatePowerPlant(ctx context.Context, tx *sql.Tx, powerPlant *model.PowerPlant) (err error) {
 	defer derrors.Wrap(&err, "CreatePowerPlant(%q)", powerPlant.ID)
 
-	query := `INSERT INTO power_plant (name, latitude, longitude) VALUES (?, ?, ?)`
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO power_plant (organization_id, name, latitude, longitude, timezone) VALUES (?, ?, ?, ?, ?)`
 	args := []interface{}{
+		organizationID,
 		powerPlant.Name,
 		powerPlant.Latitude,
 		powerPlant.Longitude,
+		powerPlant.Timezone,
 	}
 
-	_, err = r.Exec(ctx, tx, query, args)
+	id, err := r.Insert(ctx, tx, query, args)
 	if err != nil {
-		return derrors.WrapStack(err, derrors.Unknown, "r.Exec")
+		if database.IsDuplicateKey(err) {
+			return duplicateNameError(powerPlant.Name)
+		}
+		return derrors.WrapStack(err, derrors.Unknown, "r.Insert")
+	}
+	powerPlant.ID = strconv.FormatInt(id, 10)
+
+	// Re-read the row so the database generated timestamps are returned as well.
+	query = `SELECT ` + powerPlantColumns + ` FROM power_plant WHERE id = ?`
+	err = r.QueryRowPrimary(ctx, tx, query, powerPlant.ID).Scan(r.getDest(powerPlant)...)
+	if err != nil {
+		return derrors.HandleSQLError(err, "r.QueryRowPrimary")
 	}
 
 	return
 }
 
+func (r *powerPlantRepository) CreatePowerPlants(ctx context.Context, tx *sql.Tx, powerPlants []*model.PowerPlant) (err error) {
+	defer derrors.Wrap(&err, "CreatePowerPlants(%d)", len(powerPlants))
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return err
+	}
+	if len(powerPlants) == 0 {
+		return nil
+	}
+
+	values := make([]string, 0, len(powerPlants))
+	args := make([]interface{}, 0, len(powerPlants)*5)
+	for _, powerPlant := range powerPlants {
+		values = append(values, "(?, ?, ?, ?, ?)")
+		args = append(args,
+			organizationID,
+			powerPlant.Name,
+			powerPlant.Latitude,
+			powerPlant.Longitude,
+			powerPlant.Timezone,
+		)
+	}
+	query := `INSERT INTO power_plant (organization_id, name, latitude, longitude, timezone) VALUES ` + strings.Join(values, ", ")
+
+	// In a transaction the failed insert is rolled back to a savepoint, which
+	// keeps the transaction usable to find the plant whose name is taken.
+	inTx := tx != nil || repository.TxFromContext(ctx) != nil
+	if inTx {
+		_, err = r.Exec(ctx, tx, `SAVEPOINT create_power_plants`, nil)
+		if err != nil {
+			return derrors.WrapStack(err, derrors.Unknown, "r.Exec")
+		}
+	}
+
+	ids, err := r.InsertMany(ctx, tx, query, args, len(powerPlants))
+	if err != nil {
+		if !database.IsDuplicateKey(err) {
+			return derrors.WrapStack(err, derrors.Unknown, "r.InsertMany")
+		}
+		if inTx {
+			_, err = r.Exec(ctx, tx, `ROLLBACK TO SAVEPOINT create_power_plants`, nil)
+			if err != nil {
+				return derrors.WrapStack(err, derrors.Unknown, "r.Exec")
+			}
+		}
+		return r.duplicateItemError(ctx, tx, organizationID, powerPlants)
+	}
+
+	// Re-read the rows for the database generated timestamps, which also
+	// checks that the IDs belong to the rows that were inserted.
+	query = `SELECT ` + powerPlantColumns + ` FROM power_plant WHERE organization_id = ? AND id IN (?` + strings.Repeat(", ?", len(ids)-1) + `) ORDER BY id`
+	args = []interface{}{organizationID}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	rows, err := r.QueryPrimary(ctx, tx, query, args...)
+	if err != nil {
+		return derrors.HandleSQLError(err, "r.QueryPrimary")
+	}
+	defer rows.Close()
+
+	inserted := make([]*model.PowerPlant, 0, len(powerPlants))
+	for rows.Next() {
+		powerPlant := &model.PowerPlant{}
+		err = rows.Scan(r.getDest(powerPlant)...)
+		if err != nil {
+			return derrors.WrapStack(err, derrors.Unknown, "rows.Scan")
+		}
+		inserted = append(inserted, powerPlant)
+	}
+	if err = rows.Err(); err != nil {
+		return derrors.WrapStack(err, derrors.Unknown, "rows.Err")
+	}
+
+	if len(inserted) != len(powerPlants) {
+		return derrors.New(derrors.Unknown, "read back %d of %d inserted power plants", len(inserted), len(powerPlants))
+	}
+	for i, powerPlant := range powerPlants {
+		if inserted[i].Name != powerPlant.Name {
+			return derrors.New(derrors.Unknown, "inserted power plant %s is not %q", inserted[i].ID, powerPlant.Name)
+		}
+		*powerPlant = *inserted[i]
+	}
+
+	return nil
+}
+
 func (r *powerPlantRepository) GetPowerPlantByID(ctx context.Context, id string) (powerPlant *model.PowerPlant, err error) {
 	defer derrors.Wrap(&err, "GetPowerPlantByID(%q)", id)
 
-	query := `SELECT id, name, latitude, longitude FROM power_plant WHERE id = ?`
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + powerPlantColumns + ` FROM power_plant WHERE id = ? AND organization_id = ?`
 	powerPlant = &model.PowerPlant{}
 	args := []any{
 		id,
+		organizationID,
+	}
+
+	err = r.Query(ctx, query, r.getDest(powerPlant), args)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, derrors.HandleSQLError(err, "r.Query")
+	}
+
+	return powerPlant, nil
+}
+
+func (r *powerPlantRepository) GetPowerPlantsByIDs(ctx context.Context, ids []string) (powerPlants []*model.PowerPlant, err error) {
+	defer derrors.Wrap(&err, "GetPowerPlantsByIDs(%d)", len(ids))
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	powerPlants = make([]*model.PowerPlant, 0, len(ids))
+	if len(ids) == 0 {
+		return powerPlants, nil
+	}
+
+	query := `SELECT ` + powerPlantColumns + ` FROM power_plant WHERE organization_id = ? AND id IN (?` + strings.Repeat(", ?", len(ids)-1) + `) ORDER BY id`
+	args := []interface{}{organizationID}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	rows, err := r.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, derrors.HandleSQLError(err, "QueryContext")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		powerPlant := &model.PowerPlant{}
+		err = rows.Scan(r.getDest(powerPlant)...)
+		if err != nil {
+			return nil, derrors.WrapStack(err, derrors.Unknown, "rows.Scan")
+		}
+
+		powerPlants = append(powerPlants, powerPlant)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, derrors.WrapStack(err, derrors.Unknown, "rows.Err")
+	}
+
+	return powerPlants, nil
+}
+
+func (r *powerPlantRepository) GetPowerPlantByName(ctx context.Context, name string) (powerPlant *model.PowerPlant, err error) {
+	defer derrors.Wrap(&err, "GetPowerPlantByName(%q)", name)
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + powerPlantColumns + ` FROM power_plant WHERE organization_id = ? AND name = ? LIMIT 1`
+	powerPlant = &model.PowerPlant{}
+	args := []any{
+		organizationID,
+		name,
 	}
 
 	err = r.Query(ctx, query, r.getDest(powerPlant), args)
@@ -70,28 +326,95 @@ func (r *powerPlantRepository) GetPowerPlantByID(ctx context.Context, id string)
 func (r *powerPlantRepository) UpdatePowerPlant(ctx context.Context, tx *sql.Tx, powerPlant *model.PowerPlant) (err error) {
 	defer derrors.Wrap(&err, "UpdatePowerPlant(%q)", powerPlant.ID)
 
-	query := `UPDATE power_plant SET name = ?, latitude = ?, longitude = ? WHERE id = ?`
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE power_plant SET name = ?, latitude = ?, longitude = ?, timezone = ?, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND organization_id = ? AND version = ?`
 	args := []interface{}{
 		powerPlant.Name,
 		powerPlant.Latitude,
 		powerPlant.Longitude,
+		powerPlant.Timezone,
 		powerPlant.ID,
+		organizationID,
+		powerPlant.Version,
 	}
 
-	_, err = r.Exec(ctx, tx, query, args)
+	result, err := r.Exec(ctx, tx, query, args)
 	if err != nil {
+		if database.IsDuplicateKey(err) {
+			return duplicateNameError(powerPlant.Name)
+		}
 		return derrors.WrapStack(err, derrors.Unknown, "r.Exec")
 	}
 
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return derrors.WrapStack(err, derrors.Unknown, "result.RowsAffected")
+	}
+	if affected == 0 {
+		return derrors.New(derrors.Conflict, "power plant was changed or deleted since version %d", powerPlant.Version)
+	}
+	powerPlant.Version++
+
+	return nil
+}
+
+func (r *powerPlantRepository) UpdatePowerPlants(ctx context.Context, tx *sql.Tx, powerPlants []*model.PowerPlant) (err error) {
+	defer derrors.Wrap(&err, "UpdatePowerPlants(%d)", len(powerPlants))
+
+	_, err = auth.RequireOrganization(ctx)
+	if err != nil {
+		return err
+	}
+
+	if tx == nil && repository.TxFromContext(ctx) == nil {
+		tx, err = r.Begin()
+		if err != nil {
+			return derrors.WrapStack(err, derrors.Unknown, "r.Begin")
+		}
+		defer func() {
+			if err != nil {
+				_ = r.Rollback(tx)
+				return
+			}
+			if err = r.Commit(tx); err != nil {
+				err = derrors.WrapStack(err, derrors.Unknown, "r.Commit")
+			}
+		}()
+	}
+
+	for i, powerPlant := range powerPlants {
+		err = r.UpdatePowerPlant(ctx, tx, powerPlant)
+		if err != nil {
+			return &ItemError{Index: i, Err: err}
+		}
+
+		// Re-read the row for the timestamp maintained by the database.
+		query := `SELECT ` + powerPlantColumns + ` FROM power_plant WHERE id = ?`
+		err = r.QueryRowPrimary(ctx, tx, query, powerPlant.ID).Scan(r.getDest(powerPlant)...)
+		if err != nil {
+			return &ItemError{Index: i, Err: derrors.HandleSQLError(err, "r.QueryRowPrimary")}
+		}
+	}
+
 	return nil
 }
 
 func (r *powerPlantRepository) DeletePowerPlant(ctx context.Context, tx *sql.Tx, id string) (err error) {
 	defer derrors.Wrap(&err, "DeletePowerPlant(%q)", id)
 
-	query := `DELETE FROM power_plant WHERE id = ?`
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `DELETE FROM power_plant WHERE id = ? AND organization_id = ?`
 	args := []interface{}{
 		id,
+		organizationID,
 	}
 
 	_, err = r.Exec(ctx, tx, query, args)
@@ -102,30 +425,131 @@ func (r *powerPlantRepository) DeletePowerPlant(ctx context.Context, tx *sql.Tx,
 	return nil
 }
 
+// duplicateItemError joins an *ItemError for every plant of powerPlants whose
+// name the unique key rejected. The names are read with a locking read, which
+// sees the plants committed by concurrent transactions.
+func (r *powerPlantRepository) duplicateItemError(ctx context.Context, tx *sql.Tx, organizationID string, powerPlants []*model.PowerPlant) error {
+	query := `SELECT name FROM power_plant WHERE organization_id = ? AND name IN (?` + strings.Repeat(", ?", len(powerPlants)-1) + `)` + r.Dialect().ForUpdate()
+	args := []interface{}{organizationID}
+	for _, powerPlant := range powerPlants {
+		args = append(args, powerPlant.Name)
+	}
+
+	rows, err := r.QueryPrimary(ctx, tx, query, args...)
+	if err != nil {
+		return derrors.HandleSQLError(err, "r.QueryPrimary")
+	}
+	defer rows.Close()
+
+	// A name used twice in powerPlants is taken by its first plant.
+	taken := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		err = rows.Scan(&name)
+		if err != nil {
+			return derrors.WrapStack(err, derrors.Unknown, "rows.Scan")
+		}
+		taken[name] = true
+	}
+	if err = rows.Err(); err != nil {
+		return derrors.WrapStack(err, derrors.Unknown, "rows.Err")
+	}
+
+	var itemErrs []error
+	for i, powerPlant := range powerPlants {
+		if taken[powerPlant.Name] {
+			itemErrs = append(itemErrs, &ItemError{Index: i, Err: duplicateNameError(powerPlant.Name)})
+		}
+		taken[powerPlant.Name] = true
+	}
+	if len(itemErrs) > 0 {
+		return errors.Join(itemErrs...)
+	}
+
+	// The plant using the name was removed again since.
+	return derrors.NewWithFields(derrors.Duplicate, []derrors.FieldError{
+		{Field: "name", Message: "a power plant with this name already exists"},
+	}, "duplicate power plant name in batch of %d", len(powerPlants))
+}
+
+// duplicateNameError is returned when the unique key on the organization and
+// name rejects a plant.
+func duplicateNameError(name string) error {
+	return derrors.NewWithFields(derrors.Duplicate, []derrors.FieldError{
+		{Field: "name", Message: "a power plant with this name already exists"},
+	}, "duplicate power plant name %q", name)
+}
+
 func (r *powerPlantRepository) getDest(powerPlant *model.PowerPlant) []interface{} {
 	return []interface{}{
 		&powerPlant.ID,
 		&powerPlant.Name,
 		&powerPlant.Latitude,
 		&powerPlant.Longitude,
+		&powerPlant.Timezone,
+		&powerPlant.Version,
+		&powerPlant.CreatedAt,
+		&powerPlant.UpdatedAt,
+	}
+}
+
+func (r *powerPlantRepository) StreamPowerPlants(ctx context.Context, fn func(powerPlant *model.PowerPlant) error) (err error) {
+	defer derrors.Wrap(&err, "StreamPowerPlants")
+
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `SELECT ` + powerPlantColumns + ` FROM power_plant WHERE organization_id = ? ORDER BY id`
+	rows, err := r.QueryContext(ctx, query, organizationID)
+	if err != nil {
+		return derrors.HandleSQLError(err, "QueryContext")
 	}
+	defer rows.Close()
+
+	for rows.Next() {
+		powerPlant := &model.PowerPlant{}
+		err = rows.Scan(r.getDest(powerPlant)...)
+		if err != nil {
+			return derrors.WrapStack(err, derrors.Unknown, "rows.Scan")
+		}
+
+		err = fn(powerPlant)
+		if err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return derrors.WrapStack(err, derrors.Unknown, "rows.Err")
+	}
+
+	return nil
 }
 
 func (r *powerPlantRepository) GetPowerPlants(ctx context.Context, page, limit int) (powerPlants []*model.PowerPlant, total int, err error) {
 	defer derrors.Wrap(&err, "GetPowerPlants")
-	args := []interface{}{}
 
-	query := `SELECT id , name, latitude, longitude FROM power_plant LIMIT ?,?`
+	organizationID, err := auth.RequireOrganization(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
 
-	args = append(args, r.GetOffset(page, limit), limit)
+	query := `SELECT ` + powerPlantColumns + ` FROM power_plant WHERE organization_id = ? ORDER BY id LIMIT ? OFFSET ?`
+	args := []interface{}{
+		organizationID,
+		limit,
+		r.GetOffset(page, limit),
+	}
 
 	powerPlants = make([]*model.PowerPlant, 0)
 
-	rows, err := r.Slave().QueryContext(ctx, query, args...)
+	rows, err := r.QueryContext(ctx, query, args...)
 	if err != nil {
 		err = derrors.HandleSQLError(err, "QueryContext")
 		return
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		wc := &model.PowerPlant{}
@@ -137,21 +561,14 @@ func (r *powerPlantRepository) GetPowerPlants(ctx context.Context, page, limit i
 		powerPlants = append(powerPlants, wc)
 	}
 
-	totalCountQuery := `SELECT COUNT(*) FROM power_plant`
+	totalCountQuery := `SELECT COUNT(*) FROM power_plant WHERE organization_id = ?`
 	// Get total count
-	totalCountRow, err := r.Slave().QueryContext(ctx, totalCountQuery, []interface{}{}...)
+	var totalCount int
+	err = r.QueryRowContext(ctx, totalCountQuery, organizationID).Scan(&totalCount)
 	if err != nil {
 		err = derrors.HandleSQLError(err, "QueryRowContext(%s)", totalCountQuery)
 		return
 	}
 
-	var totalCount int
-	for totalCountRow.Next() {
-		err = totalCountRow.Scan(&totalCount)
-		if err != nil {
-			return
-		}
-	}
-
 	return powerPlants, totalCount, nil
 }
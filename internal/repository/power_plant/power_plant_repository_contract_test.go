@@ -0,0 +1,529 @@
+package powerPlantrepository_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"tensor-graphql/infrastructure/config"
+	"tensor-graphql/infrastructure/database"
+	"tensor-graphql/internal/constant"
+	"tensor-graphql/internal/model"
+	repository "tensor-graphql/internal/repository/common"
+	powerPlantrepository "tensor-graphql/internal/repository/power_plant"
+	"tensor-graphql/pkg/derrors"
+	"tensor-graphql/pkg/geo"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// contractMySQLDSNEnv holds the DSN of a MySQL database to run the contract
+// against, e.g. "user:pass@tcp(localhost:3306)/tensor_test?parseTime=true".
+// The database is migrated and every case creates its own organizations.
+const contractMySQLDSNEnv = "TEST_MYSQL_DSN"
+
+// newContractRepository returns an empty repository and two organizations
+// without plants.
+type newContractRepository func(t *testing.T) (repo powerPlantrepository.PowerPlantRepository, organizationA, organizationB string)
+
+// TestPowerPlantRepositoryContract runs the same behaviour checks against every
+// PowerPlantRepository implementation so they cannot drift apart.
+func TestPowerPlantRepositoryContract(t *testing.T) {
+	t.Run("Memory", func(t *testing.T) {
+		testPowerPlantRepositoryContract(t, func(t *testing.T) (powerPlantrepository.PowerPlantRepository, string, string) {
+			return powerPlantrepository.NewMemoryPowerPlantRepository(), "1", "2"
+		})
+	})
+
+	t.Run("SQLite", func(t *testing.T) {
+		testPowerPlantRepositoryContract(t, func(t *testing.T) (powerPlantrepository.PowerPlantRepository, string, string) {
+			path := filepath.Join(t.TempDir(), "tensor.db")
+			return newSQLContractRepository(t, &config.DB{
+				Driver:           "sqlite",
+				ConnectionString: fmt.Sprintf(constant.SQLiteDBStringConnection, path),
+			})
+		})
+	})
+
+	t.Run("MySQL", func(t *testing.T) {
+		dsn := os.Getenv(contractMySQLDSNEnv)
+		if dsn == "" {
+			t.Skipf("%s is not set", contractMySQLDSNEnv)
+		}
+
+		testPowerPlantRepositoryContract(t, func(t *testing.T) (powerPlantrepository.PowerPlantRepository, string, string) {
+			return newSQLContractRepository(t, &config.DB{
+				Driver:           "mysql",
+				ConnectionString: dsn,
+			})
+		})
+	})
+}
+
+// newSQLContractRepository migrates the database and creates two organizations.
+func newSQLContractRepository(t *testing.T, conf *config.DB) (powerPlantrepository.PowerPlantRepository, string, string) {
+	migrator, err := database.NewMigrator(conf)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Up())
+	require.NoError(t, migrator.Close())
+
+	db, err := database.InitializeDatabase(&config.Config{DBMaster: conf, DBSlave: conf})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.Master.Close()
+		db.Slave.Close()
+	})
+
+	var organizations [2]string
+	for i := range organizations {
+		name := fmt.Sprintf("contract-%s-%d-%d", t.Name(), time.Now().UnixNano(), i)
+		result, err := db.Master.Exec(`INSERT INTO organization (name) VALUES (?)`, name)
+		require.NoError(t, err)
+		id, err := result.LastInsertId()
+		require.NoError(t, err)
+		organizations[i] = strconv.FormatInt(id, 10)
+	}
+
+	return powerPlantrepository.NewPowerPlantRepository(repository.NewRepository(db)), organizations[0], organizations[1]
+}
+
+func testPowerPlantRepositoryContract(t *testing.T, newRepository newContractRepository) {
+	t.Run("CreateAndGet", func(t *testing.T) {
+		repo, organization, _ := newRepository(t)
+		ctx := tenantContext(organization)
+
+		plant := &model.PowerPlant{Name: "Plant A", Latitude: 1.5, Longitude: 2.5, Timezone: "UTC"}
+		require.NoError(t, repo.CreatePowerPlant(ctx, nil, plant))
+		assert.NotEmpty(t, plant.ID)
+		assert.Equal(t, 1, plant.Version)
+		assert.False(t, plant.CreatedAt.IsNil())
+		assert.False(t, plant.UpdatedAt.IsNil())
+
+		other := &model.PowerPlant{Name: "Plant B", Latitude: 3.5, Longitude: 4.5, Timezone: "UTC"}
+		require.NoError(t, repo.CreatePowerPlant(ctx, nil, other))
+		assert.NotEqual(t, plant.ID, other.ID)
+
+		stored, err := repo.GetPowerPlantByID(ctx, plant.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, plant, stored)
+
+		stored, err = repo.GetPowerPlantByName(ctx, "Plant B")
+		assert.NoError(t, err)
+		assert.Equal(t, other, stored)
+
+		plants, err := repo.GetPowerPlantsByIDs(ctx, []string{other.ID, "999999", plant.ID})
+		assert.NoError(t, err)
+		assert.Equal(t, []*model.PowerPlant{plant, other}, plants)
+	})
+
+	t.Run("DuplicateName", func(t *testing.T) {
+		repo, organization, otherOrganization := newRepository(t)
+		ctx := tenantContext(organization)
+
+		plant := &model.PowerPlant{Name: "Plant A", Timezone: "UTC"}
+		require.NoError(t, repo.CreatePowerPlant(ctx, nil, plant))
+		other := &model.PowerPlant{Name: "Plant B", Timezone: "UTC"}
+		require.NoError(t, repo.CreatePowerPlant(ctx, nil, other))
+
+		err := repo.CreatePowerPlant(ctx, nil, &model.PowerPlant{Name: "Plant A", Timezone: "UTC"})
+		assert.True(t, derrors.IsErrCode(err, derrors.Duplicate))
+		assert.Equal(t, []derrors.FieldError{{Field: "name", Message: "a power plant with this name already exists"}}, derrors.FieldsOf(err))
+
+		err = repo.CreatePowerPlants(ctx, nil, []*model.PowerPlant{
+			{Name: "Plant C", Timezone: "UTC"},
+			{Name: "Plant B", Timezone: "UTC"},
+			{Name: "Plant A", Timezone: "UTC"},
+		})
+		assert.True(t, derrors.IsErrCode(err, derrors.Duplicate))
+		var indexes []int
+		for _, itemErr := range powerPlantrepository.ItemErrorsOf(err) {
+			indexes = append(indexes, itemErr.Index)
+		}
+		assert.Equal(t, []int{1, 2}, indexes)
+		stored, err := repo.GetPowerPlantByName(ctx, "Plant C")
+		assert.NoError(t, err)
+		assert.Nil(t, stored)
+
+		renamed := *other
+		renamed.Name = "Plant A"
+		err = repo.UpdatePowerPlant(ctx, nil, &renamed)
+		assert.True(t, derrors.IsErrCode(err, derrors.Duplicate))
+
+		// Names are unique within an organization only.
+		assert.NoError(t, repo.CreatePowerPlant(tenantContext(otherOrganization), nil, &model.PowerPlant{Name: "Plant A", Timezone: "UTC"}))
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		repo, organization, _ := newRepository(t)
+		ctx := tenantContext(organization)
+
+		stored, err := repo.GetPowerPlantByID(ctx, "999999")
+		assert.NoError(t, err)
+		assert.Nil(t, stored)
+
+		stored, err = repo.GetPowerPlantByName(ctx, "Missing")
+		assert.NoError(t, err)
+		assert.Nil(t, stored)
+
+		plants, err := repo.GetPowerPlantsByIDs(ctx, nil)
+		assert.NoError(t, err)
+		assert.NotNil(t, plants)
+		assert.Empty(t, plants)
+
+		plants, total, err := repo.GetPowerPlants(ctx, 1, 10)
+		assert.NoError(t, err)
+		assert.NotNil(t, plants)
+		assert.Empty(t, plants)
+		assert.Zero(t, total)
+
+		err = repo.UpdatePowerPlant(ctx, nil, &model.PowerPlant{ID: "999999", Name: "Missing", Version: 1})
+		assert.True(t, derrors.IsErrCode(err, derrors.Conflict))
+
+		assert.NoError(t, repo.DeletePowerPlant(ctx, nil, "999999"))
+	})
+
+	t.Run("Paging", func(t *testing.T) {
+		repo, organization, _ := newRepository(t)
+		ctx := tenantContext(organization)
+
+		var ids []string
+		for i := 0; i < 3; i++ {
+			plant := &model.PowerPlant{Name: fmt.Sprintf("Plant %d", i), Timezone: "UTC"}
+			require.NoError(t, repo.CreatePowerPlant(ctx, nil, plant))
+			ids = append(ids, plant.ID)
+		}
+
+		var testCases = []struct {
+			caseName string
+			page     int
+			limit    int
+			ids      []string
+		}{
+			{caseName: "FirstPage", page: 1, limit: 2, ids: ids[:2]},
+			{caseName: "LastPage", page: 2, limit: 2, ids: ids[2:]},
+			{caseName: "PastTheEnd", page: 3, limit: 2, ids: []string{}},
+			{caseName: "AllInOnePage", page: 1, limit: 10, ids: ids},
+		}
+
+		for _, testCase := range testCases {
+			t.Run(testCase.caseName, func(t *testing.T) {
+				plants, total, err := repo.GetPowerPlants(ctx, testCase.page, testCase.limit)
+				assert.NoError(t, err)
+				assert.Equal(t, 3, total)
+
+				got := []string{}
+				for _, plant := range plants {
+					got = append(got, plant.ID)
+				}
+				assert.Equal(t, testCase.ids, got)
+			})
+		}
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		repo, organization, otherOrganization := newRepository(t)
+		ctx := tenantContext(organization)
+
+		var ids []string
+		for i := 0; i < 3; i++ {
+			plant := &model.PowerPlant{Name: fmt.Sprintf("Plant %d", i), Latitude: float64(i), Timezone: "UTC"}
+			require.NoError(t, repo.CreatePowerPlant(ctx, nil, plant))
+			ids = append(ids, plant.ID)
+		}
+		other := &model.PowerPlant{Name: "Other", Timezone: "UTC"}
+		require.NoError(t, repo.CreatePowerPlant(tenantContext(otherOrganization), nil, other))
+
+		got := []string{}
+		err := repo.StreamPowerPlants(ctx, func(plant *model.PowerPlant) error {
+			got = append(got, plant.ID)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, ids, got)
+
+		stop := errors.New("stop")
+		calls := 0
+		err = repo.StreamPowerPlants(ctx, func(plant *model.PowerPlant) error {
+			calls++
+			return stop
+		})
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repo, organization, _ := newRepository(t)
+		ctx := tenantContext(organization)
+
+		plant := &model.PowerPlant{Name: "Plant A", Latitude: 1.5, Longitude: 2.5, Timezone: "UTC"}
+		require.NoError(t, repo.CreatePowerPlant(ctx, nil, plant))
+
+		plant.Name = "Plant B"
+		plant.Latitude = 10.5
+		plant.Timezone = "Asia/Jakarta"
+		require.NoError(t, repo.UpdatePowerPlant(ctx, nil, plant))
+		assert.Equal(t, 2, plant.Version)
+
+		stored, err := repo.GetPowerPlantByID(ctx, plant.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Plant B", stored.Name)
+		assert.Equal(t, 10.5, stored.Latitude)
+		assert.Equal(t, "Asia/Jakarta", stored.Timezone)
+		assert.Equal(t, 2, stored.Version)
+
+		stale := *plant
+		stale.Version = 1
+		stale.Name = "Plant C"
+		err = repo.UpdatePowerPlant(ctx, nil, &stale)
+		assert.True(t, derrors.IsErrCode(err, derrors.Conflict))
+		assert.Equal(t, 1, stale.Version)
+
+		stored, err = repo.GetPowerPlantByID(ctx, plant.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Plant B", stored.Name)
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		repo, organization, _ := newRepository(t)
+		ctx := tenantContext(organization)
+
+		plants := []*model.PowerPlant{
+			{Name: "Plant A", Latitude: 1.5, Longitude: 2.5, Timezone: "UTC"},
+			{Name: "Plant B", Latitude: 3.5, Longitude: 4.5, Timezone: "Asia/Jakarta"},
+			{Name: "Plant C", Latitude: 5.5, Longitude: 6.5, Timezone: "UTC"},
+		}
+		require.NoError(t, repo.CreatePowerPlants(ctx, nil, plants))
+		for i, plant := range plants {
+			assert.NotEmpty(t, plant.ID)
+			assert.Equal(t, 1, plant.Version)
+			assert.False(t, plant.CreatedAt.IsNil())
+			if i > 0 {
+				previous, _ := strconv.Atoi(plants[i-1].ID)
+				current, _ := strconv.Atoi(plant.ID)
+				assert.Less(t, previous, current)
+			}
+
+			stored, err := repo.GetPowerPlantByID(ctx, plant.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, plant.Name, stored.Name)
+			assert.Equal(t, plant.Timezone, stored.Timezone)
+		}
+
+		plants[0].Name = "Plant A2"
+		plants[1].Latitude = 10.5
+		require.NoError(t, repo.UpdatePowerPlants(ctx, nil, plants[:2]))
+		assert.Equal(t, 2, plants[0].Version)
+		assert.Equal(t, 2, plants[1].Version)
+		assert.False(t, plants[0].UpdatedAt.IsNil())
+
+		// The stale second plant fails the batch, the first one stays as it was.
+		renamed := *plants[2]
+		renamed.Name = "Plant C2"
+		stale := *plants[0]
+		stale.Version = 1
+		err := repo.UpdatePowerPlants(ctx, nil, []*model.PowerPlant{&renamed, &stale})
+		var itemErr *powerPlantrepository.ItemError
+		if assert.ErrorAs(t, err, &itemErr) {
+			assert.Equal(t, 1, itemErr.Index)
+		}
+		assert.True(t, derrors.IsErrCode(err, derrors.Conflict))
+
+		stored, err := repo.GetPowerPlantByID(ctx, plants[2].ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Plant C", stored.Name)
+		assert.Equal(t, 1, stored.Version)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repo, organization, _ := newRepository(t)
+		ctx := tenantContext(organization)
+
+		plant := &model.PowerPlant{Name: "Plant A", Timezone: "UTC"}
+		require.NoError(t, repo.CreatePowerPlant(ctx, nil, plant))
+		require.NoError(t, repo.DeletePowerPlant(ctx, nil, plant.ID))
+
+		stored, err := repo.GetPowerPlantByID(ctx, plant.ID)
+		assert.NoError(t, err)
+		assert.Nil(t, stored)
+
+		_, total, err := repo.GetPowerPlants(ctx, 1, 10)
+		assert.NoError(t, err)
+		assert.Zero(t, total)
+
+		err = repo.UpdatePowerPlant(ctx, nil, plant)
+		assert.True(t, derrors.IsErrCode(err, derrors.Conflict))
+	})
+
+	t.Run("Nearby", func(t *testing.T) {
+		repo, organization, otherOrganization := newRepository(t)
+		ctx := tenantContext(organization)
+
+		// Distances from Jakarta (-6.2088, 106.8456).
+		for _, plant := range []*model.PowerPlant{
+			{Name: "Bandung", Latitude: -6.9175, Longitude: 107.6191},   // 116 km
+			{Name: "Bogor", Latitude: -6.595, Longitude: 106.8166},      // 43 km
+			{Name: "Surabaya", Latitude: -7.2575, Longitude: 112.7521},  // 663 km
+			{Name: "Tangerang", Latitude: -6.1783, Longitude: 106.6319}, // 24 km
+		} {
+			plant.Timezone = "Asia/Jakarta"
+			require.NoError(t, repo.CreatePowerPlant(ctx, nil, plant))
+		}
+		other := &model.PowerPlant{Name: "Depok", Latitude: -6.4025, Longitude: 106.7942, Timezone: "Asia/Jakarta"}
+		require.NoError(t, repo.CreatePowerPlant(tenantContext(otherOrganization), nil, other))
+
+		names := func(plants []*model.PowerPlant) []string {
+			found := []string{}
+			for _, plant := range plants {
+				found = append(found, plant.Name)
+			}
+			return found
+		}
+
+		plants, err := repo.GetNearbyPowerPlants(ctx, -6.2088, 106.8456, 150, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Tangerang", "Bogor", "Bandung"}, names(plants))
+		if assert.Len(t, plants, 3) {
+			assert.InDelta(t, 24.2, *plants[0].DistanceKm, 0.5)
+			assert.InDelta(t, 116.2, *plants[2].DistanceKm, 0.5)
+		}
+
+		plants, err = repo.GetNearbyPowerPlants(ctx, -6.2088, 106.8456, 150, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Tangerang", "Bogor"}, names(plants))
+
+		plants, err = repo.GetNearbyPowerPlants(ctx, -6.2088, 106.8456, 10, 10)
+		assert.NoError(t, err)
+		assert.Empty(t, plants)
+	})
+
+	t.Run("Region", func(t *testing.T) {
+		repo, organization, otherOrganization := newRepository(t)
+		ctx := tenantContext(organization)
+
+		for _, plant := range []*model.PowerPlant{
+			{Name: "Inside", Latitude: 2, Longitude: 102},
+			{Name: "InTheHole", Latitude: 5, Longitude: 105},
+			{Name: "OnTheEdge", Latitude: 10, Longitude: 110},
+			{Name: "Outside", Latitude: 20, Longitude: 120},
+			{Name: "Fiji", Latitude: -17.7, Longitude: 178.1},
+			{Name: "Samoa", Latitude: -13.8, Longitude: -171.8},
+		} {
+			plant.Timezone = "UTC"
+			require.NoError(t, repo.CreatePowerPlant(ctx, nil, plant))
+		}
+		other := &model.PowerPlant{Name: "OtherTenant", Latitude: 3, Longitude: 103, Timezone: "UTC"}
+		require.NoError(t, repo.CreatePowerPlant(tenantContext(otherOrganization), nil, other))
+
+		names := func(plants []*model.PowerPlant) []string {
+			found := []string{}
+			for _, plant := range plants {
+				found = append(found, plant.Name)
+			}
+			return found
+		}
+
+		plants, err := repo.GetPowerPlantsInBounds(ctx, geo.Bounds{South: 0, West: 100, North: 10, East: 110})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Inside", "InTheHole", "OnTheEdge"}, names(plants))
+
+		plants, err = repo.GetPowerPlantsInBounds(ctx, geo.Bounds{South: -20, West: 170, North: -10, East: -170})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Fiji", "Samoa"}, names(plants))
+
+		polygon, err := geo.ParsePolygon(`{"type": "Polygon", "coordinates": [
+			[[100, 0], [110, 0], [100, 10], [100, 0]],
+			[[104, 4], [106, 4], [104, 6], [104, 4]]
+		]}`)
+		require.NoError(t, err)
+		plants, err = repo.GetPowerPlantsInPolygon(ctx, polygon)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Inside"}, names(plants))
+
+		// The plant on the north east corner counts for the last cell.
+		clusters, err := repo.ClusterPowerPlantsInBounds(ctx, geo.Bounds{South: 0, West: 100, North: 10, East: 110}, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []geo.Cluster{
+			{Center: geo.Point{Latitude: 2, Longitude: 102}, Count: 1, Bounds: geo.Bounds{South: 0, West: 100, North: 5, East: 105}},
+			{Center: geo.Point{Latitude: 7.5, Longitude: 107.5}, Count: 2, Bounds: geo.Bounds{South: 5, West: 105, North: 10, East: 110}},
+		}, clusters)
+
+		clusters, err = repo.ClusterPowerPlantsInBounds(ctx, geo.Bounds{South: -20, West: 170, North: -10, East: -170}, 1)
+		assert.NoError(t, err)
+		if assert.Len(t, clusters, 1) {
+			assert.Equal(t, 2, clusters[0].Count)
+			assert.InDelta(t, -15.75, clusters[0].Center.Latitude, 1e-9)
+			assert.InDelta(t, -176.85, clusters[0].Center.Longitude, 1e-9)
+		}
+
+		clusters, err = repo.ClusterPowerPlantsInPolygon(ctx, polygon, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []geo.Cluster{
+			{Center: geo.Point{Latitude: 2, Longitude: 102}, Count: 1, Bounds: geo.Bounds{South: 0, West: 100, North: 10, East: 110}},
+		}, clusters)
+	})
+
+	t.Run("ConcurrentCreate", func(t *testing.T) {
+		repo, organization, _ := newRepository(t)
+		ctx := tenantContext(organization)
+
+		var wg sync.WaitGroup
+		ids := make([]string, 10)
+		for i := range ids {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				plant := &model.PowerPlant{Name: fmt.Sprintf("Plant %d", i), Timezone: "UTC"}
+				assert.NoError(t, repo.CreatePowerPlant(ctx, nil, plant))
+				ids[i] = plant.ID
+			}()
+		}
+		wg.Wait()
+
+		plants, total, err := repo.GetPowerPlants(ctx, 1, len(ids))
+		assert.NoError(t, err)
+		assert.Equal(t, len(ids), total)
+		assert.Len(t, plants, len(ids))
+		for _, plant := range plants {
+			assert.Contains(t, ids, plant.ID)
+		}
+	})
+
+	t.Run("TenantScope", func(t *testing.T) {
+		repo, organization, otherOrganization := newRepository(t)
+		ctx := tenantContext(organization)
+		otherCtx := tenantContext(otherOrganization)
+
+		plant := &model.PowerPlant{Name: "Plant A", Timezone: "UTC"}
+		require.NoError(t, repo.CreatePowerPlant(ctx, nil, plant))
+
+		stored, err := repo.GetPowerPlantByID(otherCtx, plant.ID)
+		assert.NoError(t, err)
+		assert.Nil(t, stored)
+
+		stored, err = repo.GetPowerPlantByName(otherCtx, plant.Name)
+		assert.NoError(t, err)
+		assert.Nil(t, stored)
+
+		plants, err := repo.GetPowerPlantsByIDs(otherCtx, []string{plant.ID})
+		assert.NoError(t, err)
+		assert.Empty(t, plants)
+
+		plants, total, err := repo.GetPowerPlants(otherCtx, 1, 10)
+		assert.NoError(t, err)
+		assert.Empty(t, plants)
+		assert.Zero(t, total)
+
+		err = repo.UpdatePowerPlant(otherCtx, nil, &model.PowerPlant{ID: plant.ID, Name: "Taken", Version: plant.Version})
+		assert.True(t, derrors.IsErrCode(err, derrors.Conflict))
+
+		require.NoError(t, repo.DeletePowerPlant(otherCtx, nil, plant.ID))
+		stored, err = repo.GetPowerPlantByID(ctx, plant.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Plant A", stored.Name)
+	})
+}
@@ -0,0 +1,376 @@
+package powerPlantrepository_test
+
+import (
+	"context"
+	"regexp"
+	"tensor-graphql/infrastructure/database"
+	"tensor-graphql/internal/auth"
+	"tensor-graphql/internal/model"
+	repository "tensor-graphql/internal/repository/common"
+	powerPlantrepository "tensor-graphql/internal/repository/power_plant"
+	"tensor-graphql/pkg/derrors"
+	"tensor-graphql/pkg/geo"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+var powerPlantColumns = []string{"id", "name", "latitude", "longitude", "timezone", "version", "created_at", "updated_at"}
+
+func initRepository(t *testing.T) (powerPlantrepository.PowerPlantRepository, sqlmock.Sqlmock) {
+	db, dbMock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		assert.NoError(t, dbMock.ExpectationsWereMet())
+		db.Close()
+	})
+
+	store := repository.NewRepository(&database.DB{Master: db, Slave: db})
+	return powerPlantrepository.NewPowerPlantRepository(store), dbMock
+}
+
+func tenantContext(organizationID string) context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{
+		Subject:        "user-1",
+		OrganizationID: organizationID,
+		Roles:          []auth.Role{auth.RoleAdmin},
+	})
+}
+
+func TestPowerPlantRepositoryTenantScope(t *testing.T) {
+	now := time.Now()
+	ctx := tenantContext("7")
+
+	t.Run("CreatePowerPlant_StoresTenant", func(t *testing.T) {
+		repo, dbMock := initRepository(t)
+		dbMock.ExpectExec(regexp.QuoteMeta(`INSERT INTO power_plant (organization_id, name, latitude, longitude, timezone)`)).
+			WithArgs("7", "Plant A", 1.5, 2.5, "UTC").
+			WillReturnResult(sqlmock.NewResult(10, 1))
+		dbMock.ExpectQuery(`FROM power_plant WHERE id = \?`).
+			WithArgs("10").
+			WillReturnRows(sqlmock.NewRows(powerPlantColumns).AddRow(10, "Plant A", 1.5, 2.5, "UTC", 1, now, now))
+
+		plant := &model.PowerPlant{Name: "Plant A", Latitude: 1.5, Longitude: 2.5, Timezone: "UTC"}
+		err := repo.CreatePowerPlant(ctx, nil, plant)
+		assert.NoError(t, err)
+		assert.Equal(t, "10", plant.ID)
+	})
+
+	t.Run("CreatePowerPlants_MultiRowInsert", func(t *testing.T) {
+		repo, dbMock := initRepository(t)
+		dbMock.ExpectExec(regexp.QuoteMeta(`INSERT INTO power_plant (organization_id, name, latitude, longitude, timezone) VALUES (?, ?, ?, ?, ?), (?, ?, ?, ?, ?)`)).
+			WithArgs("7", "Plant A", 1.5, 2.5, "UTC", "7", "Plant B", 3.5, 4.5, "UTC").
+			WillReturnResult(sqlmock.NewResult(10, 2))
+		dbMock.ExpectQuery(`FROM power_plant WHERE organization_id = \? AND id IN \(\?, \?\) ORDER BY id`).
+			WithArgs("7", int64(10), int64(11)).
+			WillReturnRows(sqlmock.NewRows(powerPlantColumns).
+				AddRow(10, "Plant A", 1.5, 2.5, "UTC", 1, now, now).
+				AddRow(11, "Plant B", 3.5, 4.5, "UTC", 1, now, now))
+
+		plants := []*model.PowerPlant{
+			{Name: "Plant A", Latitude: 1.5, Longitude: 2.5, Timezone: "UTC"},
+			{Name: "Plant B", Latitude: 3.5, Longitude: 4.5, Timezone: "UTC"},
+		}
+		err := repo.CreatePowerPlants(ctx, nil, plants)
+		assert.NoError(t, err)
+		assert.Equal(t, "10", plants[0].ID)
+		assert.Equal(t, "11", plants[1].ID)
+	})
+
+	t.Run("CreatePowerPlants_IDsOfOtherRows", func(t *testing.T) {
+		repo, dbMock := initRepository(t)
+		dbMock.ExpectExec(`INSERT INTO power_plant`).
+			WillReturnResult(sqlmock.NewResult(10, 2))
+		dbMock.ExpectQuery(`FROM power_plant WHERE organization_id = \? AND id IN`).
+			WillReturnRows(sqlmock.NewRows(powerPlantColumns).
+				AddRow(10, "Plant A", 1.5, 2.5, "UTC", 1, now, now).
+				AddRow(11, "Plant X", 3.5, 4.5, "UTC", 1, now, now))
+
+		plants := []*model.PowerPlant{{Name: "Plant A"}, {Name: "Plant B"}}
+		err := repo.CreatePowerPlants(ctx, nil, plants)
+		assert.True(t, derrors.IsErrCode(err, derrors.Unknown))
+	})
+
+	t.Run("CreatePowerPlants_DuplicateInTransaction", func(t *testing.T) {
+		db, dbMock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		repo := powerPlantrepository.NewPowerPlantRepository(repository.NewRepository(&database.DB{Master: db, Slave: db}))
+
+		dbMock.ExpectBegin()
+		dbMock.ExpectExec(`^SAVEPOINT create_power_plants$`).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		dbMock.ExpectExec(`INSERT INTO power_plant`).
+			WillReturnError(&mysql.MySQLError{Number: 1062})
+		dbMock.ExpectExec(`^ROLLBACK TO SAVEPOINT create_power_plants$`).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		dbMock.ExpectQuery(`SELECT name FROM power_plant WHERE organization_id = \? AND name IN \(\?, \?\) FOR UPDATE`).
+			WithArgs("7", "Plant A", "Plant B").
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Plant B"))
+		dbMock.ExpectRollback()
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+		err = repo.CreatePowerPlants(ctx, tx, []*model.PowerPlant{{Name: "Plant A"}, {Name: "Plant B"}})
+		assert.NoError(t, tx.Rollback())
+
+		assert.True(t, derrors.IsErrCode(err, derrors.Duplicate))
+		var itemErr *powerPlantrepository.ItemError
+		if assert.ErrorAs(t, err, &itemErr) {
+			assert.Equal(t, 1, itemErr.Index)
+		}
+		assert.NoError(t, dbMock.ExpectationsWereMet())
+	})
+
+	t.Run("UpdatePowerPlants_OwnTransaction", func(t *testing.T) {
+		repo, dbMock := initRepository(t)
+		dbMock.ExpectBegin()
+		dbMock.ExpectExec(`UPDATE power_plant SET .* WHERE id = \? AND organization_id = \? AND version = \?`).
+			WithArgs("Plant A", 1.5, 2.5, "UTC", "10", "7", 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		dbMock.ExpectQuery(`FROM power_plant WHERE id = \?`).
+			WithArgs("10").
+			WillReturnRows(sqlmock.NewRows(powerPlantColumns).AddRow(10, "Plant A", 1.5, 2.5, "UTC", 2, now, now))
+		dbMock.ExpectExec(`UPDATE power_plant SET .* AND version = \?`).
+			WithArgs("Plant B", 3.5, 4.5, "UTC", "11", "7", 1).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		dbMock.ExpectRollback()
+
+		plants := []*model.PowerPlant{
+			{ID: "10", Name: "Plant A", Latitude: 1.5, Longitude: 2.5, Timezone: "UTC", Version: 1},
+			{ID: "11", Name: "Plant B", Latitude: 3.5, Longitude: 4.5, Timezone: "UTC", Version: 1},
+		}
+		err := repo.UpdatePowerPlants(ctx, nil, plants)
+		var itemErr *powerPlantrepository.ItemError
+		if assert.ErrorAs(t, err, &itemErr) {
+			assert.Equal(t, 1, itemErr.Index)
+		}
+		assert.True(t, derrors.IsErrCode(err, derrors.Conflict))
+	})
+
+	t.Run("GetPowerPlantByID_OtherTenant", func(t *testing.T) {
+		repo, dbMock := initRepository(t)
+		dbMock.ExpectQuery(`FROM power_plant WHERE id = \? AND organization_id = \?`).
+			WithArgs("10", "7").
+			WillReturnRows(sqlmock.NewRows(powerPlantColumns))
+
+		plant, err := repo.GetPowerPlantByID(ctx, "10")
+		assert.NoError(t, err)
+		assert.Nil(t, plant)
+	})
+
+	t.Run("GetPowerPlantByName_ScopedToTenant", func(t *testing.T) {
+		repo, dbMock := initRepository(t)
+		dbMock.ExpectQuery(`FROM power_plant WHERE organization_id = \? AND name = \?`).
+			WithArgs("7", "Plant A").
+			WillReturnRows(sqlmock.NewRows(powerPlantColumns).AddRow(10, "Plant A", 1.5, 2.5, "UTC", 1, now, now))
+
+		plant, err := repo.GetPowerPlantByName(ctx, "Plant A")
+		assert.NoError(t, err)
+		assert.Equal(t, "10", plant.ID)
+	})
+
+	t.Run("GetPowerPlants_ScopedToTenant", func(t *testing.T) {
+		repo, dbMock := initRepository(t)
+		dbMock.ExpectQuery(`FROM power_plant WHERE organization_id = \? ORDER BY id LIMIT \? OFFSET \?`).
+			WithArgs("7", 10, 0).
+			WillReturnRows(sqlmock.NewRows(powerPlantColumns).AddRow(10, "Plant A", 1.5, 2.5, "UTC", 1, now, now))
+		dbMock.ExpectQuery(`SELECT COUNT\(\*\) FROM power_plant WHERE organization_id = \?`).
+			WithArgs("7").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+		plants, total, err := repo.GetPowerPlants(ctx, 1, 10)
+		assert.NoError(t, err)
+		assert.Len(t, plants, 1)
+		assert.Equal(t, 1, total)
+	})
+
+	t.Run("StreamPowerPlants_ScopedToTenant", func(t *testing.T) {
+		repo, dbMock := initRepository(t)
+		dbMock.ExpectQuery(`FROM power_plant WHERE organization_id = \? ORDER BY id$`).
+			WithArgs("7").
+			WillReturnRows(sqlmock.NewRows(powerPlantColumns).
+				AddRow(10, "Plant A", 1.5, 2.5, "UTC", 1, now, now).
+				AddRow(11, "Plant B", 3.5, 4.5, "UTC", 1, now, now))
+
+		var ids []string
+		err := repo.StreamPowerPlants(ctx, func(plant *model.PowerPlant) error {
+			ids = append(ids, plant.ID)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"10", "11"}, ids)
+	})
+
+	t.Run("GetNearbyPowerPlants_SpatialQuery", func(t *testing.T) {
+		repo, dbMock := initRepository(t)
+		dbMock.ExpectQuery(`ST_Distance_Sphere\(location, .*\) / 1000 AS distance_km FROM power_plant WHERE organization_id = \? AND MBRContains\(.*, location\) HAVING distance_km <= \? ORDER BY distance_km, id LIMIT \?`).
+			WithArgs("POINT(-6.2 106.8)", "7", sqlmock.AnyArg(), 50.0, 5).
+			WillReturnRows(sqlmock.NewRows(append(powerPlantColumns, "distance_km")).AddRow(10, "Plant A", -6.3, 106.8, "UTC", 1, now, now, 11.1))
+
+		plants, err := repo.GetNearbyPowerPlants(ctx, -6.2, 106.8, 50, 5)
+		assert.NoError(t, err)
+		if assert.Len(t, plants, 1) {
+			assert.Equal(t, 11.1, *plants[0].DistanceKm)
+		}
+	})
+
+	t.Run("GetNearbyPowerPlants_PostGIS", func(t *testing.T) {
+		db, dbMock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+
+		dbMock.ExpectQuery(`ST_Distance\(location, ST_SetSRID\(ST_MakePoint\(\$1, \$2\), 4326\)::geography, false\) / 1000 AS distance_km FROM power_plant WHERE organization_id = \$3 AND ST_DWithin\(location, ST_SetSRID\(ST_MakePoint\(\$4, \$5\), 4326\)::geography, \$6, false\) ORDER BY distance_km, id LIMIT \$7`).
+			WithArgs(106.8, -6.2, "7", 106.8, -6.2, 50000.0, 5).
+			WillReturnRows(sqlmock.NewRows(append(powerPlantColumns, "distance_km")).AddRow(10, "Plant A", -6.3, 106.8, "UTC", 1, now, now, 11.1))
+
+		repo := powerPlantrepository.NewPowerPlantRepository(repository.NewRepository(&database.DB{Master: db, Slave: db, Dialect: database.Postgres}))
+		plants, err := repo.GetNearbyPowerPlants(ctx, -6.2, 106.8, 50, 5)
+		assert.NoError(t, err)
+		if assert.Len(t, plants, 1) {
+			assert.Equal(t, 11.1, *plants[0].DistanceKm)
+		}
+		assert.NoError(t, dbMock.ExpectationsWereMet())
+	})
+
+	t.Run("GetPowerPlantsInBounds_SpatialIndex", func(t *testing.T) {
+		repo, dbMock := initRepository(t)
+		dbMock.ExpectQuery(`FROM power_plant WHERE organization_id = \? AND MBRContains\(.*, location\) AND latitude BETWEEN \? AND \? AND longitude BETWEEN \? AND \? ORDER BY id`).
+			WithArgs("7", "POLYGON((0 100, 10 100, 10 110, 0 110, 0 100))", 0.0, 10.0, 100.0, 110.0).
+			WillReturnRows(sqlmock.NewRows(powerPlantColumns).AddRow(10, "Plant A", 2, 102, "UTC", 1, now, now))
+
+		plants, err := repo.GetPowerPlantsInBounds(ctx, geo.Bounds{South: 0, West: 100, North: 10, East: 110})
+		assert.NoError(t, err)
+		assert.Len(t, plants, 1)
+	})
+
+	t.Run("GetPowerPlantsInBounds_AcrossTheAntimeridian", func(t *testing.T) {
+		repo, dbMock := initRepository(t)
+		dbMock.ExpectQuery(`FROM power_plant WHERE organization_id = \? AND latitude BETWEEN \? AND \? AND \(longitude >= \? OR longitude <= \?\) ORDER BY id`).
+			WithArgs("7", -20.0, -10.0, 170.0, -170.0).
+			WillReturnRows(sqlmock.NewRows(powerPlantColumns))
+
+		plants, err := repo.GetPowerPlantsInBounds(ctx, geo.Bounds{South: -20, West: 170, North: -10, East: -170})
+		assert.NoError(t, err)
+		assert.Empty(t, plants)
+	})
+
+	t.Run("ClusterPowerPlantsInBounds_GroupedByCell", func(t *testing.T) {
+		repo, dbMock := initRepository(t)
+		dbMock.ExpectQuery(`SELECT FLOOR\(\(latitude - \?\) / \?\) AS cell_row, .* COUNT\(\*\), SUM\(latitude\), .* FROM power_plant WHERE organization_id = \? AND MBRContains\(.*, location\) AND latitude BETWEEN \? AND \? AND longitude BETWEEN \? AND \? GROUP BY cell_row, cell_column`).
+			WithArgs(0.0, 5.0, 100.0, 100.0, 100.0, 5.0, 100.0, 100.0, 100.0, "7", "POLYGON((0 100, 10 100, 10 110, 0 110, 0 100))", 0.0, 10.0, 100.0, 110.0).
+			WillReturnRows(sqlmock.NewRows([]string{"cell_row", "cell_column", "count", "latitude", "offset"}).
+				AddRow(0.0, 0.0, 2, 3.0, 5.0).
+				AddRow(2.0, 2.0, 1, 10.0, 10.0))
+
+		clusters, err := repo.ClusterPowerPlantsInBounds(ctx, geo.Bounds{South: 0, West: 100, North: 10, East: 110}, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []geo.Cluster{
+			{Center: geo.Point{Latitude: 1.5, Longitude: 102.5}, Count: 2, Bounds: geo.Bounds{South: 0, West: 100, North: 5, East: 105}},
+			{Center: geo.Point{Latitude: 10, Longitude: 110}, Count: 1, Bounds: geo.Bounds{South: 5, West: 105, North: 10, East: 110}},
+		}, clusters)
+	})
+
+	t.Run("UpdatePowerPlant_ScopedToTenant", func(t *testing.T) {
+		repo, dbMock := initRepository(t)
+		dbMock.ExpectExec(`UPDATE power_plant SET .* WHERE id = \? AND organization_id = \? AND version = \?`).
+			WithArgs("Plant B", 1.5, 2.5, "UTC", "10", "7", 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		plant := &model.PowerPlant{ID: "10", Name: "Plant B", Latitude: 1.5, Longitude: 2.5, Timezone: "UTC", Version: 1}
+		err := repo.UpdatePowerPlant(ctx, nil, plant)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, plant.Version)
+	})
+
+	t.Run("UpdatePowerPlant_StaleVersion", func(t *testing.T) {
+		repo, dbMock := initRepository(t)
+		dbMock.ExpectExec(`UPDATE power_plant SET .* AND version = \?`).
+			WithArgs("Plant B", 1.5, 2.5, "UTC", "10", "7", 1).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		plant := &model.PowerPlant{ID: "10", Name: "Plant B", Latitude: 1.5, Longitude: 2.5, Timezone: "UTC", Version: 1}
+		err := repo.UpdatePowerPlant(ctx, nil, plant)
+		assert.True(t, derrors.IsErrCode(err, derrors.Conflict))
+		assert.Equal(t, 1, plant.Version)
+	})
+
+	t.Run("DeletePowerPlant_ScopedToTenant", func(t *testing.T) {
+		repo, dbMock := initRepository(t)
+		dbMock.ExpectExec(`DELETE FROM power_plant WHERE id = \? AND organization_id = \?`).
+			WithArgs("10", "7").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.DeletePowerPlant(ctx, nil, "10")
+		assert.NoError(t, err)
+	})
+}
+
+func TestPowerPlantRepositoryWithoutTenant(t *testing.T) {
+	repo, _ := initRepository(t)
+	plant := &model.PowerPlant{ID: "10", Name: "Plant A"}
+
+	var testCases = []struct {
+		caseName string
+		ctx      context.Context
+		code     derrors.ErrorCode
+	}{
+		{
+			caseName: "Anonymous",
+			ctx:      context.Background(),
+			code:     derrors.Unauthorized,
+		},
+		{
+			caseName: "NoOrganization",
+			ctx:      tenantContext(""),
+			code:     derrors.Forbidden,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			// No query is expected, the mock fails the test if one is sent.
+			err := repo.CreatePowerPlant(testCase.ctx, nil, plant)
+			assert.True(t, derrors.IsErrCode(err, testCase.code))
+
+			err = repo.CreatePowerPlants(testCase.ctx, nil, []*model.PowerPlant{plant})
+			assert.True(t, derrors.IsErrCode(err, testCase.code))
+
+			err = repo.UpdatePowerPlants(testCase.ctx, nil, []*model.PowerPlant{plant})
+			assert.True(t, derrors.IsErrCode(err, testCase.code))
+
+			_, err = repo.GetPowerPlantByID(testCase.ctx, plant.ID)
+			assert.True(t, derrors.IsErrCode(err, testCase.code))
+
+			_, err = repo.GetPowerPlantByName(testCase.ctx, plant.Name)
+			assert.True(t, derrors.IsErrCode(err, testCase.code))
+
+			_, _, err = repo.GetPowerPlants(testCase.ctx, 1, 10)
+			assert.True(t, derrors.IsErrCode(err, testCase.code))
+
+			err = repo.StreamPowerPlants(testCase.ctx, func(*model.PowerPlant) error { return nil })
+			assert.True(t, derrors.IsErrCode(err, testCase.code))
+
+			_, err = repo.GetNearbyPowerPlants(testCase.ctx, 0, 0, 10, 10)
+			assert.True(t, derrors.IsErrCode(err, testCase.code))
+
+			_, err = repo.GetPowerPlantsInBounds(testCase.ctx, geo.Bounds{South: 0, West: 0, North: 1, East: 1})
+			assert.True(t, derrors.IsErrCode(err, testCase.code))
+
+			err = repo.UpdatePowerPlant(testCase.ctx, nil, plant)
+			assert.True(t, derrors.IsErrCode(err, testCase.code))
+
+			err = repo.DeletePowerPlant(testCase.ctx, nil, plant.ID)
+			assert.True(t, derrors.IsErrCode(err, testCase.code))
+		})
+	}
+}
@@ -10,13 +10,33 @@ import (
 type MockComponent struct {
 	Config               *config.Config
 	PowerPlantRepository *mockrepository.PowerPlantRepository
+	APIKeyRepository     *mockrepository.APIKeyRepository
+	AuditEventRepository *mockrepository.AuditEventRepository
+	TransactionManager   *mockrepository.TransactionManager
+	PortfolioRepository  *mockrepository.PortfolioRepository
 	PowerPlantUsecase    *mockusecase.PowerPlantUsecase
+	APIKeyUsecase        *mockusecase.APIKeyUsecase
+	TimezoneDetector     *mockusecase.TimezoneDetector
+	ForecastUsecase      *mockusecase.ForecastUsecase
+	Forecaster           *mockusecase.Forecaster
+	PortfolioUsecase     *mockusecase.PortfolioUsecase
 }
 
 func InitMockComponent(t *testing.T) *MockComponent {
 	return &MockComponent{
-		Config:               &config.Config{},
+		Config: &config.Config{
+			Map: &config.Map{MaxPlants: 2, ClusterGridSize: 2},
+		},
 		PowerPlantRepository: mockrepository.NewPowerPlantRepository(t),
+		APIKeyRepository:     mockrepository.NewAPIKeyRepository(t),
+		AuditEventRepository: mockrepository.NewAuditEventRepository(t),
+		TransactionManager:   mockrepository.NewTransactionManager(t),
+		PortfolioRepository:  mockrepository.NewPortfolioRepository(t),
 		PowerPlantUsecase:    mockusecase.NewPowerPlantUsecase(t),
+		APIKeyUsecase:        mockusecase.NewAPIKeyUsecase(t),
+		TimezoneDetector:     mockusecase.NewTimezoneDetector(t),
+		ForecastUsecase:      mockusecase.NewForecastUsecase(t),
+		Forecaster:           mockusecase.NewForecaster(t),
+		PortfolioUsecase:     mockusecase.NewPortfolioUsecase(t),
 	}
 }
@@ -0,0 +1,515 @@
+// Code generated by mockery v2.46.0. DO NOT EDIT.
+
+package mockrepository
+
+import (
+	context "context"
+	database "tensor-graphql/infrastructure/database"
+
+	mock "github.com/stretchr/testify/mock"
+
+	model "tensor-graphql/internal/model"
+
+	sql "database/sql"
+)
+
+// AuditEventRepository is an autogenerated mock type for the AuditEventRepository type
+type AuditEventRepository struct {
+	mock.Mock
+}
+
+// AddSortQuery provides a mock function with given fields: query, allowedFields, sortBy
+func (_m *AuditEventRepository) AddSortQuery(query string, allowedFields []string, sortBy string) (string, error) {
+	ret := _m.Called(query, allowedFields, sortBy)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddSortQuery")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, []string, string) (string, error)); ok {
+		return rf(query, allowedFields, sortBy)
+	}
+	if rf, ok := ret.Get(0).(func(string, []string, string) string); ok {
+		r0 = rf(query, allowedFields, sortBy)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, []string, string) error); ok {
+		r1 = rf(query, allowedFields, sortBy)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AddSortQueryWithPrefix provides a mock function with given fields: query, allowedFields, sortBy
+func (_m *AuditEventRepository) AddSortQueryWithPrefix(query string, allowedFields map[string]string, sortBy string) (string, error) {
+	ret := _m.Called(query, allowedFields, sortBy)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddSortQueryWithPrefix")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, map[string]string, string) (string, error)); ok {
+		return rf(query, allowedFields, sortBy)
+	}
+	if rf, ok := ret.Get(0).(func(string, map[string]string, string) string); ok {
+		r0 = rf(query, allowedFields, sortBy)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, map[string]string, string) error); ok {
+		r1 = rf(query, allowedFields, sortBy)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Begin provides a mock function with given fields:
+func (_m *AuditEventRepository) Begin() (*sql.Tx, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Begin")
+	}
+
+	var r0 *sql.Tx
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (*sql.Tx, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() *sql.Tx); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sql.Tx)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Commit provides a mock function with given fields: tx
+func (_m *AuditEventRepository) Commit(tx *sql.Tx) error {
+	ret := _m.Called(tx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Commit")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*sql.Tx) error); ok {
+		r0 = rf(tx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateAuditEvent provides a mock function with given fields: ctx, tx, event
+func (_m *AuditEventRepository) CreateAuditEvent(ctx context.Context, tx *sql.Tx, event *model.AuditEvent) error {
+	ret := _m.Called(ctx, tx, event)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAuditEvent")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, *model.AuditEvent) error); ok {
+		r0 = rf(ctx, tx, event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Dialect provides a mock function with given fields:
+func (_m *AuditEventRepository) Dialect() database.Dialect {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Dialect")
+	}
+
+	var r0 database.Dialect
+	if rf, ok := ret.Get(0).(func() database.Dialect); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(database.Dialect)
+	}
+
+	return r0
+}
+
+// Exec provides a mock function with given fields: ctx, tx, query, args
+func (_m *AuditEventRepository) Exec(ctx context.Context, tx *sql.Tx, query string, args []interface{}) (sql.Result, error) {
+	ret := _m.Called(ctx, tx, query, args)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Exec")
+	}
+
+	var r0 sql.Result
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, string, []interface{}) (sql.Result, error)); ok {
+		return rf(ctx, tx, query, args)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, string, []interface{}) sql.Result); ok {
+		r0 = rf(ctx, tx, query, args)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(sql.Result)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *sql.Tx, string, []interface{}) error); ok {
+		r1 = rf(ctx, tx, query, args)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAuditEvents provides a mock function with given fields: ctx, entityType, entityID
+func (_m *AuditEventRepository) GetAuditEvents(ctx context.Context, entityType string, entityID string) ([]*model.AuditEvent, error) {
+	ret := _m.Called(ctx, entityType, entityID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAuditEvents")
+	}
+
+	var r0 []*model.AuditEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]*model.AuditEvent, error)); ok {
+		return rf(ctx, entityType, entityID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []*model.AuditEvent); ok {
+		r0 = rf(ctx, entityType, entityID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.AuditEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, entityType, entityID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOffset provides a mock function with given fields: page, limit
+func (_m *AuditEventRepository) GetOffset(page int, limit int) int {
+	ret := _m.Called(page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOffset")
+	}
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(int, int) int); ok {
+		r0 = rf(page, limit)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	return r0
+}
+
+// Insert provides a mock function with given fields: ctx, tx, query, args
+func (_m *AuditEventRepository) Insert(ctx context.Context, tx *sql.Tx, query string, args []interface{}) (int64, error) {
+	ret := _m.Called(ctx, tx, query, args)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Insert")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, string, []interface{}) (int64, error)); ok {
+		return rf(ctx, tx, query, args)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, string, []interface{}) int64); ok {
+		r0 = rf(ctx, tx, query, args)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *sql.Tx, string, []interface{}) error); ok {
+		r1 = rf(ctx, tx, query, args)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InsertMany provides a mock function with given fields: ctx, tx, query, args, count
+func (_m *AuditEventRepository) InsertMany(ctx context.Context, tx *sql.Tx, query string, args []interface{}, count int) ([]int64, error) {
+	ret := _m.Called(ctx, tx, query, args, count)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertMany")
+	}
+
+	var r0 []int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, string, []interface{}, int) ([]int64, error)); ok {
+		return rf(ctx, tx, query, args, count)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, string, []interface{}, int) []int64); ok {
+		r0 = rf(ctx, tx, query, args, count)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int64)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *sql.Tx, string, []interface{}, int) error); ok {
+		r1 = rf(ctx, tx, query, args, count)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Master provides a mock function with given fields:
+func (_m *AuditEventRepository) Master() *sql.DB {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Master")
+	}
+
+	var r0 *sql.DB
+	if rf, ok := ret.Get(0).(func() *sql.DB); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sql.DB)
+		}
+	}
+
+	return r0
+}
+
+// NewNullString provides a mock function with given fields: str
+func (_m *AuditEventRepository) NewNullString(str *string) sql.NullString {
+	ret := _m.Called(str)
+
+	if len(ret) == 0 {
+		panic("no return value specified for NewNullString")
+	}
+
+	var r0 sql.NullString
+	if rf, ok := ret.Get(0).(func(*string) sql.NullString); ok {
+		r0 = rf(str)
+	} else {
+		r0 = ret.Get(0).(sql.NullString)
+	}
+
+	return r0
+}
+
+// Query provides a mock function with given fields: ctx, query, dest, args
+func (_m *AuditEventRepository) Query(ctx context.Context, query string, dest []interface{}, args []interface{}) error {
+	ret := _m.Called(ctx, query, dest, args)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Query")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []interface{}, []interface{}) error); ok {
+		r0 = rf(ctx, query, dest, args)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// QueryContext provides a mock function with given fields: ctx, query, args
+func (_m *AuditEventRepository) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	var _ca []interface{}
+	_ca = append(_ca, ctx, query)
+	_ca = append(_ca, args...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for QueryContext")
+	}
+
+	var r0 *sql.Rows
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...interface{}) (*sql.Rows, error)); ok {
+		return rf(ctx, query, args...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...interface{}) *sql.Rows); ok {
+		r0 = rf(ctx, query, args...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sql.Rows)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ...interface{}) error); ok {
+		r1 = rf(ctx, query, args...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// QueryPrimary provides a mock function with given fields: ctx, tx, query, args
+func (_m *AuditEventRepository) QueryPrimary(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (*sql.Rows, error) {
+	var _ca []interface{}
+	_ca = append(_ca, ctx, tx, query)
+	_ca = append(_ca, args...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for QueryPrimary")
+	}
+
+	var r0 *sql.Rows
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, string, ...interface{}) (*sql.Rows, error)); ok {
+		return rf(ctx, tx, query, args...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, string, ...interface{}) *sql.Rows); ok {
+		r0 = rf(ctx, tx, query, args...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sql.Rows)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *sql.Tx, string, ...interface{}) error); ok {
+		r1 = rf(ctx, tx, query, args...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// QueryRowContext provides a mock function with given fields: ctx, query, args
+func (_m *AuditEventRepository) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	var _ca []interface{}
+	_ca = append(_ca, ctx, query)
+	_ca = append(_ca, args...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for QueryRowContext")
+	}
+
+	var r0 *sql.Row
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...interface{}) *sql.Row); ok {
+		r0 = rf(ctx, query, args...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sql.Row)
+		}
+	}
+
+	return r0
+}
+
+// QueryRowPrimary provides a mock function with given fields: ctx, tx, query, args
+func (_m *AuditEventRepository) QueryRowPrimary(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) *sql.Row {
+	var _ca []interface{}
+	_ca = append(_ca, ctx, tx, query)
+	_ca = append(_ca, args...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for QueryRowPrimary")
+	}
+
+	var r0 *sql.Row
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, string, ...interface{}) *sql.Row); ok {
+		r0 = rf(ctx, tx, query, args...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sql.Row)
+		}
+	}
+
+	return r0
+}
+
+// Rollback provides a mock function with given fields: tx
+func (_m *AuditEventRepository) Rollback(tx *sql.Tx) error {
+	ret := _m.Called(tx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Rollback")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*sql.Tx) error); ok {
+		r0 = rf(tx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Slave provides a mock function with given fields:
+func (_m *AuditEventRepository) Slave() *sql.DB {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Slave")
+	}
+
+	var r0 *sql.DB
+	if rf, ok := ret.Get(0).(func() *sql.DB); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sql.DB)
+		}
+	}
+
+	return r0
+}
+
+// NewAuditEventRepository creates a new instance of AuditEventRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAuditEventRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AuditEventRepository {
+	mock := &AuditEventRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
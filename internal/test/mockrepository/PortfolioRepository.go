@@ -0,0 +1,629 @@
+// Code generated by mockery v2.46.0. DO NOT EDIT.
+
+package mockrepository
+
+import (
+	context "context"
+	database "tensor-graphql/infrastructure/database"
+
+	mock "github.com/stretchr/testify/mock"
+
+	model "tensor-graphql/internal/model"
+
+	sql "database/sql"
+)
+
+// PortfolioRepository is an autogenerated mock type for the PortfolioRepository type
+type PortfolioRepository struct {
+	mock.Mock
+}
+
+// AddSortQuery provides a mock function with given fields: query, allowedFields, sortBy
+func (_m *PortfolioRepository) AddSortQuery(query string, allowedFields []string, sortBy string) (string, error) {
+	ret := _m.Called(query, allowedFields, sortBy)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddSortQuery")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, []string, string) (string, error)); ok {
+		return rf(query, allowedFields, sortBy)
+	}
+	if rf, ok := ret.Get(0).(func(string, []string, string) string); ok {
+		r0 = rf(query, allowedFields, sortBy)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, []string, string) error); ok {
+		r1 = rf(query, allowedFields, sortBy)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AddSortQueryWithPrefix provides a mock function with given fields: query, allowedFields, sortBy
+func (_m *PortfolioRepository) AddSortQueryWithPrefix(query string, allowedFields map[string]string, sortBy string) (string, error) {
+	ret := _m.Called(query, allowedFields, sortBy)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddSortQueryWithPrefix")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, map[string]string, string) (string, error)); ok {
+		return rf(query, allowedFields, sortBy)
+	}
+	if rf, ok := ret.Get(0).(func(string, map[string]string, string) string); ok {
+		r0 = rf(query, allowedFields, sortBy)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, map[string]string, string) error); ok {
+		r1 = rf(query, allowedFields, sortBy)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Begin provides a mock function with given fields:
+func (_m *PortfolioRepository) Begin() (*sql.Tx, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Begin")
+	}
+
+	var r0 *sql.Tx
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (*sql.Tx, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() *sql.Tx); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sql.Tx)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Commit provides a mock function with given fields: tx
+func (_m *PortfolioRepository) Commit(tx *sql.Tx) error {
+	ret := _m.Called(tx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Commit")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*sql.Tx) error); ok {
+		r0 = rf(tx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreatePortfolio provides a mock function with given fields: ctx, tx, portfolio
+func (_m *PortfolioRepository) CreatePortfolio(ctx context.Context, tx *sql.Tx, portfolio *model.Portfolio) error {
+	ret := _m.Called(ctx, tx, portfolio)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreatePortfolio")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, *model.Portfolio) error); ok {
+		r0 = rf(ctx, tx, portfolio)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeletePortfolio provides a mock function with given fields: ctx, tx, id
+func (_m *PortfolioRepository) DeletePortfolio(ctx context.Context, tx *sql.Tx, id string) error {
+	ret := _m.Called(ctx, tx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeletePortfolio")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, string) error); ok {
+		r0 = rf(ctx, tx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Dialect provides a mock function with given fields:
+func (_m *PortfolioRepository) Dialect() database.Dialect {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Dialect")
+	}
+
+	var r0 database.Dialect
+	if rf, ok := ret.Get(0).(func() database.Dialect); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(database.Dialect)
+	}
+
+	return r0
+}
+
+// Exec provides a mock function with given fields: ctx, tx, query, args
+func (_m *PortfolioRepository) Exec(ctx context.Context, tx *sql.Tx, query string, args []interface{}) (sql.Result, error) {
+	ret := _m.Called(ctx, tx, query, args)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Exec")
+	}
+
+	var r0 sql.Result
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, string, []interface{}) (sql.Result, error)); ok {
+		return rf(ctx, tx, query, args)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, string, []interface{}) sql.Result); ok {
+		r0 = rf(ctx, tx, query, args)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(sql.Result)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *sql.Tx, string, []interface{}) error); ok {
+		r1 = rf(ctx, tx, query, args)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOffset provides a mock function with given fields: page, limit
+func (_m *PortfolioRepository) GetOffset(page int, limit int) int {
+	ret := _m.Called(page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOffset")
+	}
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(int, int) int); ok {
+		r0 = rf(page, limit)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	return r0
+}
+
+// GetPortfolioByID provides a mock function with given fields: ctx, id
+func (_m *PortfolioRepository) GetPortfolioByID(ctx context.Context, id string) (*model.Portfolio, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPortfolioByID")
+	}
+
+	var r0 *model.Portfolio
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.Portfolio, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.Portfolio); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Portfolio)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPortfolioByName provides a mock function with given fields: ctx, name
+func (_m *PortfolioRepository) GetPortfolioByName(ctx context.Context, name string) (*model.Portfolio, error) {
+	ret := _m.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPortfolioByName")
+	}
+
+	var r0 *model.Portfolio
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.Portfolio, error)); ok {
+		return rf(ctx, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.Portfolio); ok {
+		r0 = rf(ctx, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Portfolio)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPortfolios provides a mock function with given fields: ctx
+func (_m *PortfolioRepository) GetPortfolios(ctx context.Context) ([]*model.Portfolio, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPortfolios")
+	}
+
+	var r0 []*model.Portfolio
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*model.Portfolio, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*model.Portfolio); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Portfolio)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Insert provides a mock function with given fields: ctx, tx, query, args
+func (_m *PortfolioRepository) Insert(ctx context.Context, tx *sql.Tx, query string, args []interface{}) (int64, error) {
+	ret := _m.Called(ctx, tx, query, args)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Insert")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, string, []interface{}) (int64, error)); ok {
+		return rf(ctx, tx, query, args)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, string, []interface{}) int64); ok {
+		r0 = rf(ctx, tx, query, args)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *sql.Tx, string, []interface{}) error); ok {
+		r1 = rf(ctx, tx, query, args)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InsertMany provides a mock function with given fields: ctx, tx, query, args, count
+func (_m *PortfolioRepository) InsertMany(ctx context.Context, tx *sql.Tx, query string, args []interface{}, count int) ([]int64, error) {
+	ret := _m.Called(ctx, tx, query, args, count)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertMany")
+	}
+
+	var r0 []int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, string, []interface{}, int) ([]int64, error)); ok {
+		return rf(ctx, tx, query, args, count)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, string, []interface{}, int) []int64); ok {
+		r0 = rf(ctx, tx, query, args, count)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int64)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *sql.Tx, string, []interface{}, int) error); ok {
+		r1 = rf(ctx, tx, query, args, count)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Master provides a mock function with given fields:
+func (_m *PortfolioRepository) Master() *sql.DB {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Master")
+	}
+
+	var r0 *sql.DB
+	if rf, ok := ret.Get(0).(func() *sql.DB); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sql.DB)
+		}
+	}
+
+	return r0
+}
+
+// NewNullString provides a mock function with given fields: str
+func (_m *PortfolioRepository) NewNullString(str *string) sql.NullString {
+	ret := _m.Called(str)
+
+	if len(ret) == 0 {
+		panic("no return value specified for NewNullString")
+	}
+
+	var r0 sql.NullString
+	if rf, ok := ret.Get(0).(func(*string) sql.NullString); ok {
+		r0 = rf(str)
+	} else {
+		r0 = ret.Get(0).(sql.NullString)
+	}
+
+	return r0
+}
+
+// Query provides a mock function with given fields: ctx, query, dest, args
+func (_m *PortfolioRepository) Query(ctx context.Context, query string, dest []interface{}, args []interface{}) error {
+	ret := _m.Called(ctx, query, dest, args)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Query")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []interface{}, []interface{}) error); ok {
+		r0 = rf(ctx, query, dest, args)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// QueryContext provides a mock function with given fields: ctx, query, args
+func (_m *PortfolioRepository) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	var _ca []interface{}
+	_ca = append(_ca, ctx, query)
+	_ca = append(_ca, args...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for QueryContext")
+	}
+
+	var r0 *sql.Rows
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...interface{}) (*sql.Rows, error)); ok {
+		return rf(ctx, query, args...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...interface{}) *sql.Rows); ok {
+		r0 = rf(ctx, query, args...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sql.Rows)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ...interface{}) error); ok {
+		r1 = rf(ctx, query, args...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// QueryPrimary provides a mock function with given fields: ctx, tx, query, args
+func (_m *PortfolioRepository) QueryPrimary(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (*sql.Rows, error) {
+	var _ca []interface{}
+	_ca = append(_ca, ctx, tx, query)
+	_ca = append(_ca, args...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for QueryPrimary")
+	}
+
+	var r0 *sql.Rows
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, string, ...interface{}) (*sql.Rows, error)); ok {
+		return rf(ctx, tx, query, args...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, string, ...interface{}) *sql.Rows); ok {
+		r0 = rf(ctx, tx, query, args...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sql.Rows)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *sql.Tx, string, ...interface{}) error); ok {
+		r1 = rf(ctx, tx, query, args...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// QueryRowContext provides a mock function with given fields: ctx, query, args
+func (_m *PortfolioRepository) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	var _ca []interface{}
+	_ca = append(_ca, ctx, query)
+	_ca = append(_ca, args...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for QueryRowContext")
+	}
+
+	var r0 *sql.Row
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...interface{}) *sql.Row); ok {
+		r0 = rf(ctx, query, args...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sql.Row)
+		}
+	}
+
+	return r0
+}
+
+// QueryRowPrimary provides a mock function with given fields: ctx, tx, query, args
+func (_m *PortfolioRepository) QueryRowPrimary(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) *sql.Row {
+	var _ca []interface{}
+	_ca = append(_ca, ctx, tx, query)
+	_ca = append(_ca, args...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for QueryRowPrimary")
+	}
+
+	var r0 *sql.Row
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, string, ...interface{}) *sql.Row); ok {
+		r0 = rf(ctx, tx, query, args...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sql.Row)
+		}
+	}
+
+	return r0
+}
+
+// Rollback provides a mock function with given fields: tx
+func (_m *PortfolioRepository) Rollback(tx *sql.Tx) error {
+	ret := _m.Called(tx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Rollback")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*sql.Tx) error); ok {
+		r0 = rf(tx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetPortfolioMembers provides a mock function with given fields: ctx, tx, portfolio
+func (_m *PortfolioRepository) SetPortfolioMembers(ctx context.Context, tx *sql.Tx, portfolio *model.Portfolio) error {
+	ret := _m.Called(ctx, tx, portfolio)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetPortfolioMembers")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, *model.Portfolio) error); ok {
+		r0 = rf(ctx, tx, portfolio)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Slave provides a mock function with given fields:
+func (_m *PortfolioRepository) Slave() *sql.DB {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Slave")
+	}
+
+	var r0 *sql.DB
+	if rf, ok := ret.Get(0).(func() *sql.DB); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sql.DB)
+		}
+	}
+
+	return r0
+}
+
+// UpdatePortfolio provides a mock function with given fields: ctx, tx, portfolio
+func (_m *PortfolioRepository) UpdatePortfolio(ctx context.Context, tx *sql.Tx, portfolio *model.Portfolio) error {
+	ret := _m.Called(ctx, tx, portfolio)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePortfolio")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, *model.Portfolio) error); ok {
+		r0 = rf(ctx, tx, portfolio)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewPortfolioRepository creates a new instance of PortfolioRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPortfolioRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PortfolioRepository {
+	mock := &PortfolioRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -4,10 +4,13 @@ package mockrepository
 
 import (
 	context "context"
-	model "tensor-graphql/internal/model"
+	database "tensor-graphql/infrastructure/database"
+	geo "tensor-graphql/pkg/geo"
 
 	mock "github.com/stretchr/testify/mock"
 
+	model "tensor-graphql/internal/model"
+
 	sql "database/sql"
 )
 
@@ -102,6 +105,66 @@ func (_m *PowerPlantRepository) Begin() (*sql.Tx, error) {
 	return r0, r1
 }
 
+// ClusterPowerPlantsInBounds provides a mock function with given fields: ctx, bounds, gridSize
+func (_m *PowerPlantRepository) ClusterPowerPlantsInBounds(ctx context.Context, bounds geo.Bounds, gridSize int) ([]geo.Cluster, error) {
+	ret := _m.Called(ctx, bounds, gridSize)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClusterPowerPlantsInBounds")
+	}
+
+	var r0 []geo.Cluster
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, geo.Bounds, int) ([]geo.Cluster, error)); ok {
+		return rf(ctx, bounds, gridSize)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, geo.Bounds, int) []geo.Cluster); ok {
+		r0 = rf(ctx, bounds, gridSize)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]geo.Cluster)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, geo.Bounds, int) error); ok {
+		r1 = rf(ctx, bounds, gridSize)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ClusterPowerPlantsInPolygon provides a mock function with given fields: ctx, polygon, gridSize
+func (_m *PowerPlantRepository) ClusterPowerPlantsInPolygon(ctx context.Context, polygon geo.Polygon, gridSize int) ([]geo.Cluster, error) {
+	ret := _m.Called(ctx, polygon, gridSize)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClusterPowerPlantsInPolygon")
+	}
+
+	var r0 []geo.Cluster
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, geo.Polygon, int) ([]geo.Cluster, error)); ok {
+		return rf(ctx, polygon, gridSize)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, geo.Polygon, int) []geo.Cluster); ok {
+		r0 = rf(ctx, polygon, gridSize)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]geo.Cluster)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, geo.Polygon, int) error); ok {
+		r1 = rf(ctx, polygon, gridSize)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Commit provides a mock function with given fields: tx
 func (_m *PowerPlantRepository) Commit(tx *sql.Tx) error {
 	ret := _m.Called(tx)
@@ -138,6 +201,24 @@ func (_m *PowerPlantRepository) CreatePowerPlant(ctx context.Context, tx *sql.Tx
 	return r0
 }
 
+// CreatePowerPlants provides a mock function with given fields: ctx, tx, powerPlants
+func (_m *PowerPlantRepository) CreatePowerPlants(ctx context.Context, tx *sql.Tx, powerPlants []*model.PowerPlant) error {
+	ret := _m.Called(ctx, tx, powerPlants)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreatePowerPlants")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, []*model.PowerPlant) error); ok {
+		r0 = rf(ctx, tx, powerPlants)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeletePowerPlant provides a mock function with given fields: ctx, tx, id
 func (_m *PowerPlantRepository) DeletePowerPlant(ctx context.Context, tx *sql.Tx, id string) error {
 	ret := _m.Called(ctx, tx, id)
@@ -156,6 +237,24 @@ func (_m *PowerPlantRepository) DeletePowerPlant(ctx context.Context, tx *sql.Tx
 	return r0
 }
 
+// Dialect provides a mock function with given fields:
+func (_m *PowerPlantRepository) Dialect() database.Dialect {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Dialect")
+	}
+
+	var r0 database.Dialect
+	if rf, ok := ret.Get(0).(func() database.Dialect); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(database.Dialect)
+	}
+
+	return r0
+}
+
 // Exec provides a mock function with given fields: ctx, tx, query, args
 func (_m *PowerPlantRepository) Exec(ctx context.Context, tx *sql.Tx, query string, args []interface{}) (sql.Result, error) {
 	ret := _m.Called(ctx, tx, query, args)
@@ -186,6 +285,36 @@ func (_m *PowerPlantRepository) Exec(ctx context.Context, tx *sql.Tx, query stri
 	return r0, r1
 }
 
+// GetNearbyPowerPlants provides a mock function with given fields: ctx, latitude, longitude, radiusKm, limit
+func (_m *PowerPlantRepository) GetNearbyPowerPlants(ctx context.Context, latitude float64, longitude float64, radiusKm float64, limit int) ([]*model.PowerPlant, error) {
+	ret := _m.Called(ctx, latitude, longitude, radiusKm, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetNearbyPowerPlants")
+	}
+
+	var r0 []*model.PowerPlant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, float64, float64, float64, int) ([]*model.PowerPlant, error)); ok {
+		return rf(ctx, latitude, longitude, radiusKm, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, float64, float64, float64, int) []*model.PowerPlant); ok {
+		r0 = rf(ctx, latitude, longitude, radiusKm, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.PowerPlant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, float64, float64, float64, int) error); ok {
+		r1 = rf(ctx, latitude, longitude, radiusKm, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetOffset provides a mock function with given fields: page, limit
 func (_m *PowerPlantRepository) GetOffset(page int, limit int) int {
 	ret := _m.Called(page, limit)
@@ -234,6 +363,36 @@ func (_m *PowerPlantRepository) GetPowerPlantByID(ctx context.Context, id string
 	return r0, r1
 }
 
+// GetPowerPlantByName provides a mock function with given fields: ctx, name
+func (_m *PowerPlantRepository) GetPowerPlantByName(ctx context.Context, name string) (*model.PowerPlant, error) {
+	ret := _m.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPowerPlantByName")
+	}
+
+	var r0 *model.PowerPlant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.PowerPlant, error)); ok {
+		return rf(ctx, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.PowerPlant); ok {
+		r0 = rf(ctx, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.PowerPlant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetPowerPlants provides a mock function with given fields: ctx, page, limit
 func (_m *PowerPlantRepository) GetPowerPlants(ctx context.Context, page int, limit int) ([]*model.PowerPlant, int, error) {
 	ret := _m.Called(ctx, page, limit)
@@ -271,6 +430,154 @@ func (_m *PowerPlantRepository) GetPowerPlants(ctx context.Context, page int, li
 	return r0, r1, r2
 }
 
+// GetPowerPlantsByIDs provides a mock function with given fields: ctx, ids
+func (_m *PowerPlantRepository) GetPowerPlantsByIDs(ctx context.Context, ids []string) ([]*model.PowerPlant, error) {
+	ret := _m.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPowerPlantsByIDs")
+	}
+
+	var r0 []*model.PowerPlant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]*model.PowerPlant, error)); ok {
+		return rf(ctx, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []*model.PowerPlant); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.PowerPlant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPowerPlantsInBounds provides a mock function with given fields: ctx, bounds
+func (_m *PowerPlantRepository) GetPowerPlantsInBounds(ctx context.Context, bounds geo.Bounds) ([]*model.PowerPlant, error) {
+	ret := _m.Called(ctx, bounds)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPowerPlantsInBounds")
+	}
+
+	var r0 []*model.PowerPlant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, geo.Bounds) ([]*model.PowerPlant, error)); ok {
+		return rf(ctx, bounds)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, geo.Bounds) []*model.PowerPlant); ok {
+		r0 = rf(ctx, bounds)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.PowerPlant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, geo.Bounds) error); ok {
+		r1 = rf(ctx, bounds)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPowerPlantsInPolygon provides a mock function with given fields: ctx, polygon
+func (_m *PowerPlantRepository) GetPowerPlantsInPolygon(ctx context.Context, polygon geo.Polygon) ([]*model.PowerPlant, error) {
+	ret := _m.Called(ctx, polygon)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPowerPlantsInPolygon")
+	}
+
+	var r0 []*model.PowerPlant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, geo.Polygon) ([]*model.PowerPlant, error)); ok {
+		return rf(ctx, polygon)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, geo.Polygon) []*model.PowerPlant); ok {
+		r0 = rf(ctx, polygon)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.PowerPlant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, geo.Polygon) error); ok {
+		r1 = rf(ctx, polygon)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Insert provides a mock function with given fields: ctx, tx, query, args
+func (_m *PowerPlantRepository) Insert(ctx context.Context, tx *sql.Tx, query string, args []interface{}) (int64, error) {
+	ret := _m.Called(ctx, tx, query, args)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Insert")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, string, []interface{}) (int64, error)); ok {
+		return rf(ctx, tx, query, args)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, string, []interface{}) int64); ok {
+		r0 = rf(ctx, tx, query, args)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *sql.Tx, string, []interface{}) error); ok {
+		r1 = rf(ctx, tx, query, args)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InsertMany provides a mock function with given fields: ctx, tx, query, args, count
+func (_m *PowerPlantRepository) InsertMany(ctx context.Context, tx *sql.Tx, query string, args []interface{}, count int) ([]int64, error) {
+	ret := _m.Called(ctx, tx, query, args, count)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertMany")
+	}
+
+	var r0 []int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, string, []interface{}, int) ([]int64, error)); ok {
+		return rf(ctx, tx, query, args, count)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, string, []interface{}, int) []int64); ok {
+		r0 = rf(ctx, tx, query, args, count)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int64)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *sql.Tx, string, []interface{}, int) error); ok {
+		r1 = rf(ctx, tx, query, args, count)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Master provides a mock function with given fields:
 func (_m *PowerPlantRepository) Master() *sql.DB {
 	ret := _m.Called()
@@ -327,6 +634,118 @@ func (_m *PowerPlantRepository) Query(ctx context.Context, query string, dest []
 	return r0
 }
 
+// QueryContext provides a mock function with given fields: ctx, query, args
+func (_m *PowerPlantRepository) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	var _ca []interface{}
+	_ca = append(_ca, ctx, query)
+	_ca = append(_ca, args...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for QueryContext")
+	}
+
+	var r0 *sql.Rows
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...interface{}) (*sql.Rows, error)); ok {
+		return rf(ctx, query, args...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...interface{}) *sql.Rows); ok {
+		r0 = rf(ctx, query, args...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sql.Rows)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ...interface{}) error); ok {
+		r1 = rf(ctx, query, args...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// QueryPrimary provides a mock function with given fields: ctx, tx, query, args
+func (_m *PowerPlantRepository) QueryPrimary(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (*sql.Rows, error) {
+	var _ca []interface{}
+	_ca = append(_ca, ctx, tx, query)
+	_ca = append(_ca, args...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for QueryPrimary")
+	}
+
+	var r0 *sql.Rows
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, string, ...interface{}) (*sql.Rows, error)); ok {
+		return rf(ctx, tx, query, args...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, string, ...interface{}) *sql.Rows); ok {
+		r0 = rf(ctx, tx, query, args...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sql.Rows)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *sql.Tx, string, ...interface{}) error); ok {
+		r1 = rf(ctx, tx, query, args...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// QueryRowContext provides a mock function with given fields: ctx, query, args
+func (_m *PowerPlantRepository) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	var _ca []interface{}
+	_ca = append(_ca, ctx, query)
+	_ca = append(_ca, args...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for QueryRowContext")
+	}
+
+	var r0 *sql.Row
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...interface{}) *sql.Row); ok {
+		r0 = rf(ctx, query, args...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sql.Row)
+		}
+	}
+
+	return r0
+}
+
+// QueryRowPrimary provides a mock function with given fields: ctx, tx, query, args
+func (_m *PowerPlantRepository) QueryRowPrimary(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) *sql.Row {
+	var _ca []interface{}
+	_ca = append(_ca, ctx, tx, query)
+	_ca = append(_ca, args...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for QueryRowPrimary")
+	}
+
+	var r0 *sql.Row
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, string, ...interface{}) *sql.Row); ok {
+		r0 = rf(ctx, tx, query, args...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sql.Row)
+		}
+	}
+
+	return r0
+}
+
 // Rollback provides a mock function with given fields: tx
 func (_m *PowerPlantRepository) Rollback(tx *sql.Tx) error {
 	ret := _m.Called(tx)
@@ -365,6 +784,24 @@ func (_m *PowerPlantRepository) Slave() *sql.DB {
 	return r0
 }
 
+// StreamPowerPlants provides a mock function with given fields: ctx, fn
+func (_m *PowerPlantRepository) StreamPowerPlants(ctx context.Context, fn func(*model.PowerPlant) error) error {
+	ret := _m.Called(ctx, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StreamPowerPlants")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, func(*model.PowerPlant) error) error); ok {
+		r0 = rf(ctx, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdatePowerPlant provides a mock function with given fields: ctx, tx, powerPlant
 func (_m *PowerPlantRepository) UpdatePowerPlant(ctx context.Context, tx *sql.Tx, powerPlant *model.PowerPlant) error {
 	ret := _m.Called(ctx, tx, powerPlant)
@@ -383,6 +820,24 @@ func (_m *PowerPlantRepository) UpdatePowerPlant(ctx context.Context, tx *sql.Tx
 	return r0
 }
 
+// UpdatePowerPlants provides a mock function with given fields: ctx, tx, powerPlants
+func (_m *PowerPlantRepository) UpdatePowerPlants(ctx context.Context, tx *sql.Tx, powerPlants []*model.PowerPlant) error {
+	ret := _m.Called(ctx, tx, powerPlants)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePowerPlants")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, []*model.PowerPlant) error); ok {
+		r0 = rf(ctx, tx, powerPlants)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewPowerPlantRepository creates a new instance of PowerPlantRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewPowerPlantRepository(t interface {
@@ -0,0 +1,160 @@
+// Code generated by mockery v2.46.0. DO NOT EDIT.
+
+package mockusecase
+
+import (
+	context "context"
+	auth "tensor-graphql/internal/auth"
+
+	datatype "tensor-graphql/pkg/datatype"
+
+	mock "github.com/stretchr/testify/mock"
+
+	model "tensor-graphql/internal/model"
+)
+
+// APIKeyUsecase is an autogenerated mock type for the APIKeyUsecase type
+type APIKeyUsecase struct {
+	mock.Mock
+}
+
+// AuthenticateAPIKey provides a mock function with given fields: ctx, key
+func (_m *APIKeyUsecase) AuthenticateAPIKey(ctx context.Context, key string) (*auth.Principal, error) {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AuthenticateAPIKey")
+	}
+
+	var r0 *auth.Principal
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*auth.Principal, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *auth.Principal); ok {
+		r0 = rf(ctx, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*auth.Principal)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateAPIKey provides a mock function with given fields: ctx, name, scopes, expiresAt
+func (_m *APIKeyUsecase) CreateAPIKey(ctx context.Context, name string, scopes []model.Role, expiresAt *datatype.Time) (*model.APIKey, string, error) {
+	ret := _m.Called(ctx, name, scopes, expiresAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAPIKey")
+	}
+
+	var r0 *model.APIKey
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []model.Role, *datatype.Time) (*model.APIKey, string, error)); ok {
+		return rf(ctx, name, scopes, expiresAt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []model.Role, *datatype.Time) *model.APIKey); ok {
+		r0 = rf(ctx, name, scopes, expiresAt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []model.Role, *datatype.Time) string); ok {
+		r1 = rf(ctx, name, scopes, expiresAt)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, []model.Role, *datatype.Time) error); ok {
+		r2 = rf(ctx, name, scopes, expiresAt)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetAPIKeys provides a mock function with given fields: ctx
+func (_m *APIKeyUsecase) GetAPIKeys(ctx context.Context) ([]*model.APIKey, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAPIKeys")
+	}
+
+	var r0 []*model.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*model.APIKey, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*model.APIKey); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeAPIKey provides a mock function with given fields: ctx, apiKeyID
+func (_m *APIKeyUsecase) RevokeAPIKey(ctx context.Context, apiKeyID string) (*model.APIKey, error) {
+	ret := _m.Called(ctx, apiKeyID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeAPIKey")
+	}
+
+	var r0 *model.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.APIKey, error)); ok {
+		return rf(ctx, apiKeyID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.APIKey); ok {
+		r0 = rf(ctx, apiKeyID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, apiKeyID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewAPIKeyUsecase creates a new instance of APIKeyUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAPIKeyUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *APIKeyUsecase {
+	mock := &APIKeyUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,50 @@
+// Code generated by mockery v2.46.0. DO NOT EDIT.
+
+package mockusecase
+
+import (
+	context "context"
+
+	io "io"
+
+	mock "github.com/stretchr/testify/mock"
+
+	model "tensor-graphql/internal/model"
+)
+
+// ForecastUsecase is an autogenerated mock type for the ForecastUsecase type
+type ForecastUsecase struct {
+	mock.Mock
+}
+
+// ExportForecasts provides a mock function with given fields: ctx, export, w
+func (_m *ForecastUsecase) ExportForecasts(ctx context.Context, export *model.ForecastExport, w io.Writer) error {
+	ret := _m.Called(ctx, export, w)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportForecasts")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ForecastExport, io.Writer) error); ok {
+		r0 = rf(ctx, export, w)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewForecastUsecase creates a new instance of ForecastUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewForecastUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ForecastUsecase {
+	mock := &ForecastUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,61 @@
+// Code generated by mockery v2.46.0. DO NOT EDIT.
+
+package mockusecase
+
+import (
+	context "context"
+	datatype "tensor-graphql/pkg/datatype"
+
+	mock "github.com/stretchr/testify/mock"
+
+	openmeteo "tensor-graphql/internal/library/openmeteo"
+)
+
+// Forecaster is an autogenerated mock type for the Forecaster type
+type Forecaster struct {
+	mock.Mock
+}
+
+// GetWeatherForecastBetween provides a mock function with given fields: ctx, latitude, longitude, startDate, endDate, timezone
+func (_m *Forecaster) GetWeatherForecastBetween(ctx context.Context, latitude float64, longitude float64, startDate datatype.Date, endDate datatype.Date, timezone string) (*openmeteo.WeatherResponse, error) {
+	ret := _m.Called(ctx, latitude, longitude, startDate, endDate, timezone)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWeatherForecastBetween")
+	}
+
+	var r0 *openmeteo.WeatherResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, float64, float64, datatype.Date, datatype.Date, string) (*openmeteo.WeatherResponse, error)); ok {
+		return rf(ctx, latitude, longitude, startDate, endDate, timezone)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, float64, float64, datatype.Date, datatype.Date, string) *openmeteo.WeatherResponse); ok {
+		r0 = rf(ctx, latitude, longitude, startDate, endDate, timezone)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*openmeteo.WeatherResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, float64, float64, datatype.Date, datatype.Date, string) error); ok {
+		r1 = rf(ctx, latitude, longitude, startDate, endDate, timezone)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewForecaster creates a new instance of Forecaster. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewForecaster(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Forecaster {
+	mock := &Forecaster{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
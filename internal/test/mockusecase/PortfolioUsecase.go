@@ -0,0 +1,193 @@
+// Code generated by mockery v2.46.0. DO NOT EDIT.
+
+package mockusecase
+
+import (
+	context "context"
+	model "tensor-graphql/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PortfolioUsecase is an autogenerated mock type for the PortfolioUsecase type
+type PortfolioUsecase struct {
+	mock.Mock
+}
+
+// AggregateForecasts provides a mock function with given fields: portfolio, forecasts
+func (_m *PortfolioUsecase) AggregateForecasts(portfolio *model.Portfolio, forecasts map[string][]*model.WeatherForecast) *model.PortfolioWeather {
+	ret := _m.Called(portfolio, forecasts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AggregateForecasts")
+	}
+
+	var r0 *model.PortfolioWeather
+	if rf, ok := ret.Get(0).(func(*model.Portfolio, map[string][]*model.WeatherForecast) *model.PortfolioWeather); ok {
+		r0 = rf(portfolio, forecasts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.PortfolioWeather)
+		}
+	}
+
+	return r0
+}
+
+// CreatePortfolio provides a mock function with given fields: ctx, portfolio
+func (_m *PortfolioUsecase) CreatePortfolio(ctx context.Context, portfolio *model.Portfolio) error {
+	ret := _m.Called(ctx, portfolio)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreatePortfolio")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Portfolio) error); ok {
+		r0 = rf(ctx, portfolio)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeletePortfolio provides a mock function with given fields: ctx, portfolioID
+func (_m *PortfolioUsecase) DeletePortfolio(ctx context.Context, portfolioID string) error {
+	ret := _m.Called(ctx, portfolioID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeletePortfolio")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, portfolioID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetPortfolioByID provides a mock function with given fields: ctx, portfolioID
+func (_m *PortfolioUsecase) GetPortfolioByID(ctx context.Context, portfolioID string) (*model.Portfolio, error) {
+	ret := _m.Called(ctx, portfolioID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPortfolioByID")
+	}
+
+	var r0 *model.Portfolio
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.Portfolio, error)); ok {
+		return rf(ctx, portfolioID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.Portfolio); ok {
+		r0 = rf(ctx, portfolioID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Portfolio)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, portfolioID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPortfolioPlants provides a mock function with given fields: ctx, portfolio
+func (_m *PortfolioUsecase) GetPortfolioPlants(ctx context.Context, portfolio *model.Portfolio) ([]*model.PowerPlant, error) {
+	ret := _m.Called(ctx, portfolio)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPortfolioPlants")
+	}
+
+	var r0 []*model.PowerPlant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Portfolio) ([]*model.PowerPlant, error)); ok {
+		return rf(ctx, portfolio)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Portfolio) []*model.PowerPlant); ok {
+		r0 = rf(ctx, portfolio)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.PowerPlant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Portfolio) error); ok {
+		r1 = rf(ctx, portfolio)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPortfolios provides a mock function with given fields: ctx
+func (_m *PortfolioUsecase) GetPortfolios(ctx context.Context) ([]*model.Portfolio, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPortfolios")
+	}
+
+	var r0 []*model.Portfolio
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*model.Portfolio, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*model.Portfolio); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Portfolio)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdatePortfolio provides a mock function with given fields: ctx, portfolio
+func (_m *PortfolioUsecase) UpdatePortfolio(ctx context.Context, portfolio *model.Portfolio) error {
+	ret := _m.Called(ctx, portfolio)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePortfolio")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Portfolio) error); ok {
+		r0 = rf(ctx, portfolio)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewPortfolioUsecase creates a new instance of PortfolioUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPortfolioUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PortfolioUsecase {
+	mock := &PortfolioUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -4,9 +4,13 @@ package mockusecase
 
 import (
 	context "context"
-	model "tensor-graphql/internal/model"
+	geo "tensor-graphql/pkg/geo"
+
+	io "io"
 
 	mock "github.com/stretchr/testify/mock"
+
+	model "tensor-graphql/internal/model"
 )
 
 // PowerPlantUsecase is an autogenerated mock type for the PowerPlantUsecase type
@@ -32,6 +36,132 @@ func (_m *PowerPlantUsecase) CreatePowerPlant(ctx context.Context, powerplant *m
 	return r0
 }
 
+// CreatePowerPlants provides a mock function with given fields: ctx, powerplants
+func (_m *PowerPlantUsecase) CreatePowerPlants(ctx context.Context, powerplants []*model.PowerPlant) (*model.PowerPlantBatch, error) {
+	ret := _m.Called(ctx, powerplants)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreatePowerPlants")
+	}
+
+	var r0 *model.PowerPlantBatch
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*model.PowerPlant) (*model.PowerPlantBatch, error)); ok {
+		return rf(ctx, powerplants)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []*model.PowerPlant) *model.PowerPlantBatch); ok {
+		r0 = rf(ctx, powerplants)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.PowerPlantBatch)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []*model.PowerPlant) error); ok {
+		r1 = rf(ctx, powerplants)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeletePowerPlant provides a mock function with given fields: ctx, powerplantID
+func (_m *PowerPlantUsecase) DeletePowerPlant(ctx context.Context, powerplantID string) error {
+	ret := _m.Called(ctx, powerplantID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeletePowerPlant")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, powerplantID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ExportPowerPlantsGeoJSON provides a mock function with given fields: ctx, w
+func (_m *PowerPlantUsecase) ExportPowerPlantsGeoJSON(ctx context.Context, w io.Writer) error {
+	ret := _m.Called(ctx, w)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportPowerPlantsGeoJSON")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, io.Writer) error); ok {
+		r0 = rf(ctx, w)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAuditTrail provides a mock function with given fields: ctx, powerplantID
+func (_m *PowerPlantUsecase) GetAuditTrail(ctx context.Context, powerplantID string) ([]*model.AuditEvent, error) {
+	ret := _m.Called(ctx, powerplantID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAuditTrail")
+	}
+
+	var r0 []*model.AuditEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*model.AuditEvent, error)); ok {
+		return rf(ctx, powerplantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*model.AuditEvent); ok {
+		r0 = rf(ctx, powerplantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.AuditEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, powerplantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetNearbyPowerPlants provides a mock function with given fields: ctx, latitude, longitude, radiusKm, limit
+func (_m *PowerPlantUsecase) GetNearbyPowerPlants(ctx context.Context, latitude float64, longitude float64, radiusKm float64, limit int) ([]*model.PowerPlant, error) {
+	ret := _m.Called(ctx, latitude, longitude, radiusKm, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetNearbyPowerPlants")
+	}
+
+	var r0 []*model.PowerPlant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, float64, float64, float64, int) ([]*model.PowerPlant, error)); ok {
+		return rf(ctx, latitude, longitude, radiusKm, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, float64, float64, float64, int) []*model.PowerPlant); ok {
+		r0 = rf(ctx, latitude, longitude, radiusKm, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.PowerPlant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, float64, float64, float64, int) error); ok {
+		r1 = rf(ctx, latitude, longitude, radiusKm, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetPowerPlantByID provides a mock function with given fields: ctx, powerplantID
 func (_m *PowerPlantUsecase) GetPowerPlantByID(ctx context.Context, powerplantID string) (*model.PowerPlant, error) {
 	ret := _m.Called(ctx, powerplantID)
@@ -99,6 +229,124 @@ func (_m *PowerPlantUsecase) GetPowerPlants(ctx context.Context, page int, limit
 	return r0, r1, r2
 }
 
+// GetPowerPlantsGeoJSON provides a mock function with given fields: ctx
+func (_m *PowerPlantUsecase) GetPowerPlantsGeoJSON(ctx context.Context) (string, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPowerPlantsGeoJSON")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (string, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) string); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPowerPlantsInBounds provides a mock function with given fields: ctx, bounds
+func (_m *PowerPlantUsecase) GetPowerPlantsInBounds(ctx context.Context, bounds geo.Bounds) (*model.PowerPlantRegion, error) {
+	ret := _m.Called(ctx, bounds)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPowerPlantsInBounds")
+	}
+
+	var r0 *model.PowerPlantRegion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, geo.Bounds) (*model.PowerPlantRegion, error)); ok {
+		return rf(ctx, bounds)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, geo.Bounds) *model.PowerPlantRegion); ok {
+		r0 = rf(ctx, bounds)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.PowerPlantRegion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, geo.Bounds) error); ok {
+		r1 = rf(ctx, bounds)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPowerPlantsInPolygon provides a mock function with given fields: ctx, geojson
+func (_m *PowerPlantUsecase) GetPowerPlantsInPolygon(ctx context.Context, geojson string) (*model.PowerPlantRegion, error) {
+	ret := _m.Called(ctx, geojson)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPowerPlantsInPolygon")
+	}
+
+	var r0 *model.PowerPlantRegion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.PowerPlantRegion, error)); ok {
+		return rf(ctx, geojson)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.PowerPlantRegion); ok {
+		r0 = rf(ctx, geojson)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.PowerPlantRegion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, geojson)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ImportPowerPlants provides a mock function with given fields: ctx, file, dryRun
+func (_m *PowerPlantUsecase) ImportPowerPlants(ctx context.Context, file io.Reader, dryRun bool) (*model.PowerPlantImport, error) {
+	ret := _m.Called(ctx, file, dryRun)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportPowerPlants")
+	}
+
+	var r0 *model.PowerPlantImport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, io.Reader, bool) (*model.PowerPlantImport, error)); ok {
+		return rf(ctx, file, dryRun)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, io.Reader, bool) *model.PowerPlantImport); ok {
+		r0 = rf(ctx, file, dryRun)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.PowerPlantImport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, io.Reader, bool) error); ok {
+		r1 = rf(ctx, file, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdatePowerPlant provides a mock function with given fields: ctx, powerplant
 func (_m *PowerPlantUsecase) UpdatePowerPlant(ctx context.Context, powerplant *model.PowerPlant) error {
 	ret := _m.Called(ctx, powerplant)
@@ -117,6 +365,36 @@ func (_m *PowerPlantUsecase) UpdatePowerPlant(ctx context.Context, powerplant *m
 	return r0
 }
 
+// UpdatePowerPlants provides a mock function with given fields: ctx, updates
+func (_m *PowerPlantUsecase) UpdatePowerPlants(ctx context.Context, updates []*model.UpdatePowerPlantInput) (*model.PowerPlantBatch, error) {
+	ret := _m.Called(ctx, updates)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePowerPlants")
+	}
+
+	var r0 *model.PowerPlantBatch
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*model.UpdatePowerPlantInput) (*model.PowerPlantBatch, error)); ok {
+		return rf(ctx, updates)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []*model.UpdatePowerPlantInput) *model.PowerPlantBatch); ok {
+		r0 = rf(ctx, updates)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.PowerPlantBatch)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []*model.UpdatePowerPlantInput) error); ok {
+		r1 = rf(ctx, updates)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewPowerPlantUsecase creates a new instance of PowerPlantUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewPowerPlantUsecase(t interface {
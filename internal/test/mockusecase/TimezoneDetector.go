@@ -0,0 +1,56 @@
+// Code generated by mockery v2.46.0. DO NOT EDIT.
+
+package mockusecase
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// TimezoneDetector is an autogenerated mock type for the TimezoneDetector type
+type TimezoneDetector struct {
+	mock.Mock
+}
+
+// DetectTimezone provides a mock function with given fields: ctx, latitude, longitude
+func (_m *TimezoneDetector) DetectTimezone(ctx context.Context, latitude float64, longitude float64) (string, error) {
+	ret := _m.Called(ctx, latitude, longitude)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DetectTimezone")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, float64, float64) (string, error)); ok {
+		return rf(ctx, latitude, longitude)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, float64, float64) string); ok {
+		r0 = rf(ctx, latitude, longitude)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, float64, float64) error); ok {
+		r1 = rf(ctx, latitude, longitude)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewTimezoneDetector creates a new instance of TimezoneDetector. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTimezoneDetector(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TimezoneDetector {
+	mock := &TimezoneDetector{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,151 @@
+package apikeyusecase_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"tensor-graphql/internal/auth"
+	"tensor-graphql/internal/model"
+	"tensor-graphql/internal/test"
+	apikeyusecase "tensor-graphql/internal/usecase/api_key"
+	"tensor-graphql/pkg/datatype"
+	"tensor-graphql/pkg/derrors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	mc := test.InitMockComponent(t)
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "admin"})
+	testUsecase := apikeyusecase.NewAPIKeyUsecase(mc.APIKeyRepository)
+
+	t.Run("CreateAPIKey_Success", func(t *testing.T) {
+		var stored *model.APIKey
+		mc.APIKeyRepository.On("CreateAPIKey", mock.Anything, mock.Anything, mock.AnythingOfType("*model.APIKey")).
+			Run(func(args mock.Arguments) {
+				stored = args.Get(2).(*model.APIKey)
+			}).
+			Return(nil).Once()
+
+		apiKey, key, err := testUsecase.CreateAPIKey(ctx, "scada", []model.Role{model.RoleOperator}, nil)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(key, "tgk_"))
+		assert.Equal(t, stored, apiKey)
+		assert.Equal(t, hash(key), stored.KeyHash)
+		assert.Equal(t, key[:len(stored.Prefix)], stored.Prefix)
+		assert.Equal(t, "admin", stored.CreatedBy)
+	})
+
+	t.Run("CreateAPIKey_InvalidArgument", func(t *testing.T) {
+		expiresAt := datatype.NewTime(&time.Time{})
+		_, _, err := testUsecase.CreateAPIKey(ctx, "", nil, &expiresAt)
+		assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+		assert.Len(t, derrors.FieldsOf(err), 3)
+	})
+
+	t.Run("CreateAPIKey_Anonymous", func(t *testing.T) {
+		_, _, err := testUsecase.CreateAPIKey(context.Background(), "scada", []model.Role{model.RoleViewer}, nil)
+		assert.True(t, derrors.IsErrCode(err, derrors.Unauthorized))
+	})
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	mc := test.InitMockComponent(t)
+	ctx := context.Background()
+	testUsecase := apikeyusecase.NewAPIKeyUsecase(mc.APIKeyRepository)
+
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	var testCases = []struct {
+		caseName     string
+		key          string
+		expectations func(key string)
+		results      func(principal *auth.Principal, err error)
+	}{
+		{
+			caseName: "AuthenticateAPIKey_Success",
+			key:      "tgk_valid",
+			expectations: func(key string) {
+				mc.APIKeyRepository.On("GetAPIKeyByHash", mock.Anything, hash(key)).
+					Return(&model.APIKey{ID: "1", OrganizationID: "7", Scopes: []model.Role{model.RoleOperator}, ExpiresAt: datatype.NewTime(&future)}, nil)
+				mc.APIKeyRepository.On("TouchAPIKey", mock.Anything, mock.Anything, "1").
+					Return(nil)
+			},
+			results: func(principal *auth.Principal, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, "api_key:1", principal.Subject)
+				assert.Equal(t, "7", principal.OrganizationID)
+				assert.True(t, principal.HasRole(auth.RoleOperator))
+				assert.False(t, principal.HasRole(auth.RoleAdmin))
+			},
+		},
+		{
+			caseName: "AuthenticateAPIKey_RecentlyUsed",
+			key:      "tgk_recent",
+			expectations: func(key string) {
+				recent := time.Now().Add(-time.Minute)
+				mc.APIKeyRepository.On("GetAPIKeyByHash", mock.Anything, hash(key)).
+					Return(&model.APIKey{ID: "2", OrganizationID: "7", Scopes: []model.Role{model.RoleOperator}, LastUsedAt: datatype.NewTime(&recent)}, nil)
+			},
+			results: func(principal *auth.Principal, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, "api_key:2", principal.Subject)
+				mc.APIKeyRepository.AssertNotCalled(t, "TouchAPIKey", mock.Anything, mock.Anything, "2")
+			},
+		},
+		{
+			caseName: "AuthenticateAPIKey_Unknown",
+			key:      "tgk_unknown",
+			expectations: func(key string) {
+				mc.APIKeyRepository.On("GetAPIKeyByHash", mock.Anything, hash(key)).
+					Return(nil, nil)
+			},
+			results: func(principal *auth.Principal, err error) {
+				assert.Nil(t, principal)
+				assert.True(t, derrors.IsErrCode(err, derrors.Unauthorized))
+			},
+		},
+		{
+			caseName: "AuthenticateAPIKey_Revoked",
+			key:      "tgk_revoked",
+			expectations: func(key string) {
+				mc.APIKeyRepository.On("GetAPIKeyByHash", mock.Anything, hash(key)).
+					Return(&model.APIKey{ID: "2", RevokedAt: datatype.NewTime(&past)}, nil)
+			},
+			results: func(principal *auth.Principal, err error) {
+				assert.Nil(t, principal)
+				assert.True(t, derrors.IsErrCode(err, derrors.Unauthorized))
+			},
+		},
+		{
+			caseName: "AuthenticateAPIKey_Expired",
+			key:      "tgk_expired",
+			expectations: func(key string) {
+				mc.APIKeyRepository.On("GetAPIKeyByHash", mock.Anything, hash(key)).
+					Return(&model.APIKey{ID: "3", ExpiresAt: datatype.NewTime(&past)}, nil)
+			},
+			results: func(principal *auth.Principal, err error) {
+				assert.Nil(t, principal)
+				assert.True(t, derrors.IsErrCode(err, derrors.Unauthorized))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			testCase.expectations(testCase.key)
+			principal, err := testUsecase.AuthenticateAPIKey(ctx, testCase.key)
+			testCase.results(principal, err)
+		})
+	}
+}
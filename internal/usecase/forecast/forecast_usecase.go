@@ -0,0 +1,272 @@
+package forecastusecase
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"tensor-graphql/internal/auth"
+	"tensor-graphql/internal/library/openmeteo"
+	"tensor-graphql/internal/model"
+	powerplantrepo "tensor-graphql/internal/repository/power_plant"
+	"tensor-graphql/pkg/datatype"
+	"tensor-graphql/pkg/derrors"
+	"time"
+)
+
+const (
+	// exportMaxPlants and exportMaxDays bound the forecasts of a single
+	// export, a forecast never spans more than the horizon.
+	exportMaxPlants = 1000
+	exportMaxDays   = forecastHorizonDays
+
+	// forecastHorizonDays is how far ahead Open-Meteo forecasts, today
+	// included.
+	forecastHorizonDays = 16
+
+	// exportConcurrency bounds the forecasts fetched ahead of the plant
+	// being written.
+	exportConcurrency = 8
+)
+
+type (
+	ForecastUsecase interface {
+		// ExportForecasts writes the hourly forecasts selected by export to w,
+		// one plant at a time while the forecasts of the next plants are
+		// fetched. Nothing is written when export is invalid.
+		ExportForecasts(ctx context.Context, export *model.ForecastExport, w io.Writer) (err error)
+	}
+
+	// Forecaster fetches the hourly forecast of a location.
+	Forecaster interface {
+		GetWeatherForecastBetween(ctx context.Context, latitude, longitude float64, startDate, endDate datatype.Date, timezone string) (weather *openmeteo.WeatherResponse, err error)
+	}
+
+	forecastUsecase struct {
+		powerplantRepo powerplantrepo.PowerPlantRepository
+		forecaster     Forecaster
+	}
+)
+
+func NewForecastUsecase(powerplantRepo powerplantrepo.PowerPlantRepository, forecaster Forecaster) ForecastUsecase {
+	return &forecastUsecase{
+		powerplantRepo: powerplantRepo,
+		forecaster:     forecaster,
+	}
+}
+
+func (u *forecastUsecase) ExportForecasts(ctx context.Context, export *model.ForecastExport, w io.Writer) (err error) {
+	defer derrors.Wrap(&err, "ExportForecasts(%d plants, %s)", len(export.PlantIDs), export.Format)
+
+	_, err = auth.RequirePrincipal(ctx)
+	if err != nil {
+		return err
+	}
+
+	fields := validateExport(export)
+	if len(fields) > 0 {
+		return derrors.NewWithFields(derrors.InvalidArgument, fields, "invalid forecast export")
+	}
+
+	// Every plant is looked up before the first row, so an unknown ID fails
+	// the export instead of truncating it.
+	found, err := u.powerplantRepo.GetPowerPlantsByIDs(ctx, export.PlantIDs)
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[string]*model.PowerPlant, len(found))
+	for _, powerplant := range found {
+		byID[powerplant.ID] = powerplant
+	}
+
+	powerplants := make([]*model.PowerPlant, 0, len(export.PlantIDs))
+	for _, id := range export.PlantIDs {
+		powerplant, ok := byID[id]
+		if !ok {
+			return derrors.NewWithFields(derrors.NotFound, []derrors.FieldError{
+				{Field: "plantIds", Message: "power plant " + id + " not found"},
+			}, "power plant %q not found", id)
+		}
+		powerplants = append(powerplants, powerplant)
+	}
+
+	fields = validateHorizon(export, powerplants, time.Now())
+	if len(fields) > 0 {
+		return derrors.NewWithFields(derrors.InvalidArgument, fields, "invalid forecast export")
+	}
+
+	writer, err := newForecastWriter(w, export.Format, export.Variables)
+	if err != nil {
+		return derrors.WrapStack(err, derrors.Unknown, "newForecastWriter")
+	}
+
+	// Stop the fetches ahead once the export fails.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sem := make(chan struct{}, exportConcurrency)
+	fetches := u.fetchForecasts(ctx, sem, powerplants, export)
+	for i, powerplant := range powerplants {
+		var fetch forecastFetch
+		select {
+		case fetch = <-fetches[i]:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if fetch.err != nil {
+			return fetch.err
+		}
+
+		err = writeForecast(writer, powerplant, export, fetch.weather)
+		if err != nil {
+			return err
+		}
+		<-sem
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return derrors.WrapStack(err, derrors.Unknown, "writer.Close")
+	}
+
+	return nil
+}
+
+// forecastFetch is the fetched forecast of a plant.
+type forecastFetch struct {
+	weather *openmeteo.WeatherResponse
+	err     error
+}
+
+// fetchForecasts fetches the forecasts of powerplants in order, each on the
+// channel at its index. A fetch holds a slot of sem until the caller has
+// written the plant, so at most cap(sem) forecasts are held in memory.
+func (u *forecastUsecase) fetchForecasts(ctx context.Context, sem chan struct{}, powerplants []*model.PowerPlant, export *model.ForecastExport) []chan forecastFetch {
+	fetches := make([]chan forecastFetch, len(powerplants))
+	for i := range fetches {
+		fetches[i] = make(chan forecastFetch, 1)
+	}
+
+	go func() {
+		for i, powerplant := range powerplants {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+			go func() {
+				weather, err := u.forecaster.GetWeatherForecastBetween(ctx, powerplant.Latitude, powerplant.Longitude, export.StartDate, export.EndDate, powerplant.Timezone)
+				fetches[i] <- forecastFetch{weather: weather, err: err}
+			}()
+		}
+	}()
+
+	return fetches
+}
+
+// writeForecast writes the hours of the forecast of powerplant.
+func writeForecast(writer forecastWriter, powerplant *model.PowerPlant, export *model.ForecastExport, weather *openmeteo.WeatherResponse) error {
+	loc := openmeteo.LoadLocation(powerplant.Timezone)
+	row := forecastRow{
+		PlantID:   powerplant.ID,
+		PlantName: powerplant.Name,
+		Values:    make([]float64, len(export.Variables)),
+	}
+	for i := range weather.Hourly.Time {
+		forecastTime, err := openmeteo.ParseTime(weather.Hourly.Time[i], loc)
+		if err != nil {
+			return err
+		}
+		row.Time = *forecastTime.Time()
+
+		for j, variable := range export.Variables {
+			row.Values[j] = forecastValue(weather.Hourly, variable, i)
+		}
+
+		err = writer.Write(row)
+		if err != nil {
+			return derrors.WrapStack(err, derrors.Unknown, "writer.Write")
+		}
+	}
+
+	err := writer.Flush()
+	if err != nil {
+		return derrors.WrapStack(err, derrors.Unknown, "writer.Flush")
+	}
+
+	return nil
+}
+
+func forecastValue(hourly openmeteo.HourlyData, variable model.ForecastVariable, i int) float64 {
+	switch variable {
+	case model.ForecastVariableTemperature:
+		return hourly.Temperature2m[i]
+	case model.ForecastVariablePrecipitation:
+		return hourly.Precipitation[i]
+	case model.ForecastVariableWindSpeed:
+		return hourly.WindSpeed10m[i]
+	default:
+		return hourly.WindDirection10m[i]
+	}
+}
+
+func validateExport(export *model.ForecastExport) (fields []derrors.FieldError) {
+	if len(export.PlantIDs) == 0 || len(export.PlantIDs) > exportMaxPlants {
+		fields = append(fields, derrors.FieldError{Field: "plantIds", Message: "plantIds must have between 1 and " + strconv.Itoa(exportMaxPlants) + " IDs"})
+	}
+
+	lastDate := export.StartDate.AddDate(0, 0, exportMaxDays-1)
+	switch {
+	case export.StartDate.IsNil() || export.EndDate.IsNil():
+		fields = append(fields, derrors.FieldError{Field: "startDate", Message: "startDate and endDate are required"})
+	case export.EndDate.IsBefore(export.StartDate):
+		fields = append(fields, derrors.FieldError{Field: "endDate", Message: "endDate must not be before startDate"})
+	case lastDate.IsBefore(export.EndDate):
+		fields = append(fields, derrors.FieldError{Field: "endDate", Message: "the export must span at most " + strconv.Itoa(exportMaxDays) + " days"})
+	}
+
+	if len(export.Variables) == 0 {
+		fields = append(fields, derrors.FieldError{Field: "variables", Message: "variables must name at least one variable"})
+	}
+	seen := make(map[model.ForecastVariable]bool)
+	for _, variable := range export.Variables {
+		if !isForecastVariable(variable) || seen[variable] {
+			fields = append(fields, derrors.FieldError{Field: "variables", Message: "variables must be distinct names of temperature, precipitation, windSpeed and windDirection"})
+			break
+		}
+		seen[variable] = true
+	}
+
+	if export.Format != model.ExportFormatCSV && export.Format != model.ExportFormatParquet {
+		fields = append(fields, derrors.FieldError{Field: "format", Message: "format must be csv or parquet"})
+	}
+
+	return fields
+}
+
+// validateHorizon checks endDate against the last day forecast for every plant,
+// counted from today in the timezone of the plant.
+func validateHorizon(export *model.ForecastExport, powerplants []*model.PowerPlant, now time.Time) (fields []derrors.FieldError) {
+	for _, powerplant := range powerplants {
+		today := now.In(openmeteo.LoadLocation(powerplant.Timezone))
+		// Dates are parsed at midnight UTC, the last day is compared alike.
+		lastDay := time.Date(today.Year(), today.Month(), today.Day()+forecastHorizonDays-1, 0, 0, 0, 0, time.UTC)
+		if export.EndDate.Time().After(lastDay) {
+			return []derrors.FieldError{
+				{Field: "endDate", Message: "endDate must be within the " + strconv.Itoa(forecastHorizonDays) + " days forecast from today of power plant " + powerplant.ID},
+			}
+		}
+	}
+
+	return nil
+}
+
+func isForecastVariable(variable model.ForecastVariable) bool {
+	for _, known := range model.ForecastVariables {
+		if variable == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,260 @@
+package forecastusecase_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"tensor-graphql/internal/auth"
+	"tensor-graphql/internal/library/openmeteo"
+	"tensor-graphql/internal/model"
+	"tensor-graphql/internal/test"
+	forecastusecase "tensor-graphql/internal/usecase/forecast"
+	"tensor-graphql/pkg/datatype"
+	"tensor-graphql/pkg/derrors"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// parquetForecast is a row of a Parquet export of temperature and windSpeed.
+type parquetForecast struct {
+	PlantID     string  `parquet:"plantId"`
+	PlantName   string  `parquet:"plantName"`
+	Time        int64   `parquet:"time"`
+	Temperature float64 `parquet:"temperature"`
+	WindSpeed   float64 `parquet:"windSpeed"`
+}
+
+func TestExportForecasts(t *testing.T) {
+	mc := test.InitMockComponent(t)
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "viewer"})
+	testUsecase := forecastusecase.NewForecastUsecase(mc.PowerPlantRepository, mc.Forecaster)
+
+	startDate, _ := datatype.ParseDate("2025-03-04", "UTC")
+	endDate, _ := datatype.ParseDate("2025-03-05", "UTC")
+	lateDate, _ := datatype.ParseDate("2025-03-20", "UTC")
+	// Kiritimati is 25 hours ahead of Pago Pago, so the last day forecast for
+	// Kiritimati is always beyond the horizon of Pago Pago.
+	kiritimatiLastDay, _ := datatype.ParseDate(time.Now().In(openmeteo.LoadLocation("Pacific/Kiritimati")).AddDate(0, 0, 15).Format("2006-01-02"), "UTC")
+
+	jakarta := &model.PowerPlant{ID: "1", Name: "test_name", Latitude: -6.2, Longitude: 106.8, Timezone: "Asia/Jakarta"}
+	london := &model.PowerPlant{ID: "2", Name: "other_name", Latitude: 51.5, Longitude: -0.1, Timezone: "Europe/London"}
+	kiritimati := &model.PowerPlant{ID: "3", Name: "ahead_name", Latitude: 1.9, Longitude: -157.5, Timezone: "Pacific/Kiritimati"}
+	pagoPago := &model.PowerPlant{ID: "4", Name: "behind_name", Latitude: -14.3, Longitude: -170.7, Timezone: "Pacific/Pago_Pago"}
+
+	weather := func(temperature float64) *openmeteo.WeatherResponse {
+		return &openmeteo.WeatherResponse{
+			Hourly: openmeteo.HourlyData{
+				Time:             []string{"2025-03-04T00:00", "2025-03-04T01:00"},
+				Temperature2m:    []float64{temperature, temperature + 0.5},
+				Precipitation:    []float64{0, 0.2},
+				WindSpeed10m:     []float64{3, 4},
+				WindDirection10m: []float64{90, 180},
+			},
+		}
+	}
+	forecasts := func() {
+		mc.PowerPlantRepository.On("GetPowerPlantsByIDs", mock.Anything, []string{"1", "2"}).
+			Return([]*model.PowerPlant{jakarta, london}, nil).Once()
+		mc.Forecaster.On("GetWeatherForecastBetween", mock.Anything, -6.2, 106.8, startDate, endDate, "Asia/Jakarta").
+			Return(weather(28), nil).Once()
+		mc.Forecaster.On("GetWeatherForecastBetween", mock.Anything, 51.5, -0.1, startDate, endDate, "Europe/London").
+			Return(weather(7), nil).Once()
+	}
+	export := func(format model.ExportFormat) *model.ForecastExport {
+		return &model.ForecastExport{
+			PlantIDs:  []string{"1", "2"},
+			StartDate: startDate,
+			EndDate:   endDate,
+			Variables: []model.ForecastVariable{model.ForecastVariableTemperature, model.ForecastVariableWindSpeed},
+			Format:    format,
+		}
+	}
+
+	var testCases = []struct {
+		caseName     string
+		ctx          context.Context
+		export       *model.ForecastExport
+		expectations func()
+		results      func(file []byte, err error)
+	}{
+		{
+			caseName:     "ExportForecasts_CSV",
+			ctx:          ctx,
+			export:       export(model.ExportFormatCSV),
+			expectations: forecasts,
+			results: func(file []byte, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, strings.Join([]string{
+					"plantId,plantName,time,temperature,windSpeed",
+					"1,test_name,2025-03-04T00:00:00+07:00,28,3",
+					"1,test_name,2025-03-04T01:00:00+07:00,28.5,4",
+					"2,other_name,2025-03-04T00:00:00Z,7,3",
+					"2,other_name,2025-03-04T01:00:00Z,7.5,4",
+					"",
+				}, "\n"), string(file))
+			},
+		},
+		{
+			caseName:     "ExportForecasts_Parquet",
+			ctx:          ctx,
+			export:       export(model.ExportFormatParquet),
+			expectations: forecasts,
+			results: func(file []byte, err error) {
+				assert.NoError(t, err)
+
+				rows, err := parquet.Read[parquetForecast](bytes.NewReader(file), int64(len(file)))
+				assert.NoError(t, err)
+				assert.Equal(t, []parquetForecast{
+					{PlantID: "1", PlantName: "test_name", Time: 1741021200000, Temperature: 28, WindSpeed: 3},
+					{PlantID: "1", PlantName: "test_name", Time: 1741024800000, Temperature: 28.5, WindSpeed: 4},
+					{PlantID: "2", PlantName: "other_name", Time: 1741046400000, Temperature: 7, WindSpeed: 3},
+					{PlantID: "2", PlantName: "other_name", Time: 1741050000000, Temperature: 7.5, WindSpeed: 4},
+				}, rows)
+			},
+		},
+		{
+			caseName: "ExportForecasts_Invalid",
+			ctx:      ctx,
+			export: &model.ForecastExport{
+				StartDate: endDate,
+				EndDate:   startDate,
+				Variables: []model.ForecastVariable{model.ForecastVariableTemperature, "humidity"},
+				Format:    "xlsx",
+			},
+			expectations: func() {},
+			results: func(file []byte, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+				assert.Equal(t, []derrors.FieldError{
+					{Field: "plantIds", Message: "plantIds must have between 1 and 1000 IDs"},
+					{Field: "endDate", Message: "endDate must not be before startDate"},
+					{Field: "variables", Message: "variables must be distinct names of temperature, precipitation, windSpeed and windDirection"},
+					{Field: "format", Message: "format must be csv or parquet"},
+				}, derrors.FieldsOf(err))
+				assert.Empty(t, file)
+			},
+		},
+		{
+			caseName: "ExportForecasts_TooLong",
+			ctx:      ctx,
+			export: &model.ForecastExport{
+				PlantIDs:  []string{"1"},
+				StartDate: startDate,
+				EndDate:   lateDate,
+				Variables: model.ForecastVariables,
+				Format:    model.ExportFormatCSV,
+			},
+			expectations: func() {},
+			results: func(file []byte, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+				assert.Equal(t, []derrors.FieldError{
+					{Field: "endDate", Message: "the export must span at most 16 days"},
+				}, derrors.FieldsOf(err))
+				assert.Empty(t, file)
+			},
+		},
+		{
+			caseName: "ExportForecasts_HorizonOfPlant",
+			ctx:      ctx,
+			export: &model.ForecastExport{
+				PlantIDs:  []string{"3"},
+				StartDate: kiritimatiLastDay,
+				EndDate:   kiritimatiLastDay,
+				Variables: []model.ForecastVariable{model.ForecastVariableTemperature},
+				Format:    model.ExportFormatCSV,
+			},
+			expectations: func() {
+				mc.PowerPlantRepository.On("GetPowerPlantsByIDs", mock.Anything, []string{"3"}).
+					Return([]*model.PowerPlant{kiritimati}, nil).Once()
+				mc.Forecaster.On("GetWeatherForecastBetween", mock.Anything, 1.9, -157.5, kiritimatiLastDay, kiritimatiLastDay, "Pacific/Kiritimati").
+					Return(weather(27), nil).Once()
+			},
+			results: func(file []byte, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, strings.Join([]string{
+					"plantId,plantName,time,temperature",
+					"3,ahead_name,2025-03-04T00:00:00+14:00,27",
+					"3,ahead_name,2025-03-04T01:00:00+14:00,27.5",
+					"",
+				}, "\n"), string(file))
+			},
+		},
+		{
+			caseName: "ExportForecasts_BeyondHorizonOfPlant",
+			ctx:      ctx,
+			export: &model.ForecastExport{
+				PlantIDs:  []string{"3", "4"},
+				StartDate: kiritimatiLastDay,
+				EndDate:   kiritimatiLastDay,
+				Variables: model.ForecastVariables,
+				Format:    model.ExportFormatCSV,
+			},
+			expectations: func() {
+				mc.PowerPlantRepository.On("GetPowerPlantsByIDs", mock.Anything, []string{"3", "4"}).
+					Return([]*model.PowerPlant{kiritimati, pagoPago}, nil).Once()
+			},
+			results: func(file []byte, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+				assert.Equal(t, []derrors.FieldError{
+					{Field: "endDate", Message: "endDate must be within the 16 days forecast from today of power plant 4"},
+				}, derrors.FieldsOf(err))
+				assert.Empty(t, file)
+			},
+		},
+		{
+			caseName: "ExportForecasts_NotFound",
+			ctx:      ctx,
+			export:   export(model.ExportFormatCSV),
+			expectations: func() {
+				mc.PowerPlantRepository.On("GetPowerPlantsByIDs", mock.Anything, []string{"1", "2"}).
+					Return([]*model.PowerPlant{jakarta}, nil).Once()
+			},
+			results: func(file []byte, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.NotFound))
+				assert.Equal(t, []derrors.FieldError{
+					{Field: "plantIds", Message: "power plant 2 not found"},
+				}, derrors.FieldsOf(err))
+				assert.Empty(t, file)
+			},
+		},
+		{
+			caseName: "ExportForecasts_ForecastError",
+			ctx:      ctx,
+			export:   export(model.ExportFormatCSV),
+			expectations: func() {
+				mc.PowerPlantRepository.On("GetPowerPlantsByIDs", mock.Anything, []string{"1", "2"}).
+					Return([]*model.PowerPlant{jakarta, london}, nil).Once()
+				mc.Forecaster.On("GetWeatherForecastBetween", mock.Anything, -6.2, 106.8, startDate, endDate, "Asia/Jakarta").
+					Return(nil, derrors.New(derrors.Unknown, "error")).Once()
+				// London is fetched ahead concurrently, unless the export failed first.
+				mc.Forecaster.On("GetWeatherForecastBetween", mock.Anything, 51.5, -0.1, startDate, endDate, "Europe/London").
+					Return(weather(7), nil).Maybe()
+			},
+			results: func(file []byte, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.Unknown))
+			},
+		},
+		{
+			caseName:     "ExportForecasts_Unauthenticated",
+			ctx:          context.Background(),
+			export:       export(model.ExportFormatCSV),
+			expectations: func() {},
+			results: func(file []byte, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.Unauthorized))
+				assert.Empty(t, file)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			testCase.expectations()
+			var file bytes.Buffer
+			err := testUsecase.ExportForecasts(testCase.ctx, testCase.export, &file)
+			testCase.results(file.Bytes(), err)
+		})
+	}
+}
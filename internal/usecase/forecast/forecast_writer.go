@@ -0,0 +1,142 @@
+package forecastusecase
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"tensor-graphql/internal/model"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// parquetRowGroupSize bounds the rows a Parquet export buffers before they
+// are written out as a row group.
+const parquetRowGroupSize = 10000
+
+type (
+	// forecastRow is an hour of the forecast of a plant, Values holds the
+	// exported variables in their column order.
+	forecastRow struct {
+		PlantID   string
+		PlantName string
+		Time      time.Time
+		Values    []float64
+	}
+
+	// forecastWriter writes the rows of an export in a file format.
+	forecastWriter interface {
+		Write(row forecastRow) error
+		// Flush writes out the rows buffered so far.
+		Flush() error
+		// Close ends the file, it does not close the underlying writer.
+		Close() error
+	}
+)
+
+func newForecastWriter(w io.Writer, format model.ExportFormat, variables []model.ForecastVariable) (forecastWriter, error) {
+	if format == model.ExportFormatParquet {
+		return newParquetForecastWriter(w, variables), nil
+	}
+	return newCSVForecastWriter(w, variables)
+}
+
+// csvForecastWriter writes a header row and then one row per hour, times
+// keep the offset of the plant.
+type csvForecastWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func newCSVForecastWriter(w io.Writer, variables []model.ForecastVariable) (*csvForecastWriter, error) {
+	header := []string{"plantId", "plantName", "time"}
+	for _, variable := range variables {
+		header = append(header, string(variable))
+	}
+
+	cw := &csvForecastWriter{
+		w:      csv.NewWriter(w),
+		record: make([]string, len(header)),
+	}
+	return cw, cw.w.Write(header)
+}
+
+func (cw *csvForecastWriter) Write(row forecastRow) error {
+	cw.record[0] = row.PlantID
+	cw.record[1] = row.PlantName
+	cw.record[2] = row.Time.Format(time.RFC3339)
+	for i, value := range row.Values {
+		cw.record[3+i] = strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return cw.w.Write(cw.record)
+}
+
+func (cw *csvForecastWriter) Flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+func (cw *csvForecastWriter) Close() error {
+	return cw.Flush()
+}
+
+// parquetForecastWriter writes a Parquet file whose times are UTC timestamps
+// in milliseconds.
+type parquetForecastWriter struct {
+	w *parquet.Writer
+	// columns are the column indexes of plantId, plantName, time and the
+	// variables, the schema orders its columns by name.
+	columns []int
+	row     parquet.Row
+}
+
+func newParquetForecastWriter(w io.Writer, variables []model.ForecastVariable) *parquetForecastWriter {
+	names := []string{"plantId", "plantName", "time"}
+	group := parquet.Group{
+		"plantId":   parquet.String(),
+		"plantName": parquet.String(),
+		"time":      parquet.Timestamp(parquet.Millisecond),
+	}
+	for _, variable := range variables {
+		names = append(names, string(variable))
+		group[string(variable)] = parquet.Leaf(parquet.DoubleType)
+	}
+	schema := parquet.NewSchema("forecast", group)
+
+	pw := &parquetForecastWriter{
+		w:   parquet.NewWriter(w, schema, parquet.MaxRowsPerRowGroup(parquetRowGroupSize)),
+		row: make(parquet.Row, len(names)),
+	}
+	for _, name := range names {
+		column, _ := schema.Lookup(name)
+		pw.columns = append(pw.columns, column.ColumnIndex)
+	}
+	return pw
+}
+
+func (pw *parquetForecastWriter) Write(row forecastRow) error {
+	pw.set(0, parquet.ByteArrayValue([]byte(row.PlantID)))
+	pw.set(1, parquet.ByteArrayValue([]byte(row.PlantName)))
+	pw.set(2, parquet.Int64Value(row.Time.UnixMilli()))
+	for i, value := range row.Values {
+		pw.set(3+i, parquet.DoubleValue(value))
+	}
+
+	_, err := pw.w.WriteRows([]parquet.Row{pw.row})
+	return err
+}
+
+func (pw *parquetForecastWriter) set(i int, value parquet.Value) {
+	column := pw.columns[i]
+	pw.row[column] = value.Level(0, 0, column)
+}
+
+// Flush leaves the rows to the row group size, small row groups would make
+// the file slow to read.
+func (pw *parquetForecastWriter) Flush() error {
+	return nil
+}
+
+func (pw *parquetForecastWriter) Close() error {
+	return pw.w.Close()
+}
@@ -0,0 +1,312 @@
+package portfoliousecase
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"tensor-graphql/internal/auth"
+	"tensor-graphql/internal/model"
+	repository "tensor-graphql/internal/repository/common"
+	portfoliorepo "tensor-graphql/internal/repository/portfolio"
+	powerplantrepo "tensor-graphql/internal/repository/power_plant"
+	"tensor-graphql/pkg/derrors"
+
+	"github.com/asaskevich/govalidator"
+)
+
+const (
+	portfolioNameMinLength = "1"
+	portfolioNameMaxLength = "255"
+
+	// portfolioMaxMembers bounds the plants of a portfolio, their forecasts
+	// are fetched whenever its weather is queried.
+	portfolioMaxMembers = 500
+)
+
+type (
+	PortfolioUsecase interface {
+		// CreatePortfolio and UpdatePortfolio store the portfolio with its
+		// members, which replace the previous members on update.
+		CreatePortfolio(ctx context.Context, portfolio *model.Portfolio) (err error)
+		UpdatePortfolio(ctx context.Context, portfolio *model.Portfolio) (err error)
+		GetPortfolioByID(ctx context.Context, portfolioID string) (portfolio *model.Portfolio, err error)
+		GetPortfolios(ctx context.Context) (portfolios []*model.Portfolio, err error)
+		DeletePortfolio(ctx context.Context, portfolioID string) (err error)
+		// GetPortfolioPlants returns the plants of the members, in their order.
+		GetPortfolioPlants(ctx context.Context, portfolio *model.Portfolio) (powerplants []*model.PowerPlant, err error)
+		// AggregateForecasts aggregates the weather of portfolio from the
+		// hourly forecasts of its members, keyed by plant ID.
+		AggregateForecasts(portfolio *model.Portfolio, forecasts map[string][]*model.WeatherForecast) (weather *model.PortfolioWeather)
+	}
+
+	portfolioUsecase struct {
+		portfolioRepo  portfoliorepo.PortfolioRepository
+		powerplantRepo powerplantrepo.PowerPlantRepository
+		txManager      repository.TransactionManager
+	}
+
+	// portfolioHour sums the capacity-weighted forecasts of the members for
+	// one hour.
+	portfolioHour struct {
+		capacity      float64
+		temperature   float64
+		precipitation float64
+		windSpeed     float64
+	}
+)
+
+func NewPortfolioUsecase(portfolioRepo portfoliorepo.PortfolioRepository, powerplantRepo powerplantrepo.PowerPlantRepository, txManager repository.TransactionManager) PortfolioUsecase {
+	return &portfolioUsecase{
+		portfolioRepo:  portfolioRepo,
+		powerplantRepo: powerplantRepo,
+		txManager:      txManager,
+	}
+}
+
+func (u *portfolioUsecase) CreatePortfolio(ctx context.Context, portfolio *model.Portfolio) (err error) {
+	defer derrors.Wrap(&err, "CreatePortfolio(%q)", portfolio.Name)
+
+	_, err = auth.RequirePrincipal(ctx)
+	if err != nil {
+		return
+	}
+
+	err = u.validate(ctx, portfolio)
+	if err != nil {
+		return
+	}
+
+	err = u.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := u.portfolioRepo.CreatePortfolio(ctx, nil, portfolio)
+		if err != nil {
+			return err
+		}
+
+		return u.portfolioRepo.SetPortfolioMembers(ctx, nil, portfolio)
+	})
+
+	return
+}
+
+func (u *portfolioUsecase) UpdatePortfolio(ctx context.Context, portfolio *model.Portfolio) (err error) {
+	defer derrors.Wrap(&err, "UpdatePortfolio(%q)", portfolio.ID)
+
+	_, err = auth.RequirePrincipal(ctx)
+	if err != nil {
+		return
+	}
+
+	err = u.validate(ctx, portfolio)
+	if err != nil {
+		return
+	}
+
+	err = u.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := u.portfolioRepo.UpdatePortfolio(ctx, nil, portfolio)
+		if err != nil {
+			return err
+		}
+
+		return u.portfolioRepo.SetPortfolioMembers(ctx, nil, portfolio)
+	})
+
+	return
+}
+
+func (u *portfolioUsecase) GetPortfolioByID(ctx context.Context, portfolioID string) (portfolio *model.Portfolio, err error) {
+	defer derrors.Wrap(&err, "GetPortfolioByID(%q)", portfolioID)
+
+	portfolio, err = u.portfolioRepo.GetPortfolioByID(ctx, portfolioID)
+	return
+}
+
+func (u *portfolioUsecase) GetPortfolios(ctx context.Context) (portfolios []*model.Portfolio, err error) {
+	defer derrors.Wrap(&err, "GetPortfolios")
+
+	portfolios, err = u.portfolioRepo.GetPortfolios(ctx)
+	return
+}
+
+func (u *portfolioUsecase) DeletePortfolio(ctx context.Context, portfolioID string) (err error) {
+	defer derrors.Wrap(&err, "DeletePortfolio(%q)", portfolioID)
+
+	_, err = auth.RequirePrincipal(ctx)
+	if err != nil {
+		return
+	}
+
+	err = u.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		portfolio, err := u.portfolioRepo.GetPortfolioByID(ctx, portfolioID)
+		if err != nil {
+			return err
+		}
+		if portfolio == nil {
+			return derrors.New(derrors.NotFound, "portfolio not found")
+		}
+
+		return u.portfolioRepo.DeletePortfolio(ctx, nil, portfolioID)
+	})
+
+	return
+}
+
+func (u *portfolioUsecase) GetPortfolioPlants(ctx context.Context, portfolio *model.Portfolio) (powerplants []*model.PowerPlant, err error) {
+	defer derrors.Wrap(&err, "GetPortfolioPlants(%q)", portfolio.ID)
+
+	found, err := u.powerplantRepo.GetPowerPlantsByIDs(ctx, memberIDs(portfolio))
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*model.PowerPlant, len(found))
+	for _, powerplant := range found {
+		byID[powerplant.ID] = powerplant
+	}
+
+	powerplants = make([]*model.PowerPlant, 0, len(portfolio.Members))
+	for _, member := range portfolio.Members {
+		// A plant deleted since the portfolio was read has left it.
+		if powerplant, ok := byID[member.PlantID]; ok {
+			powerplants = append(powerplants, powerplant)
+		}
+	}
+
+	return powerplants, nil
+}
+
+// AggregateForecasts weighs the forecasts of the members by their capacity
+// hour by hour, an hour only forecast for some members is weighed among
+// those. MeanTemperature is the mean of the hourly temperatures,
+// TotalPrecipitation their sum and MaxWindSpeed the highest hourly wind speed.
+// Hours are matched by instant, so members in other timezones line up.
+func (u *portfolioUsecase) AggregateForecasts(portfolio *model.Portfolio, forecasts map[string][]*model.WeatherForecast) (weather *model.PortfolioWeather) {
+	weather = &model.PortfolioWeather{}
+
+	hours := make(map[int64]*portfolioHour)
+	for _, member := range portfolio.Members {
+		for _, forecast := range forecasts[member.PlantID] {
+			instant := forecast.Time.Time().Unix()
+			hour, ok := hours[instant]
+			if !ok {
+				hour = &portfolioHour{}
+				hours[instant] = hour
+			}
+
+			hour.capacity += member.CapacityMw
+			hour.temperature += member.CapacityMw * forecast.Temperature
+			hour.precipitation += member.CapacityMw * forecast.Precipitation
+			hour.windSpeed += member.CapacityMw * forecast.WindSpeed
+		}
+	}
+
+	var (
+		temperature, precipitation float64
+		windSpeed                  = math.Inf(-1)
+		count                      int
+	)
+	for _, hour := range hours {
+		if hour.capacity == 0 {
+			continue
+		}
+		temperature += hour.temperature / hour.capacity
+		precipitation += hour.precipitation / hour.capacity
+		windSpeed = math.Max(windSpeed, hour.windSpeed/hour.capacity)
+		count++
+	}
+	if count == 0 {
+		return weather
+	}
+
+	temperature /= float64(count)
+	weather.MeanTemperature = &temperature
+	weather.TotalPrecipitation = &precipitation
+	weather.MaxWindSpeed = &windSpeed
+	return weather
+}
+
+// validate checks the fields and members of portfolio and makes sure no other
+// portfolio already uses its name.
+func (u *portfolioUsecase) validate(ctx context.Context, portfolio *model.Portfolio) error {
+	fields := validatePortfolioFields(portfolio)
+	if len(fields) > 0 {
+		return derrors.NewWithFields(derrors.InvalidArgument, fields, "invalid portfolio")
+	}
+
+	existing, err := u.portfolioRepo.GetPortfolioByName(ctx, portfolio.Name)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != portfolio.ID {
+		return derrors.NewWithFields(derrors.Duplicate, []derrors.FieldError{
+			{Field: "name", Message: "a portfolio with this name already exists"},
+		}, "duplicate portfolio name %q", portfolio.Name)
+	}
+
+	if len(portfolio.Members) == 0 {
+		return nil
+	}
+
+	found, err := u.powerplantRepo.GetPowerPlantsByIDs(ctx, memberIDs(portfolio))
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]bool, len(found))
+	for _, powerplant := range found {
+		known[powerplant.ID] = true
+	}
+	for i, member := range portfolio.Members {
+		if !known[member.PlantID] {
+			fields = append(fields, derrors.FieldError{Field: memberField(i, "plantId"), Message: "power plant " + member.PlantID + " not found"})
+		}
+	}
+	if len(fields) > 0 {
+		return derrors.NewWithFields(derrors.NotFound, fields, "unknown portfolio members")
+	}
+
+	return nil
+}
+
+func validatePortfolioFields(portfolio *model.Portfolio) (fields []derrors.FieldError) {
+	switch {
+	case strings.TrimSpace(portfolio.Name) == "":
+		fields = append(fields, derrors.FieldError{Field: "name", Message: "name is required"})
+	case !govalidator.RuneLength(portfolio.Name, portfolioNameMinLength, portfolioNameMaxLength):
+		fields = append(fields, derrors.FieldError{Field: "name", Message: "name must be at most " + portfolioNameMaxLength + " characters"})
+	}
+
+	if len(portfolio.Members) > portfolioMaxMembers {
+		fields = append(fields, derrors.FieldError{Field: "members", Message: "members must have at most " + strconv.Itoa(portfolioMaxMembers) + " plants"})
+		return fields
+	}
+
+	seen := make(map[string]int)
+	for i, member := range portfolio.Members {
+		if j, ok := seen[member.PlantID]; ok {
+			fields = append(fields, derrors.FieldError{Field: memberField(i, "plantId"), Message: "power plant is already member " + strconv.Itoa(j)})
+		} else {
+			seen[member.PlantID] = i
+		}
+
+		if !(member.CapacityMw > 0) || math.IsInf(member.CapacityMw, 0) {
+			fields = append(fields, derrors.FieldError{Field: memberField(i, "capacityMw"), Message: "capacityMw must be greater than 0"})
+		}
+	}
+
+	return fields
+}
+
+// memberIDs returns the plant IDs of the members of portfolio.
+func memberIDs(portfolio *model.Portfolio) []string {
+	ids := make([]string, 0, len(portfolio.Members))
+	for _, member := range portfolio.Members {
+		ids = append(ids, member.PlantID)
+	}
+	return ids
+}
+
+// memberField names a field of the member at index i, e.g. members.2.plantId.
+func memberField(i int, field string) string {
+	return "members." + strconv.Itoa(i) + "." + field
+}
@@ -0,0 +1,354 @@
+package portfoliousecase_test
+
+import (
+	"context"
+	"strings"
+	"tensor-graphql/internal/auth"
+	"tensor-graphql/internal/model"
+	"tensor-graphql/internal/test"
+	portfoliousecase "tensor-graphql/internal/usecase/portfolio"
+	"tensor-graphql/pkg/datatype"
+	"tensor-graphql/pkg/derrors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// withinTransaction stands in for TransactionManager.WithinTransaction and
+// runs the unit of work without a database.
+func withinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestCreatePortfolio(t *testing.T) {
+	mc := test.InitMockComponent(t)
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "operator"})
+	testUsecase := portfoliousecase.NewPortfolioUsecase(mc.PortfolioRepository, mc.PowerPlantRepository, mc.TransactionManager)
+
+	var testCases = []struct {
+		caseName     string
+		portfolio    *model.Portfolio
+		expectations func(portfolio *model.Portfolio)
+		results      func(portfolio *model.Portfolio, err error)
+	}{
+		{
+			caseName: "CreatePortfolio_Success",
+			portfolio: &model.Portfolio{
+				Name: "North Sea wind",
+				Members: []*model.PortfolioMember{
+					{PlantID: "1", CapacityMw: 400},
+					{PlantID: "2", CapacityMw: 250.5},
+				},
+			},
+			expectations: func(portfolio *model.Portfolio) {
+				mc.PortfolioRepository.On("GetPortfolioByName", mock.Anything, "North Sea wind").Return(nil, nil).Once()
+				mc.PowerPlantRepository.On("GetPowerPlantsByIDs", mock.Anything, []string{"1", "2"}).
+					Return([]*model.PowerPlant{{ID: "1"}, {ID: "2"}}, nil).Once()
+				mc.TransactionManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(withinTransaction).Once()
+				mc.PortfolioRepository.On("CreatePortfolio", mock.Anything, mock.Anything, portfolio).
+					Run(func(args mock.Arguments) {
+						args.Get(2).(*model.Portfolio).ID = "10"
+					}).
+					Return(nil).Once()
+				mc.PortfolioRepository.On("SetPortfolioMembers", mock.Anything, mock.Anything, portfolio).Return(nil).Once()
+			},
+			results: func(portfolio *model.Portfolio, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, "10", portfolio.ID)
+				assert.Equal(t, 650.5, portfolio.CapacityMw())
+			},
+		},
+		{
+			caseName: "CreatePortfolio_InvalidFields",
+			portfolio: &model.Portfolio{
+				Name: " ",
+				Members: []*model.PortfolioMember{
+					{PlantID: "1", CapacityMw: 400},
+					{PlantID: "1", CapacityMw: 0},
+				},
+			},
+			expectations: func(portfolio *model.Portfolio) {},
+			results: func(portfolio *model.Portfolio, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+				assert.Equal(t, []derrors.FieldError{
+					{Field: "name", Message: "name is required"},
+					{Field: "members.1.plantId", Message: "power plant is already member 0"},
+					{Field: "members.1.capacityMw", Message: "capacityMw must be greater than 0"},
+				}, derrors.FieldsOf(err))
+			},
+		},
+		{
+			caseName: "CreatePortfolio_TooLongName",
+			portfolio: &model.Portfolio{
+				Name: strings.Repeat("a", 256),
+			},
+			expectations: func(portfolio *model.Portfolio) {},
+			results: func(portfolio *model.Portfolio, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+				assert.Equal(t, []derrors.FieldError{
+					{Field: "name", Message: "name must be at most 255 characters"},
+				}, derrors.FieldsOf(err))
+			},
+		},
+		{
+			caseName: "CreatePortfolio_DuplicateName",
+			portfolio: &model.Portfolio{
+				Name: "Iberia PV",
+			},
+			expectations: func(portfolio *model.Portfolio) {
+				mc.PortfolioRepository.On("GetPortfolioByName", mock.Anything, "Iberia PV").
+					Return(&model.Portfolio{ID: "3", Name: "Iberia PV"}, nil).Once()
+			},
+			results: func(portfolio *model.Portfolio, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.Duplicate))
+			},
+		},
+		{
+			caseName: "CreatePortfolio_UnknownPlant",
+			portfolio: &model.Portfolio{
+				Name: "Baltic wind",
+				Members: []*model.PortfolioMember{
+					{PlantID: "1", CapacityMw: 400},
+					{PlantID: "9", CapacityMw: 100},
+				},
+			},
+			expectations: func(portfolio *model.Portfolio) {
+				mc.PortfolioRepository.On("GetPortfolioByName", mock.Anything, "Baltic wind").Return(nil, nil).Once()
+				mc.PowerPlantRepository.On("GetPowerPlantsByIDs", mock.Anything, []string{"1", "9"}).
+					Return([]*model.PowerPlant{{ID: "1"}}, nil).Once()
+			},
+			results: func(portfolio *model.Portfolio, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.NotFound))
+				assert.Equal(t, []derrors.FieldError{
+					{Field: "members.1.plantId", Message: "power plant 9 not found"},
+				}, derrors.FieldsOf(err))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			testCase.expectations(testCase.portfolio)
+			err := testUsecase.CreatePortfolio(ctx, testCase.portfolio)
+			testCase.results(testCase.portfolio, err)
+		})
+	}
+}
+
+func TestUpdatePortfolio(t *testing.T) {
+	mc := test.InitMockComponent(t)
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "operator"})
+	testUsecase := portfoliousecase.NewPortfolioUsecase(mc.PortfolioRepository, mc.PowerPlantRepository, mc.TransactionManager)
+
+	var testCases = []struct {
+		caseName     string
+		portfolio    *model.Portfolio
+		expectations func(portfolio *model.Portfolio)
+		results      func(err error)
+	}{
+		{
+			caseName: "UpdatePortfolio_Success",
+			portfolio: &model.Portfolio{
+				ID:      "10",
+				Name:    "North Sea wind",
+				Members: []*model.PortfolioMember{{PlantID: "2", CapacityMw: 250}},
+			},
+			expectations: func(portfolio *model.Portfolio) {
+				// The portfolio keeps its own name.
+				mc.PortfolioRepository.On("GetPortfolioByName", mock.Anything, "North Sea wind").
+					Return(&model.Portfolio{ID: "10", Name: "North Sea wind"}, nil).Once()
+				mc.PowerPlantRepository.On("GetPowerPlantsByIDs", mock.Anything, []string{"2"}).
+					Return([]*model.PowerPlant{{ID: "2"}}, nil).Once()
+				mc.TransactionManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(withinTransaction).Once()
+				mc.PortfolioRepository.On("UpdatePortfolio", mock.Anything, mock.Anything, portfolio).Return(nil).Once()
+				mc.PortfolioRepository.On("SetPortfolioMembers", mock.Anything, mock.Anything, portfolio).Return(nil).Once()
+			},
+			results: func(err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			caseName: "UpdatePortfolio_NotFound",
+			portfolio: &model.Portfolio{
+				ID:   "11",
+				Name: "Iberia PV",
+			},
+			expectations: func(portfolio *model.Portfolio) {
+				mc.PortfolioRepository.On("GetPortfolioByName", mock.Anything, "Iberia PV").Return(nil, nil).Once()
+				mc.TransactionManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(withinTransaction).Once()
+				mc.PortfolioRepository.On("UpdatePortfolio", mock.Anything, mock.Anything, portfolio).
+					Return(derrors.New(derrors.NotFound, "portfolio not found")).Once()
+			},
+			results: func(err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.NotFound))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			testCase.expectations(testCase.portfolio)
+			err := testUsecase.UpdatePortfolio(ctx, testCase.portfolio)
+			testCase.results(err)
+		})
+	}
+}
+
+func TestDeletePortfolio(t *testing.T) {
+	mc := test.InitMockComponent(t)
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "admin"})
+	testUsecase := portfoliousecase.NewPortfolioUsecase(mc.PortfolioRepository, mc.PowerPlantRepository, mc.TransactionManager)
+
+	var testCases = []struct {
+		caseName     string
+		id           string
+		expectations func(id string)
+		results      func(err error)
+	}{
+		{
+			caseName: "DeletePortfolio_Success",
+			id:       "10",
+			expectations: func(id string) {
+				mc.TransactionManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(withinTransaction).Once()
+				mc.PortfolioRepository.On("GetPortfolioByID", mock.Anything, id).Return(&model.Portfolio{ID: id}, nil).Once()
+				mc.PortfolioRepository.On("DeletePortfolio", mock.Anything, mock.Anything, id).Return(nil).Once()
+			},
+			results: func(err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			caseName: "DeletePortfolio_NotFound",
+			id:       "11",
+			expectations: func(id string) {
+				mc.TransactionManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(withinTransaction).Once()
+				mc.PortfolioRepository.On("GetPortfolioByID", mock.Anything, id).Return(nil, nil).Once()
+			},
+			results: func(err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.NotFound))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			testCase.expectations(testCase.id)
+			err := testUsecase.DeletePortfolio(ctx, testCase.id)
+			testCase.results(err)
+		})
+	}
+}
+
+func TestGetPortfolioPlants(t *testing.T) {
+	mc := test.InitMockComponent(t)
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "viewer"})
+	testUsecase := portfoliousecase.NewPortfolioUsecase(mc.PortfolioRepository, mc.PowerPlantRepository, mc.TransactionManager)
+
+	// The plants are read in one query, ordered by ID.
+	mc.PowerPlantRepository.On("GetPowerPlantsByIDs", mock.Anything, []string{"3", "2", "10"}).
+		Return([]*model.PowerPlant{{ID: "3"}, {ID: "10"}}, nil).Once()
+
+	// Plant 2 was deleted after the portfolio was read.
+	plants, err := testUsecase.GetPortfolioPlants(ctx, &model.Portfolio{
+		ID: "10",
+		Members: []*model.PortfolioMember{
+			{PlantID: "3", CapacityMw: 100},
+			{PlantID: "2", CapacityMw: 100},
+			{PlantID: "10", CapacityMw: 100},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []*model.PowerPlant{{ID: "3"}, {ID: "10"}}, plants)
+}
+
+func TestAggregateForecasts(t *testing.T) {
+	mc := test.InitMockComponent(t)
+	testUsecase := portfoliousecase.NewPortfolioUsecase(mc.PortfolioRepository, mc.PowerPlantRepository, mc.TransactionManager)
+
+	forecast := func(at string, temperature, precipitation, windSpeed float64) *model.WeatherForecast {
+		forecastTime, err := datatype.ParseTime(at)
+		assert.NoError(t, err)
+		return &model.WeatherForecast{
+			Time:          forecastTime,
+			Temperature:   temperature,
+			Precipitation: precipitation,
+			WindSpeed:     windSpeed,
+		}
+	}
+
+	var testCases = []struct {
+		caseName  string
+		members   []*model.PortfolioMember
+		forecasts map[string][]*model.WeatherForecast
+		results   func(weather *model.PortfolioWeather)
+	}{
+		{
+			caseName: "AggregateForecasts_CapacityWeighted",
+			members: []*model.PortfolioMember{
+				{PlantID: "1", CapacityMw: 300},
+				{PlantID: "2", CapacityMw: 100},
+			},
+			forecasts: map[string][]*model.WeatherForecast{
+				// The same hours in different timezones.
+				"1": {
+					forecast("2025-03-04T08:00:00+01:00", 10, 1, 20),
+					forecast("2025-03-04T09:00:00+01:00", 12, 0, 40),
+				},
+				"2": {
+					forecast("2025-03-04T07:00:00Z", 2, 5, 60),
+					forecast("2025-03-04T08:00:00Z", 4, 0, 0),
+				},
+			},
+			results: func(weather *model.PortfolioWeather) {
+				// Hour 1: temperature 8, precipitation 2, wind 30.
+				// Hour 2: temperature 10, precipitation 0, wind 30.
+				assert.InDelta(t, 9, *weather.MeanTemperature, 1e-9)
+				assert.InDelta(t, 2, *weather.TotalPrecipitation, 1e-9)
+				assert.InDelta(t, 30, *weather.MaxWindSpeed, 1e-9)
+			},
+		},
+		{
+			caseName: "AggregateForecasts_PartialHour",
+			members: []*model.PortfolioMember{
+				{PlantID: "1", CapacityMw: 300},
+				{PlantID: "2", CapacityMw: 100},
+			},
+			forecasts: map[string][]*model.WeatherForecast{
+				"1": {
+					forecast("2025-03-04T07:00:00Z", 10, 1, 20),
+				},
+				"2": {
+					forecast("2025-03-04T07:00:00Z", 2, 5, 60),
+					forecast("2025-03-04T08:00:00Z", 4, 0.5, 70),
+				},
+			},
+			results: func(weather *model.PortfolioWeather) {
+				// The second hour is only forecast for plant 2.
+				assert.InDelta(t, 6, *weather.MeanTemperature, 1e-9)
+				assert.InDelta(t, 2.5, *weather.TotalPrecipitation, 1e-9)
+				assert.InDelta(t, 70, *weather.MaxWindSpeed, 1e-9)
+			},
+		},
+		{
+			caseName: "AggregateForecasts_NoMembers",
+			forecasts: map[string][]*model.WeatherForecast{
+				"1": {
+					forecast("2025-03-04T07:00:00Z", 10, 1, 20),
+				},
+			},
+			results: func(weather *model.PortfolioWeather) {
+				assert.Nil(t, weather.MeanTemperature)
+				assert.Nil(t, weather.TotalPrecipitation)
+				assert.Nil(t, weather.MaxWindSpeed)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			portfolio := &model.Portfolio{ID: "10", Members: testCase.members}
+			testCase.results(testUsecase.AggregateForecasts(portfolio, testCase.forecasts))
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package powerplantusecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"tensor-graphql/internal/auth"
+	"tensor-graphql/internal/model"
+	powerplantrepo "tensor-graphql/internal/repository/power_plant"
+	"tensor-graphql/pkg/derrors"
+)
+
+// batchMaxItems bounds the items of a single batch mutation.
+const batchMaxItems = 100
+
+func (u *powerplantUsecase) CreatePowerPlants(ctx context.Context, powerplants []*model.PowerPlant) (batch *model.PowerPlantBatch, err error) {
+	defer derrors.Wrap(&err, "CreatePowerPlants(%d)", len(powerplants))
+
+	_, err = auth.RequirePrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateBatchSize(len(powerplants))
+	if err != nil {
+		return nil, err
+	}
+
+	batch = newPowerPlantBatch(len(powerplants))
+	names := make(map[string]string)
+	for i, powerplant := range powerplants {
+		batch.Items[i].Errors, err = u.validateUnique(ctx, powerplant, names, fmt.Sprintf("item %d", i))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if batchFailed(batch) {
+		return batch, nil
+	}
+
+	err = u.detectTimezones(ctx, powerplants)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := u.powerplantRepo.CreatePowerPlants(ctx, nil, powerplants)
+		if err != nil {
+			return err
+		}
+
+		for _, powerplant := range powerplants {
+			err = u.audit(ctx, model.AuditActionCreate, nil, powerplant)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if itemFailed(batch, err) {
+		return batch, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	batchSucceeded(batch, powerplants)
+	return batch, nil
+}
+
+func (u *powerplantUsecase) UpdatePowerPlants(ctx context.Context, updates []*model.UpdatePowerPlantInput) (batch *model.PowerPlantBatch, err error) {
+	defer derrors.Wrap(&err, "UpdatePowerPlants(%d)", len(updates))
+
+	_, err = auth.RequirePrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateBatchSize(len(updates))
+	if err != nil {
+		return nil, err
+	}
+
+	batch = newPowerPlantBatch(len(updates))
+	befores := make([]*model.PowerPlant, len(updates))
+	powerplants := make([]*model.PowerPlant, len(updates))
+	names := make(map[string]string)
+	items := make(map[string]int)
+	for i, update := range updates {
+		item := batch.Items[i]
+		if j, ok := items[update.ID]; ok {
+			item.Errors = append(item.Errors, newFieldError("id", fmt.Sprintf("power plant is already updated by item %d", j)))
+			continue
+		}
+		items[update.ID] = i
+
+		before, err := u.powerplantRepo.GetPowerPlantByID(ctx, update.ID)
+		if err != nil {
+			return nil, err
+		}
+		if before == nil {
+			item.Errors = append(item.Errors, newFieldError("id", "power plant not found"))
+			continue
+		}
+		if before.Version != update.Version {
+			item.Errors = append(item.Errors, newFieldError("version", fmt.Sprintf("power plant is at version %d, not %d", before.Version, update.Version)))
+			continue
+		}
+
+		powerplant := *before
+		applyUpdate(&powerplant, update)
+		item.Errors, err = u.validateUnique(ctx, &powerplant, names, fmt.Sprintf("item %d", i))
+		if err != nil {
+			return nil, err
+		}
+
+		befores[i] = before
+		powerplants[i] = &powerplant
+	}
+	if batchFailed(batch) {
+		return batch, nil
+	}
+
+	err = u.detectTimezones(ctx, powerplants)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := u.powerplantRepo.UpdatePowerPlants(ctx, nil, powerplants)
+		if err != nil {
+			return err
+		}
+
+		for i, powerplant := range powerplants {
+			err = u.audit(ctx, model.AuditActionUpdate, befores[i], powerplant)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	// A plant changed by someone else since it was read fails its item.
+	var itemErr *powerplantrepo.ItemError
+	if errors.As(err, &itemErr) && derrors.IsErrCode(err, derrors.Conflict) {
+		item := batch.Items[itemErr.Index]
+		item.Errors = append(item.Errors, newFieldError("version", "power plant was changed since version "+strconv.Itoa(updates[itemErr.Index].Version)))
+		batchFailed(batch)
+		return batch, nil
+	}
+	if itemFailed(batch, err) {
+		return batch, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	batchSucceeded(batch, powerplants)
+	return batch, nil
+}
+
+// applyUpdate sets the given fields of update on powerplant, the timezone is
+// detected again when the plant moves.
+func applyUpdate(powerplant *model.PowerPlant, update *model.UpdatePowerPlantInput) {
+	if update.Name != nil {
+		powerplant.Name = *update.Name
+	}
+	if update.Latitude != nil && *update.Latitude != powerplant.Latitude {
+		powerplant.Latitude = *update.Latitude
+		powerplant.Timezone = ""
+	}
+	if update.Longitude != nil && *update.Longitude != powerplant.Longitude {
+		powerplant.Longitude = *update.Longitude
+		powerplant.Timezone = ""
+	}
+}
+
+func validateBatchSize(size int) error {
+	if size < 1 || size > batchMaxItems {
+		return derrors.NewWithFields(derrors.InvalidArgument, []derrors.FieldError{
+			{Field: "input", Message: "input must have between 1 and " + strconv.Itoa(batchMaxItems) + " items"},
+		}, "invalid batch of %d items", size)
+	}
+	return nil
+}
+
+func newPowerPlantBatch(size int) *model.PowerPlantBatch {
+	batch := &model.PowerPlantBatch{
+		Items: make([]*model.PowerPlantBatchItem, size),
+	}
+	for i := range batch.Items {
+		batch.Items[i] = &model.PowerPlantBatchItem{
+			Index:  i,
+			Errors: []*model.FieldError{},
+		}
+	}
+	return batch
+}
+
+// batchFailed reports whether an item of batch has errors, the whole batch
+// is then marked as failed.
+func batchFailed(batch *model.PowerPlantBatch) bool {
+	for _, item := range batch.Items {
+		if len(item.Errors) > 0 {
+			batch.Success = false
+			return true
+		}
+	}
+	return false
+}
+
+// itemFailed records every *powerplantrepo.ItemError of err on its item, like
+// a validation error, and marks the batch as failed. Errors of the database
+// itself are not the fault of an item and are left to the caller.
+func itemFailed(batch *model.PowerPlantBatch, err error) bool {
+	itemErrs := powerplantrepo.ItemErrorsOf(err)
+	if len(itemErrs) == 0 {
+		return false
+	}
+	for _, itemErr := range itemErrs {
+		var codeErr *derrors.Error
+		if !errors.As(itemErr.Err, &codeErr) || codeErr.Code() == derrors.Unknown {
+			return false
+		}
+	}
+
+	for _, itemErr := range itemErrs {
+		item := batch.Items[itemErr.Index]
+		fields := derrors.FieldsOf(itemErr.Err)
+		for _, field := range fields {
+			item.Errors = append(item.Errors, newFieldError(field.Field, field.Message))
+		}
+		if len(fields) == 0 {
+			item.Errors = append(item.Errors, newFieldError("", itemErr.Err.Error()))
+		}
+	}
+
+	return batchFailed(batch)
+}
+
+func batchSucceeded(batch *model.PowerPlantBatch, powerplants []*model.PowerPlant) {
+	batch.Success = true
+	for i, item := range batch.Items {
+		item.Success = true
+		item.Plant = powerplants[i]
+	}
+}
@@ -0,0 +1,278 @@
+package powerplantusecase
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"tensor-graphql/internal/auth"
+	"tensor-graphql/internal/model"
+	powerplantrepo "tensor-graphql/internal/repository/power_plant"
+	"tensor-graphql/pkg/derrors"
+)
+
+// importMaxRows bounds the rows of a single import, larger fleets are split
+// into several files.
+const importMaxRows = 1000
+
+// importColumns are the columns of an import file, the header row names them
+// in any order.
+var importColumns = []struct {
+	name     string
+	required bool
+}{
+	{name: "name", required: true},
+	{name: "latitude", required: true},
+	{name: "longitude", required: true},
+	{name: "timezone"},
+}
+
+// importRow is a row of an import file with the plant it describes.
+type importRow struct {
+	result     *model.PowerPlantImportRow
+	powerplant *model.PowerPlant
+}
+
+func (u *powerplantUsecase) ImportPowerPlants(ctx context.Context, file io.Reader, dryRun bool) (result *model.PowerPlantImport, err error) {
+	defer derrors.Wrap(&err, "ImportPowerPlants(dryRun=%v)", dryRun)
+
+	_, err = auth.RequirePrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := readImportRows(file)
+	if err != nil {
+		return nil, err
+	}
+
+	result = &model.PowerPlantImport{
+		DryRun: dryRun,
+		Rows:   make([]*model.PowerPlantImportRow, 0, len(rows)),
+	}
+
+	var valid []*importRow
+	names := make(map[string]string)
+	for _, row := range rows {
+		result.Rows = append(result.Rows, row.result)
+		if len(row.result.Errors) == 0 {
+			err = u.validateImportRow(ctx, row, names)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if len(row.result.Errors) > 0 {
+			result.ErrorCount++
+			continue
+		}
+		result.ValidCount++
+		valid = append(valid, row)
+	}
+
+	if dryRun || len(valid) == 0 {
+		return result, nil
+	}
+
+	err = u.detectTimezones(ctx, importPowerPlants(valid))
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.createImportRows(ctx, valid)
+	if taken := takenImportRows(valid, err); len(taken) > 0 {
+		// Plants of the same names were created since the file was checked,
+		// the rows are skipped like the rows that failed validation and the
+		// others are created without them. A name taken again meanwhile fails
+		// the import.
+		for _, row := range taken {
+			row.result.Errors = append(row.result.Errors, newFieldError("name", "a power plant with this name already exists"))
+			result.ValidCount--
+			result.ErrorCount++
+		}
+		valid = slices.DeleteFunc(valid, func(row *importRow) bool {
+			return slices.Contains(taken, row)
+		})
+		err = u.createImportRows(ctx, valid)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range valid {
+		id := row.powerplant.ID
+		row.result.ID = &id
+	}
+
+	return result, nil
+}
+
+// createImportRows creates the plants of rows with a single insert in a
+// transaction, either all of them or, when the database rejects one, none.
+func (u *powerplantUsecase) createImportRows(ctx context.Context, rows []*importRow) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	powerplants := importPowerPlants(rows)
+	return u.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := u.powerplantRepo.CreatePowerPlants(ctx, nil, powerplants)
+		if err != nil {
+			return err
+		}
+
+		for _, powerplant := range powerplants {
+			err = u.audit(ctx, model.AuditActionCreate, nil, powerplant)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// takenImportRows returns the rows whose names the unique key rejected when
+// err reports only such rows.
+func takenImportRows(rows []*importRow, err error) (taken []*importRow) {
+	for _, itemErr := range powerplantrepo.ItemErrorsOf(err) {
+		if !derrors.IsErrCode(itemErr.Err, derrors.Duplicate) {
+			return nil
+		}
+		taken = append(taken, rows[itemErr.Index])
+	}
+	return taken
+}
+
+// importPowerPlants returns the plants of rows.
+func importPowerPlants(rows []*importRow) []*model.PowerPlant {
+	powerplants := make([]*model.PowerPlant, 0, len(rows))
+	for _, row := range rows {
+		powerplants = append(powerplants, row.powerplant)
+	}
+	return powerplants
+}
+
+// validateImportRow checks the plant of row like CreatePowerPlant does and
+// that no earlier row of the file, recorded in names, uses its name.
+func (u *powerplantUsecase) validateImportRow(ctx context.Context, row *importRow, names map[string]string) (err error) {
+	row.result.Errors, err = u.validateUnique(ctx, row.powerplant, names, fmt.Sprintf("line %d", row.result.Line))
+	return err
+}
+
+// readImportRows parses the CSV file, the errors of values that cannot be
+// read are recorded on their row.
+func readImportRows(file io.Reader) ([]*importRow, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, importFileError("the file is empty")
+	}
+	if err != nil {
+		return nil, importFileError(fmt.Sprintf("the header cannot be read: %v", err))
+	}
+
+	columns, err := importColumnIndexes(header)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []*importRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			rows = append(rows, &importRow{
+				result: &model.PowerPlantImportRow{
+					Line:   parseErr.Line,
+					Errors: []*model.FieldError{newFieldError("", parseErr.Err.Error())},
+				},
+			})
+		} else if err != nil {
+			return nil, derrors.WrapStack(err, derrors.Unknown, "reader.Read")
+		} else {
+			line, _ := reader.FieldPos(0)
+			rows = append(rows, parseImportRow(record, line, columns))
+		}
+
+		if len(rows) > importMaxRows {
+			return nil, importFileError(fmt.Sprintf("the file must have at most %d rows", importMaxRows))
+		}
+	}
+
+	return rows, nil
+}
+
+// importColumnIndexes returns the index of every column named by header.
+func importColumnIndexes(header []string) (map[string]int, error) {
+	indexes := make(map[string]int)
+	for i, name := range header {
+		if i == 0 {
+			// Spreadsheet programs start UTF-8 files with a byte order mark.
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		indexes[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	var missing []string
+	for _, column := range importColumns {
+		if _, ok := indexes[column.name]; column.required && !ok {
+			missing = append(missing, column.name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, importFileError("the header misses the columns " + strings.Join(missing, ", "))
+	}
+
+	return indexes, nil
+}
+
+func parseImportRow(record []string, line int, columns map[string]int) *importRow {
+	row := &importRow{
+		result: &model.PowerPlantImportRow{
+			Line:   line,
+			Errors: []*model.FieldError{},
+		},
+		powerplant: &model.PowerPlant{},
+	}
+
+	value := func(column string) string {
+		i, ok := columns[column]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	coordinate := func(column string) float64 {
+		coordinate, err := strconv.ParseFloat(value(column), 64)
+		if err != nil {
+			row.result.Errors = append(row.result.Errors, newFieldError(column, column+" must be a number"))
+		}
+		return coordinate
+	}
+
+	row.powerplant.Name = value("name")
+	row.powerplant.Latitude = coordinate("latitude")
+	row.powerplant.Longitude = coordinate("longitude")
+	row.powerplant.Timezone = value("timezone")
+	row.result.Name = row.powerplant.Name
+
+	return row
+}
+
+// importFileError rejects an import file as a whole.
+func importFileError(message string) error {
+	return derrors.NewWithFields(derrors.InvalidArgument, []derrors.FieldError{
+		{Field: "csv", Message: message},
+	}, "invalid import file: %s", message)
+}
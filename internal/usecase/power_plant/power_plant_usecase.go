@@ -2,34 +2,141 @@ package powerplantusecase
 
 import (
 	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"tensor-graphql/infrastructure/config"
+	"tensor-graphql/internal/auth"
 	"tensor-graphql/internal/model"
+	auditeventrepo "tensor-graphql/internal/repository/audit_event"
+	repository "tensor-graphql/internal/repository/common"
 	powerplantrepo "tensor-graphql/internal/repository/power_plant"
+	"tensor-graphql/pkg/datatype"
 	"tensor-graphql/pkg/derrors"
+	"tensor-graphql/pkg/geo"
+	"tensor-graphql/pkg/requestid"
+)
+
+const (
+	// auditEntityType is the entity type of power plants in the audit trail.
+	auditEntityType = "power_plant"
+
+	// geoJSONMaxPlants bounds the plants of a GeoJSON document that is not
+	// streamed.
+	geoJSONMaxPlants = 1000
+
+	// timezoneConcurrency bounds the timezones detected at once for a batch
+	// or an import.
+	timezoneConcurrency = 8
 )
 
 type (
 	PowerPlantUsecase interface {
 		CreatePowerPlant(ctx context.Context, powerplant *model.PowerPlant) (err error)
+		// CreatePowerPlants and UpdatePowerPlants apply every item of a batch in
+		// one transaction, or none of them when one fails. The outcome of the
+		// items is reported in the batch.
+		CreatePowerPlants(ctx context.Context, powerplants []*model.PowerPlant) (batch *model.PowerPlantBatch, err error)
+		UpdatePowerPlants(ctx context.Context, updates []*model.UpdatePowerPlantInput) (batch *model.PowerPlantBatch, err error)
 		GetPowerPlantByID(ctx context.Context, powerplantID string) (powerplant *model.PowerPlant, err error)
 		GetPowerPlants(ctx context.Context, page, limit int) (powerplants []*model.PowerPlant, total int, err error)
+		GetNearbyPowerPlants(ctx context.Context, latitude, longitude, radiusKm float64, limit int) (powerplants []*model.PowerPlant, err error)
+		// GetPowerPlantsInBounds and GetPowerPlantsInPolygon list the plants of a
+		// map region, or cluster them when there are more than the configured
+		// maximum.
+		GetPowerPlantsInBounds(ctx context.Context, bounds geo.Bounds) (region *model.PowerPlantRegion, err error)
+		GetPowerPlantsInPolygon(ctx context.Context, geojson string) (region *model.PowerPlantRegion, err error)
+		// ExportPowerPlantsGeoJSON writes every plant to w as a GeoJSON
+		// FeatureCollection while they are read from the repository.
+		ExportPowerPlantsGeoJSON(ctx context.Context, w io.Writer) (err error)
+		// GetPowerPlantsGeoJSON returns the FeatureCollection of
+		// ExportPowerPlantsGeoJSON as one document. It fails for fleets of more
+		// than geoJSONMaxPlants plants, which are exported instead.
+		GetPowerPlantsGeoJSON(ctx context.Context) (document string, err error)
+		// ImportPowerPlants creates the plants of a CSV file in one transaction,
+		// rows with errors are reported and skipped. A dry run only validates.
+		ImportPowerPlants(ctx context.Context, file io.Reader, dryRun bool) (result *model.PowerPlantImport, err error)
 		UpdatePowerPlant(ctx context.Context, powerplant *model.PowerPlant) (err error)
+		DeletePowerPlant(ctx context.Context, powerplantID string) (err error)
+		GetAuditTrail(ctx context.Context, powerplantID string) (events []*model.AuditEvent, err error)
+	}
+
+	// TimezoneDetector resolves the IANA timezone of a location.
+	TimezoneDetector interface {
+		DetectTimezone(ctx context.Context, latitude, longitude float64) (timezone string, err error)
 	}
 
 	powerplantUsecase struct {
-		powerplantRepo powerplantrepo.PowerPlantRepository
+		powerplantRepo   powerplantrepo.PowerPlantRepository
+		auditEventRepo   auditeventrepo.AuditEventRepository
+		txManager        repository.TransactionManager
+		timezoneDetector TimezoneDetector
+		mapConf          *config.Map
+		validator        *powerplantValidator
+	}
+
+	// powerplantFeatureProperties are the properties of a plant in a GeoJSON
+	// export.
+	powerplantFeatureProperties struct {
+		Name      string        `json:"name"`
+		Latitude  float64       `json:"latitude"`
+		Longitude float64       `json:"longitude"`
+		Timezone  string        `json:"timezone"`
+		Version   int           `json:"version"`
+		CreatedAt datatype.Time `json:"createdAt"`
+		UpdatedAt datatype.Time `json:"updatedAt"`
+	}
+
+	// powerplantSnapshot is the state of a power plant recorded in the audit
+	// trail, it leaves out the fields derived from the weather forecast.
+	powerplantSnapshot struct {
+		ID        string  `json:"id"`
+		Name      string  `json:"name"`
+		Latitude  float64 `json:"latitude"`
+		Longitude float64 `json:"longitude"`
+		Timezone  string  `json:"timezone"`
+		Version   int     `json:"version"`
 	}
 )
 
-func NewPowerPlantUsecase(powerplantRepo powerplantrepo.PowerPlantRepository) PowerPlantUsecase {
+func NewPowerPlantUsecase(powerplantRepo powerplantrepo.PowerPlantRepository, auditEventRepo auditeventrepo.AuditEventRepository, txManager repository.TransactionManager, timezoneDetector TimezoneDetector, mapConf *config.Map) PowerPlantUsecase {
 	return &powerplantUsecase{
-		powerplantRepo: powerplantRepo,
+		powerplantRepo:   powerplantRepo,
+		auditEventRepo:   auditEventRepo,
+		txManager:        txManager,
+		timezoneDetector: timezoneDetector,
+		mapConf:          mapConf,
+		validator:        newPowerPlantValidator(powerplantRepo),
 	}
 }
 
 func (u *powerplantUsecase) CreatePowerPlant(ctx context.Context, powerplant *model.PowerPlant) (err error) {
 	defer derrors.Wrap(&err, "CreatePowerPlant(%q)", powerplant.Name)
 
-	err = u.powerplantRepo.CreatePowerPlant(ctx, nil, powerplant)
+	_, err = auth.RequirePrincipal(ctx)
+	if err != nil {
+		return
+	}
+
+	err = u.validator.Validate(ctx, powerplant)
+	if err != nil {
+		return
+	}
+
+	err = u.detectTimezone(ctx, powerplant)
+	if err != nil {
+		return
+	}
+
+	err = u.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := u.powerplantRepo.CreatePowerPlant(ctx, nil, powerplant)
+		if err != nil {
+			return err
+		}
+
+		return u.audit(ctx, model.AuditActionCreate, nil, powerplant)
+	})
 
 	return
 }
@@ -37,7 +144,69 @@ func (u *powerplantUsecase) CreatePowerPlant(ctx context.Context, powerplant *mo
 func (u *powerplantUsecase) UpdatePowerPlant(ctx context.Context, powerplant *model.PowerPlant) (err error) {
 	defer derrors.Wrap(&err, "UpdatePowerPlant(%q)", powerplant.ID)
 
-	err = u.powerplantRepo.UpdatePowerPlant(ctx, nil, powerplant)
+	_, err = auth.RequirePrincipal(ctx)
+	if err != nil {
+		return
+	}
+
+	err = u.validator.Validate(ctx, powerplant)
+	if err != nil {
+		return
+	}
+
+	err = u.detectTimezone(ctx, powerplant)
+	if err != nil {
+		return
+	}
+
+	err = u.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		before, err := u.powerplantRepo.GetPowerPlantByID(ctx, powerplant.ID)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return derrors.New(derrors.NotFound, "power plant not found")
+		}
+		if before.Version != powerplant.Version {
+			return derrors.New(derrors.Conflict, "power plant is at version %d, not %d", before.Version, powerplant.Version)
+		}
+
+		err = u.powerplantRepo.UpdatePowerPlant(ctx, nil, powerplant)
+		if err != nil {
+			return err
+		}
+
+		return u.audit(ctx, model.AuditActionUpdate, before, powerplant)
+	})
+
+	return
+}
+
+func (u *powerplantUsecase) DeletePowerPlant(ctx context.Context, powerplantID string) (err error) {
+	defer derrors.Wrap(&err, "DeletePowerPlant(%q)", powerplantID)
+
+	_, err = auth.RequirePrincipal(ctx)
+	if err != nil {
+		return
+	}
+
+	err = u.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		powerplant, err := u.powerplantRepo.GetPowerPlantByID(ctx, powerplantID)
+		if err != nil {
+			return err
+		}
+		if powerplant == nil {
+			return derrors.New(derrors.NotFound, "power plant not found")
+		}
+
+		err = u.powerplantRepo.DeletePowerPlant(ctx, nil, powerplantID)
+		if err != nil {
+			return err
+		}
+
+		return u.audit(ctx, model.AuditActionDelete, powerplant, nil)
+	})
+
 	return
 }
 
@@ -48,9 +217,292 @@ func (u *powerplantUsecase) GetPowerPlants(ctx context.Context, page, limit int)
 	return
 }
 
+func (u *powerplantUsecase) GetNearbyPowerPlants(ctx context.Context, latitude, longitude, radiusKm float64, limit int) (powerplants []*model.PowerPlant, err error) {
+	defer derrors.Wrap(&err, "GetNearbyPowerPlants(%v, %v, %v)", latitude, longitude, radiusKm)
+
+	fields := validateNearbyQuery(latitude, longitude, radiusKm, limit)
+	if len(fields) > 0 {
+		return nil, derrors.NewWithFields(derrors.InvalidArgument, fields, "invalid nearby search")
+	}
+
+	powerplants, err = u.powerplantRepo.GetNearbyPowerPlants(ctx, latitude, longitude, radiusKm, limit)
+	return
+}
+
+func (u *powerplantUsecase) GetPowerPlantsInBounds(ctx context.Context, bounds geo.Bounds) (region *model.PowerPlantRegion, err error) {
+	defer derrors.Wrap(&err, "GetPowerPlantsInBounds(%+v)", bounds)
+
+	fields := validateBounds(bounds)
+	if len(fields) > 0 {
+		return nil, derrors.NewWithFields(derrors.InvalidArgument, fields, "invalid bounds")
+	}
+
+	clusters, err := u.powerplantRepo.ClusterPowerPlantsInBounds(ctx, bounds, u.mapConf.ClusterGridSize)
+	if err != nil {
+		return nil, err
+	}
+
+	region = u.region(clusters)
+	if !region.Clustered {
+		region.Plants, err = u.powerplantRepo.GetPowerPlantsInBounds(ctx, bounds)
+		if err != nil {
+			return nil, err
+		}
+		region.TotalCount = len(region.Plants)
+	}
+
+	return region, nil
+}
+
+func (u *powerplantUsecase) GetPowerPlantsInPolygon(ctx context.Context, geojson string) (region *model.PowerPlantRegion, err error) {
+	defer derrors.Wrap(&err, "GetPowerPlantsInPolygon")
+
+	polygon, err := geo.ParsePolygon(geojson)
+	if err != nil {
+		return nil, derrors.NewWithFields(derrors.InvalidArgument, []derrors.FieldError{
+			{Field: "geojson", Message: err.Error()},
+		}, "invalid polygon")
+	}
+	fields := validatePolygon(polygon)
+	if len(fields) > 0 {
+		return nil, derrors.NewWithFields(derrors.InvalidArgument, fields, "invalid polygon")
+	}
+
+	clusters, err := u.powerplantRepo.ClusterPowerPlantsInPolygon(ctx, polygon, u.mapConf.ClusterGridSize)
+	if err != nil {
+		return nil, err
+	}
+
+	region = u.region(clusters)
+	if !region.Clustered {
+		region.Plants, err = u.powerplantRepo.GetPowerPlantsInPolygon(ctx, polygon)
+		if err != nil {
+			return nil, err
+		}
+		region.TotalCount = len(region.Plants)
+	}
+
+	return region, nil
+}
+
+// region counts the plants of a map region from the clusters of the grid over
+// it, past the configured maximum it returns the clusters instead of listing
+// the plants, which are left for the caller to read.
+func (u *powerplantUsecase) region(clusters []geo.Cluster) *model.PowerPlantRegion {
+	region := &model.PowerPlantRegion{
+		Plants:   []*model.PowerPlant{},
+		Clusters: []*model.PowerPlantCluster{},
+	}
+	for _, cluster := range clusters {
+		region.TotalCount += cluster.Count
+	}
+	if region.TotalCount <= u.mapConf.MaxPlants {
+		return region
+	}
+
+	region.Clustered = true
+	for _, cluster := range clusters {
+		region.Clusters = append(region.Clusters, &model.PowerPlantCluster{
+			Count:     cluster.Count,
+			Latitude:  cluster.Center.Latitude,
+			Longitude: cluster.Center.Longitude,
+			South:     cluster.Bounds.South,
+			West:      cluster.Bounds.West,
+			North:     cluster.Bounds.North,
+			East:      cluster.Bounds.East,
+		})
+	}
+
+	return region
+}
+
+func (u *powerplantUsecase) ExportPowerPlantsGeoJSON(ctx context.Context, w io.Writer) (err error) {
+	defer derrors.Wrap(&err, "ExportPowerPlantsGeoJSON")
+
+	return u.writeGeoJSON(ctx, w, 0)
+}
+
+func (u *powerplantUsecase) GetPowerPlantsGeoJSON(ctx context.Context) (document string, err error) {
+	defer derrors.Wrap(&err, "GetPowerPlantsGeoJSON")
+
+	var builder strings.Builder
+	err = u.writeGeoJSON(ctx, &builder, geoJSONMaxPlants)
+	if err != nil {
+		return "", err
+	}
+
+	return builder.String(), nil
+}
+
+// writeGeoJSON writes every plant to w as a GeoJSON FeatureCollection while
+// they are read from the repository, failing after maxPlants plants unless it
+// is 0.
+func (u *powerplantUsecase) writeGeoJSON(ctx context.Context, w io.Writer, maxPlants int) error {
+	var (
+		count    int
+		tooLarge error
+	)
+	features := geo.NewFeatureCollectionWriter(w)
+	err := u.powerplantRepo.StreamPowerPlants(ctx, func(powerplant *model.PowerPlant) error {
+		count++
+		if maxPlants > 0 && count > maxPlants {
+			tooLarge = derrors.New(derrors.InvalidArgument, "the fleet has more than %d power plants, it must be exported", maxPlants)
+			return tooLarge
+		}
+
+		return features.Write(geo.Feature{
+			ID:    powerplant.ID,
+			Point: geo.Point{Latitude: powerplant.Latitude, Longitude: powerplant.Longitude},
+			Properties: powerplantFeatureProperties{
+				Name:      powerplant.Name,
+				Latitude:  powerplant.Latitude,
+				Longitude: powerplant.Longitude,
+				Timezone:  powerplant.Timezone,
+				Version:   powerplant.Version,
+				CreatedAt: powerplant.CreatedAt,
+				UpdatedAt: powerplant.UpdatedAt,
+			},
+		})
+	})
+	if tooLarge != nil {
+		return tooLarge
+	}
+	if err != nil {
+		return err
+	}
+
+	return features.Close()
+}
+
 func (u *powerplantUsecase) GetPowerPlantByID(ctx context.Context, powerplantID string) (powerplant *model.PowerPlant, err error) {
 	defer derrors.Wrap(&err, "GetPowerPlantByID(%q)", powerplantID)
 
 	powerplant, err = u.powerplantRepo.GetPowerPlantByID(ctx, powerplantID)
 	return
 }
+
+func (u *powerplantUsecase) GetAuditTrail(ctx context.Context, powerplantID string) (events []*model.AuditEvent, err error) {
+	defer derrors.Wrap(&err, "GetAuditTrail(%q)", powerplantID)
+
+	events, err = u.auditEventRepo.GetAuditEvents(ctx, auditEntityType, powerplantID)
+	return
+}
+
+// audit records a change of a power plant in the transaction on ctx. before is
+// nil on create and after is nil on delete.
+func (u *powerplantUsecase) audit(ctx context.Context, action model.AuditAction, before, after *model.PowerPlant) (err error) {
+	principal, err := auth.RequirePrincipal(ctx)
+	if err != nil {
+		return err
+	}
+
+	event := &model.AuditEvent{
+		EntityType: auditEntityType,
+		Action:     action,
+		Actor:      principal.Subject,
+		RequestID:  requestid.FromContext(ctx),
+	}
+
+	if before != nil {
+		event.EntityID = before.ID
+		event.Before, err = snapshot(before)
+		if err != nil {
+			return err
+		}
+	}
+	if after != nil {
+		event.EntityID = after.ID
+		event.After, err = snapshot(after)
+		if err != nil {
+			return err
+		}
+	}
+
+	return u.auditEventRepo.CreateAuditEvent(ctx, nil, event)
+}
+
+func snapshot(powerplant *model.PowerPlant) (*string, error) {
+	data, err := json.Marshal(powerplantSnapshot{
+		ID:        powerplant.ID,
+		Name:      powerplant.Name,
+		Latitude:  powerplant.Latitude,
+		Longitude: powerplant.Longitude,
+		Timezone:  powerplant.Timezone,
+		Version:   powerplant.Version,
+	})
+	if err != nil {
+		return nil, derrors.WrapStack(err, derrors.Unknown, "json.Marshal")
+	}
+
+	str := string(data)
+	return &str, nil
+}
+
+// detectTimezone fills in the timezone of powerplant from its coordinates when
+// the caller did not provide one.
+func (u *powerplantUsecase) detectTimezone(ctx context.Context, powerplant *model.PowerPlant) (err error) {
+	if powerplant.Timezone != "" {
+		return nil
+	}
+
+	powerplant.Timezone, err = u.timezoneDetector.DetectTimezone(ctx, powerplant.Latitude, powerplant.Longitude)
+	return
+}
+
+// detectTimezones fills in the missing timezones of powerplants like
+// detectTimezone, detecting the timezone of each location once and at most
+// timezoneConcurrency at a time. It stops at the first error.
+func (u *powerplantUsecase) detectTimezones(ctx context.Context, powerplants []*model.PowerPlant) error {
+	type location struct {
+		latitude  float64
+		longitude float64
+	}
+	locations := make(map[location][]*model.PowerPlant)
+	for _, powerplant := range powerplants {
+		if powerplant.Timezone == "" {
+			key := location{latitude: powerplant.Latitude, longitude: powerplant.Longitude}
+			locations[key] = append(locations[key], powerplant)
+		}
+	}
+
+	detectCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	sem := make(chan struct{}, timezoneConcurrency)
+	for key, located := range locations {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+			case <-detectCtx.Done():
+				return
+			}
+			defer func() { <-sem }()
+
+			timezone, err := u.timezoneDetector.DetectTimezone(detectCtx, key.latitude, key.longitude)
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			for _, powerplant := range located {
+				powerplant.Timezone = timezone
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
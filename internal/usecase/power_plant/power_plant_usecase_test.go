@@ -2,9 +2,19 @@ package powerplantusecase_test
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"tensor-graphql/internal/auth"
 	"tensor-graphql/internal/model"
+	powerplantrepo "tensor-graphql/internal/repository/power_plant"
 	"tensor-graphql/internal/test"
 	powerplantusecase "tensor-graphql/internal/usecase/power_plant"
+	"tensor-graphql/pkg/datatype"
+	"tensor-graphql/pkg/derrors"
+	"tensor-graphql/pkg/geo"
+	"tensor-graphql/pkg/requestid"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -15,10 +25,24 @@ type params struct {
 	PowerPlant *model.PowerPlant
 }
 
+// withinTransaction stands in for TransactionManager.WithinTransaction and
+// runs the unit of work without a database.
+func withinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// auditEvent matches the audit event recorded for a change of powerPlantID.
+func auditEvent(action model.AuditAction, powerPlantID string) interface{} {
+	return mock.MatchedBy(func(event *model.AuditEvent) bool {
+		return event.EntityType == "power_plant" && event.EntityID == powerPlantID && event.Action == action
+	})
+}
+
 func TestCreatePowerPlant(t *testing.T) {
 	mc := test.InitMockComponent(t)
-	ctx := context.Background()
-	testUsecase := powerplantusecase.NewPowerPlantUsecase(mc.PowerPlantRepository)
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "operator"})
+	ctx = requestid.WithRequestID(ctx, "request-1")
+	testUsecase := powerplantusecase.NewPowerPlantUsecase(mc.PowerPlantRepository, mc.AuditEventRepository, mc.TransactionManager, mc.TimezoneDetector, mc.Config.Map)
 
 	var testCases = []struct {
 		caseName     string
@@ -37,8 +61,21 @@ func TestCreatePowerPlant(t *testing.T) {
 				},
 			},
 			expectations: func(params params) {
+				mc.PowerPlantRepository.On("GetPowerPlantByName", mock.Anything, params.PowerPlant.Name).
+					Return(nil, nil)
+				mc.TimezoneDetector.On("DetectTimezone", mock.Anything, params.PowerPlant.Latitude, params.PowerPlant.Longitude).
+					Return("Europe/Berlin", nil).Once()
+				mc.TransactionManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(withinTransaction).Once()
 				mc.PowerPlantRepository.On("CreatePowerPlant", mock.Anything, mock.Anything, params.PowerPlant).
 					Return(nil)
+				mc.AuditEventRepository.On("CreateAuditEvent", mock.Anything, mock.Anything, mock.MatchedBy(func(event *model.AuditEvent) bool {
+					return event.Action == model.AuditActionCreate &&
+						event.EntityID == params.PowerPlant.ID &&
+						event.Actor == "operator" &&
+						event.RequestID == "request-1" &&
+						event.Before == nil &&
+						event.After != nil && strings.Contains(*event.After, `"timezone":"Europe/Berlin"`)
+				})).Return(nil).Once()
 			},
 			results: func(err error) {
 				assert.NoError(t, err)
@@ -48,10 +85,15 @@ func TestCreatePowerPlant(t *testing.T) {
 			caseName: "CreatePowerPlant_Error",
 			params: params{
 				&model.PowerPlant{
-					ID: "2",
+					ID:       "2",
+					Name:     "test_name_2",
+					Timezone: "UTC",
 				},
 			},
 			expectations: func(params params) {
+				mc.PowerPlantRepository.On("GetPowerPlantByName", mock.Anything, params.PowerPlant.Name).
+					Return(nil, nil)
+				mc.TransactionManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(withinTransaction).Once()
 				mc.PowerPlantRepository.On("CreatePowerPlant", mock.Anything, mock.Anything, params.PowerPlant).
 					Return(assert.AnError)
 			},
@@ -59,6 +101,86 @@ func TestCreatePowerPlant(t *testing.T) {
 				assert.Error(t, err)
 			},
 		},
+		{
+			caseName: "CreatePowerPlant_DetectTimezoneError",
+			params: params{
+				&model.PowerPlant{
+					Name:      "test_name_3",
+					Latitude:  2.0,
+					Longitude: 2.0,
+				},
+			},
+			expectations: func(params params) {
+				mc.PowerPlantRepository.On("GetPowerPlantByName", mock.Anything, params.PowerPlant.Name).
+					Return(nil, nil)
+				mc.TimezoneDetector.On("DetectTimezone", mock.Anything, params.PowerPlant.Latitude, params.PowerPlant.Longitude).
+					Return("", assert.AnError).Once()
+			},
+			results: func(err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			caseName: "CreatePowerPlant_InvalidTimezone",
+			params: params{
+				&model.PowerPlant{
+					Name:     "test_name_4",
+					Timezone: "Mars/Olympus_Mons",
+				},
+			},
+			expectations: func(params params) {},
+			results: func(err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+			},
+		},
+		{
+			caseName: "CreatePowerPlant_InvalidArgument",
+			params: params{
+				&model.PowerPlant{
+					Name:      "<script>",
+					Latitude:  500,
+					Longitude: 1.0,
+				},
+			},
+			expectations: func(params params) {},
+			results: func(err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+				fields := derrors.FieldsOf(err)
+				if assert.Len(t, fields, 2) {
+					assert.Equal(t, "name", fields[0].Field)
+					assert.Equal(t, "latitude", fields[1].Field)
+				}
+			},
+		},
+		{
+			caseName: "CreatePowerPlant_NameTooLong",
+			params: params{
+				&model.PowerPlant{
+					Name: strings.Repeat("a", 10*1024),
+				},
+			},
+			expectations: func(params params) {},
+			results: func(err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+			},
+		},
+		{
+			caseName: "CreatePowerPlant_Duplicate",
+			params: params{
+				&model.PowerPlant{
+					Name:      "existing_name",
+					Latitude:  1.0,
+					Longitude: 1.0,
+				},
+			},
+			expectations: func(params params) {
+				mc.PowerPlantRepository.On("GetPowerPlantByName", mock.Anything, params.PowerPlant.Name).
+					Return(&model.PowerPlant{ID: "3", Name: params.PowerPlant.Name}, nil)
+			},
+			results: func(err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.Duplicate))
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -68,12 +190,17 @@ func TestCreatePowerPlant(t *testing.T) {
 			testCase.results(err)
 		})
 	}
+
+	t.Run("CreatePowerPlant_Anonymous", func(t *testing.T) {
+		err := testUsecase.CreatePowerPlant(context.Background(), &model.PowerPlant{Name: "anonymous"})
+		assert.True(t, derrors.IsErrCode(err, derrors.Unauthorized))
+	})
 }
 
 func TestGetPowerPlantByID(t *testing.T) {
 	mc := test.InitMockComponent(t)
 	ctx := context.Background()
-	testUsecase := powerplantusecase.NewPowerPlantUsecase(mc.PowerPlantRepository)
+	testUsecase := powerplantusecase.NewPowerPlantUsecase(mc.PowerPlantRepository, mc.AuditEventRepository, mc.TransactionManager, mc.TimezoneDetector, mc.Config.Map)
 
 	var testCases = []struct {
 		caseName     string
@@ -129,8 +256,8 @@ func TestGetPowerPlantByID(t *testing.T) {
 
 func TestUpdatePowerPlant(t *testing.T) {
 	mc := test.InitMockComponent(t)
-	ctx := context.Background()
-	testUsecase := powerplantusecase.NewPowerPlantUsecase(mc.PowerPlantRepository)
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "operator"})
+	testUsecase := powerplantusecase.NewPowerPlantUsecase(mc.PowerPlantRepository, mc.AuditEventRepository, mc.TransactionManager, mc.TimezoneDetector, mc.Config.Map)
 
 	var testCases = []struct {
 		caseName     string
@@ -146,11 +273,22 @@ func TestUpdatePowerPlant(t *testing.T) {
 					Name:      "test_name",
 					Latitude:  1.0,
 					Longitude: 1.0,
+					Timezone:  "UTC",
 				},
 			},
 			expectations: func(params params) {
+				mc.PowerPlantRepository.On("GetPowerPlantByName", mock.Anything, params.PowerPlant.Name).
+					Return(params.PowerPlant, nil)
+				mc.PowerPlantRepository.On("GetPowerPlantByID", mock.Anything, params.PowerPlant.ID).
+					Return(&model.PowerPlant{ID: params.PowerPlant.ID, Name: "old_name"}, nil)
+				mc.TransactionManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(withinTransaction).Once()
 				mc.PowerPlantRepository.On("UpdatePowerPlant", mock.Anything, mock.Anything, params.PowerPlant).
 					Return(nil)
+				mc.AuditEventRepository.On("CreateAuditEvent", mock.Anything, mock.Anything, mock.MatchedBy(func(event *model.AuditEvent) bool {
+					return event.Action == model.AuditActionUpdate &&
+						event.Before != nil && strings.Contains(*event.Before, `"name":"old_name"`) &&
+						event.After != nil && strings.Contains(*event.After, `"name":"test_name"`)
+				})).Return(nil).Once()
 			},
 			results: func(err error) {
 				assert.NoError(t, err)
@@ -160,10 +298,17 @@ func TestUpdatePowerPlant(t *testing.T) {
 			caseName: "UpdatePowerPlant_Error",
 			params: params{
 				&model.PowerPlant{
-					ID: "2",
+					ID:       "2",
+					Name:     "test_name_2",
+					Timezone: "UTC",
 				},
 			},
 			expectations: func(params params) {
+				mc.PowerPlantRepository.On("GetPowerPlantByName", mock.Anything, params.PowerPlant.Name).
+					Return(nil, nil)
+				mc.PowerPlantRepository.On("GetPowerPlantByID", mock.Anything, params.PowerPlant.ID).
+					Return(&model.PowerPlant{ID: params.PowerPlant.ID}, nil)
+				mc.TransactionManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(withinTransaction).Once()
 				mc.PowerPlantRepository.On("UpdatePowerPlant", mock.Anything, mock.Anything, params.PowerPlant).
 					Return(assert.AnError)
 			},
@@ -171,6 +316,71 @@ func TestUpdatePowerPlant(t *testing.T) {
 				assert.Error(t, err)
 			},
 		},
+		{
+			caseName: "UpdatePowerPlant_AuditError",
+			params: params{
+				&model.PowerPlant{
+					ID:       "3",
+					Name:     "test_name_3",
+					Timezone: "UTC",
+				},
+			},
+			expectations: func(params params) {
+				mc.PowerPlantRepository.On("GetPowerPlantByName", mock.Anything, params.PowerPlant.Name).
+					Return(nil, nil)
+				mc.PowerPlantRepository.On("GetPowerPlantByID", mock.Anything, params.PowerPlant.ID).
+					Return(&model.PowerPlant{ID: params.PowerPlant.ID}, nil)
+				mc.TransactionManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(withinTransaction).Once()
+				mc.PowerPlantRepository.On("UpdatePowerPlant", mock.Anything, mock.Anything, params.PowerPlant).
+					Return(nil)
+				mc.AuditEventRepository.On("CreateAuditEvent", mock.Anything, mock.Anything, auditEvent(model.AuditActionUpdate, params.PowerPlant.ID)).
+					Return(assert.AnError).Once()
+			},
+			results: func(err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			caseName: "UpdatePowerPlant_Conflict",
+			params: params{
+				&model.PowerPlant{
+					ID:       "5",
+					Name:     "test_name_5",
+					Timezone: "UTC",
+					Version:  1,
+				},
+			},
+			expectations: func(params params) {
+				mc.PowerPlantRepository.On("GetPowerPlantByName", mock.Anything, params.PowerPlant.Name).
+					Return(nil, nil)
+				mc.TransactionManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(withinTransaction).Once()
+				mc.PowerPlantRepository.On("GetPowerPlantByID", mock.Anything, params.PowerPlant.ID).
+					Return(&model.PowerPlant{ID: params.PowerPlant.ID, Version: 2}, nil)
+			},
+			results: func(err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.Conflict))
+			},
+		},
+		{
+			caseName: "UpdatePowerPlant_NotFound",
+			params: params{
+				&model.PowerPlant{
+					ID:       "4",
+					Name:     "test_name_4",
+					Timezone: "UTC",
+				},
+			},
+			expectations: func(params params) {
+				mc.PowerPlantRepository.On("GetPowerPlantByName", mock.Anything, params.PowerPlant.Name).
+					Return(nil, nil)
+				mc.TransactionManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(withinTransaction).Once()
+				mc.PowerPlantRepository.On("GetPowerPlantByID", mock.Anything, params.PowerPlant.ID).
+					Return(nil, nil)
+			},
+			results: func(err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.NotFound))
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -180,12 +390,17 @@ func TestUpdatePowerPlant(t *testing.T) {
 			testCase.results(err)
 		})
 	}
+
+	t.Run("UpdatePowerPlant_Anonymous", func(t *testing.T) {
+		err := testUsecase.UpdatePowerPlant(context.Background(), &model.PowerPlant{ID: "1"})
+		assert.True(t, derrors.IsErrCode(err, derrors.Unauthorized))
+	})
 }
 
 func TestGetPowerPlants(t *testing.T) {
 	mc := test.InitMockComponent(t)
 	ctx := context.Background()
-	testUsecase := powerplantusecase.NewPowerPlantUsecase(mc.PowerPlantRepository)
+	testUsecase := powerplantusecase.NewPowerPlantUsecase(mc.PowerPlantRepository, mc.AuditEventRepository, mc.TransactionManager, mc.TimezoneDetector, mc.Config.Map)
 
 	var testCases = []struct {
 		caseName     string
@@ -224,3 +439,807 @@ func TestGetPowerPlants(t *testing.T) {
 		})
 	}
 }
+
+func TestGetNearbyPowerPlants(t *testing.T) {
+	mc := test.InitMockComponent(t)
+	ctx := context.Background()
+	testUsecase := powerplantusecase.NewPowerPlantUsecase(mc.PowerPlantRepository, mc.AuditEventRepository, mc.TransactionManager, mc.TimezoneDetector, mc.Config.Map)
+
+	type nearbyParams struct {
+		latitude, longitude, radiusKm float64
+		limit                         int
+	}
+
+	var testCases = []struct {
+		caseName     string
+		params       nearbyParams
+		expectations func(params nearbyParams)
+		results      func(powerplants []*model.PowerPlant, err error)
+	}{
+		{
+			caseName: "GetNearbyPowerPlants_Success",
+			params:   nearbyParams{latitude: -6.2, longitude: 106.8, radiusKm: 50, limit: 10},
+			expectations: func(params nearbyParams) {
+				distanceKm := 12.5
+				mc.PowerPlantRepository.On("GetNearbyPowerPlants", mock.Anything, params.latitude, params.longitude, params.radiusKm, params.limit).
+					Return([]*model.PowerPlant{{ID: "1", Name: "test_name", DistanceKm: &distanceKm}}, nil).Once()
+			},
+			results: func(powerplants []*model.PowerPlant, err error) {
+				assert.NoError(t, err)
+				if assert.Len(t, powerplants, 1) {
+					assert.Equal(t, 12.5, *powerplants[0].DistanceKm)
+				}
+			},
+		},
+		{
+			caseName:     "GetNearbyPowerPlants_InvalidArguments",
+			params:       nearbyParams{latitude: 91, longitude: 106.8, radiusKm: 0, limit: 101},
+			expectations: func(params nearbyParams) {},
+			results: func(powerplants []*model.PowerPlant, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+				var fields []string
+				for _, field := range derrors.FieldsOf(err) {
+					fields = append(fields, field.Field)
+				}
+				assert.Equal(t, []string{"latitude", "radiusKm", "limit"}, fields)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			testCase.expectations(testCase.params)
+			powerplants, err := testUsecase.GetNearbyPowerPlants(ctx, testCase.params.latitude, testCase.params.longitude, testCase.params.radiusKm, testCase.params.limit)
+			testCase.results(powerplants, err)
+		})
+	}
+}
+
+func TestGetPowerPlantsInBounds(t *testing.T) {
+	mc := test.InitMockComponent(t)
+	ctx := context.Background()
+	testUsecase := powerplantusecase.NewPowerPlantUsecase(mc.PowerPlantRepository, mc.AuditEventRepository, mc.TransactionManager, mc.TimezoneDetector, mc.Config.Map)
+
+	// The mock component allows 2 plants per region on a 2 by 2 grid.
+	twoPlants := []*model.PowerPlant{
+		{ID: "1", Latitude: 1, Longitude: 1},
+		{ID: "2", Latitude: 9, Longitude: 9},
+	}
+	twoClusters := []geo.Cluster{
+		{Center: geo.Point{Latitude: 1, Longitude: 1}, Count: 1, Bounds: geo.Bounds{South: 0, West: 0, North: 5, East: 5}},
+		{Center: geo.Point{Latitude: 9, Longitude: 9}, Count: 1, Bounds: geo.Bounds{South: 5, West: 5, North: 10, East: 10}},
+	}
+	threeClusters := []geo.Cluster{
+		{Center: geo.Point{Latitude: 1.5, Longitude: 1.5}, Count: 2, Bounds: geo.Bounds{South: 0, West: 0, North: 5, East: 5}},
+		{Center: geo.Point{Latitude: 9, Longitude: 9}, Count: 1, Bounds: geo.Bounds{South: 5, West: 5, North: 10, East: 10}},
+	}
+
+	var testCases = []struct {
+		caseName     string
+		bounds       geo.Bounds
+		expectations func(bounds geo.Bounds)
+		results      func(region *model.PowerPlantRegion, err error)
+	}{
+		{
+			caseName: "GetPowerPlantsInBounds_Listed",
+			bounds:   geo.Bounds{South: 0, West: 0, North: 10, East: 10},
+			expectations: func(bounds geo.Bounds) {
+				mc.PowerPlantRepository.On("ClusterPowerPlantsInBounds", mock.Anything, bounds, 2).Return(twoClusters, nil).Once()
+				mc.PowerPlantRepository.On("GetPowerPlantsInBounds", mock.Anything, bounds).Return(twoPlants, nil).Once()
+			},
+			results: func(region *model.PowerPlantRegion, err error) {
+				assert.NoError(t, err)
+				assert.False(t, region.Clustered)
+				assert.Equal(t, 2, region.TotalCount)
+				assert.Len(t, region.Plants, 2)
+				assert.Empty(t, region.Clusters)
+			},
+		},
+		{
+			// The plants are not read once the clusters count too many.
+			caseName: "GetPowerPlantsInBounds_Clustered",
+			bounds:   geo.Bounds{South: 0, West: 0, North: 10, East: 10},
+			expectations: func(bounds geo.Bounds) {
+				mc.PowerPlantRepository.On("ClusterPowerPlantsInBounds", mock.Anything, bounds, 2).Return(threeClusters, nil).Once()
+			},
+			results: func(region *model.PowerPlantRegion, err error) {
+				assert.NoError(t, err)
+				assert.True(t, region.Clustered)
+				assert.Equal(t, 3, region.TotalCount)
+				assert.Empty(t, region.Plants)
+				assert.Equal(t, []*model.PowerPlantCluster{
+					{Count: 2, Latitude: 1.5, Longitude: 1.5, South: 0, West: 0, North: 5, East: 5},
+					{Count: 1, Latitude: 9, Longitude: 9, South: 5, West: 5, North: 10, East: 10},
+				}, region.Clusters)
+			},
+		},
+		{
+			caseName:     "GetPowerPlantsInBounds_InvalidBounds",
+			bounds:       geo.Bounds{South: 10, West: -200, North: 0, East: 10},
+			expectations: func(bounds geo.Bounds) {},
+			results: func(region *model.PowerPlantRegion, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+				assert.Len(t, derrors.FieldsOf(err), 2)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			testCase.expectations(testCase.bounds)
+			region, err := testUsecase.GetPowerPlantsInBounds(ctx, testCase.bounds)
+			testCase.results(region, err)
+		})
+	}
+}
+
+func TestGetPowerPlantsInPolygon(t *testing.T) {
+	mc := test.InitMockComponent(t)
+	ctx := context.Background()
+	testUsecase := powerplantusecase.NewPowerPlantUsecase(mc.PowerPlantRepository, mc.AuditEventRepository, mc.TransactionManager, mc.TimezoneDetector, mc.Config.Map)
+
+	var testCases = []struct {
+		caseName     string
+		geojson      string
+		expectations func()
+		results      func(region *model.PowerPlantRegion, err error)
+	}{
+		{
+			caseName: "GetPowerPlantsInPolygon_Success",
+			geojson:  `{"type": "Polygon", "coordinates": [[[100, 0], [110, 0], [100, 10], [100, 0]]]}`,
+			expectations: func() {
+				mc.PowerPlantRepository.On("ClusterPowerPlantsInPolygon", mock.Anything, mock.AnythingOfType("geo.Polygon"), 2).
+					Return([]geo.Cluster{{Center: geo.Point{Latitude: 2, Longitude: 102}, Count: 1}}, nil).Once()
+				mc.PowerPlantRepository.On("GetPowerPlantsInPolygon", mock.Anything, mock.AnythingOfType("geo.Polygon")).
+					Return([]*model.PowerPlant{{ID: "1", Latitude: 2, Longitude: 102}}, nil).Once()
+			},
+			results: func(region *model.PowerPlantRegion, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, 1, region.TotalCount)
+				assert.Len(t, region.Plants, 1)
+			},
+		},
+		{
+			caseName:     "GetPowerPlantsInPolygon_InvalidGeoJSON",
+			geojson:      `{"type": "Point", "coordinates": [100, 0]}`,
+			expectations: func() {},
+			results: func(region *model.PowerPlantRegion, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+			},
+		},
+		{
+			caseName:     "GetPowerPlantsInPolygon_TooManyPositions",
+			geojson:      `{"type": "Polygon", "coordinates": [[[100, 0]` + strings.Repeat(`, [110, 0], [100, 10]`, 500) + `, [100, 0]]]}`,
+			expectations: func() {},
+			results: func(region *model.PowerPlantRegion, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+				assert.Equal(t, []derrors.FieldError{{Field: "geojson", Message: "polygon must have at most 1000 positions"}}, derrors.FieldsOf(err))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			testCase.expectations()
+			region, err := testUsecase.GetPowerPlantsInPolygon(ctx, testCase.geojson)
+			testCase.results(region, err)
+		})
+	}
+}
+
+func TestExportPowerPlantsGeoJSON(t *testing.T) {
+	mc := test.InitMockComponent(t)
+	ctx := context.Background()
+	testUsecase := powerplantusecase.NewPowerPlantUsecase(mc.PowerPlantRepository, mc.AuditEventRepository, mc.TransactionManager, mc.TimezoneDetector, mc.Config.Map)
+
+	createdAt, _ := datatype.ParseTime("2025-03-04T10:00:00Z")
+
+	// stream stands in for StreamPowerPlants and passes powerplants to fn.
+	stream := func(powerplants ...*model.PowerPlant) func(args mock.Arguments) {
+		return func(args mock.Arguments) {
+			fn := args.Get(1).(func(powerPlant *model.PowerPlant) error)
+			for _, powerplant := range powerplants {
+				if fn(powerplant) != nil {
+					return
+				}
+			}
+		}
+	}
+
+	var testCases = []struct {
+		caseName string
+		// document gets the collection with GetPowerPlantsGeoJSON instead.
+		document     bool
+		expectations func()
+		results      func(document string, err error)
+	}{
+		{
+			caseName: "ExportPowerPlantsGeoJSON_Success",
+			expectations: func() {
+				mc.PowerPlantRepository.On("StreamPowerPlants", mock.Anything, mock.Anything).
+					Run(stream(&model.PowerPlant{ID: "1", Name: "test_name", Latitude: -6.2, Longitude: 106.8, Timezone: "Asia/Jakarta", Version: 2, CreatedAt: createdAt, UpdatedAt: createdAt})).
+					Return(nil).Once()
+			},
+			results: func(document string, err error) {
+				assert.NoError(t, err)
+				assert.JSONEq(t, `{"type": "FeatureCollection", "features": [{
+					"type": "Feature",
+					"id": "1",
+					"geometry": {"type": "Point", "coordinates": [106.8, -6.2]},
+					"properties": {"name": "test_name", "latitude": -6.2, "longitude": 106.8, "timezone": "Asia/Jakarta", "version": 2, "createdAt": "2025-03-04T10:00:00Z", "updatedAt": "2025-03-04T10:00:00Z"}
+				}]}`, document)
+			},
+		},
+		{
+			caseName: "ExportPowerPlantsGeoJSON_Empty",
+			expectations: func() {
+				mc.PowerPlantRepository.On("StreamPowerPlants", mock.Anything, mock.Anything).
+					Run(stream()).Return(nil).Once()
+			},
+			results: func(document string, err error) {
+				assert.NoError(t, err)
+				assert.JSONEq(t, `{"type": "FeatureCollection", "features": []}`, document)
+			},
+		},
+		{
+			caseName: "ExportPowerPlantsGeoJSON_Error",
+			expectations: func() {
+				mc.PowerPlantRepository.On("StreamPowerPlants", mock.Anything, mock.Anything).
+					Return(derrors.New(derrors.Unknown, "error")).Once()
+			},
+			results: func(document string, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.Unknown))
+				assert.Empty(t, document)
+			},
+		},
+		{
+			caseName: "GetPowerPlantsGeoJSON_Success",
+			document: true,
+			expectations: func() {
+				mc.PowerPlantRepository.On("StreamPowerPlants", mock.Anything, mock.Anything).
+					Run(stream(&model.PowerPlant{ID: "1", Name: "test_name", Latitude: -6.2, Longitude: 106.8})).
+					Return(nil).Once()
+			},
+			results: func(document string, err error) {
+				assert.NoError(t, err)
+				assert.Contains(t, document, `"id":"1"`)
+			},
+		},
+		{
+			caseName: "GetPowerPlantsGeoJSON_TooLarge",
+			document: true,
+			expectations: func() {
+				powerplants := make([]*model.PowerPlant, 1001)
+				for i := range powerplants {
+					powerplants[i] = &model.PowerPlant{ID: strconv.Itoa(i + 1)}
+				}
+				mc.PowerPlantRepository.On("StreamPowerPlants", mock.Anything, mock.Anything).
+					Run(stream(powerplants...)).Return(nil).Once()
+			},
+			results: func(document string, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+				assert.Empty(t, document)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			testCase.expectations()
+			if testCase.document {
+				document, err := testUsecase.GetPowerPlantsGeoJSON(ctx)
+				testCase.results(document, err)
+				return
+			}
+			var document strings.Builder
+			err := testUsecase.ExportPowerPlantsGeoJSON(ctx, &document)
+			testCase.results(document.String(), err)
+		})
+	}
+}
+
+func TestImportPowerPlants(t *testing.T) {
+	mc := test.InitMockComponent(t)
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "operator"})
+	testUsecase := powerplantusecase.NewPowerPlantUsecase(mc.PowerPlantRepository, mc.AuditEventRepository, mc.TransactionManager, mc.TimezoneDetector, mc.Config.Map)
+
+	// created matches the plants of names and gives them the IDs from id on
+	// when they are stored.
+	created := func(id int, names ...string) {
+		mc.PowerPlantRepository.On("CreatePowerPlants", mock.Anything, mock.Anything, mock.MatchedBy(func(plants []*model.PowerPlant) bool {
+			return slices.Equal(plantNames(plants), names)
+		})).Run(func(args mock.Arguments) {
+			for i, plant := range args.Get(2).([]*model.PowerPlant) {
+				plant.ID = strconv.Itoa(id + i)
+			}
+		}).Return(nil).Once()
+	}
+
+	type importParams struct {
+		csv    string
+		dryRun bool
+	}
+
+	// rowErrors returns the fields of the errors of every row, "row" stands
+	// for errors of the whole row.
+	rowErrors := func(result *model.PowerPlantImport) [][]string {
+		var rows [][]string
+		for _, row := range result.Rows {
+			fields := []string{}
+			for _, fieldError := range row.Errors {
+				field := "row"
+				if fieldError.Field != nil {
+					field = *fieldError.Field
+				}
+				fields = append(fields, field)
+			}
+			rows = append(rows, fields)
+		}
+		return rows
+	}
+
+	var testCases = []struct {
+		caseName     string
+		params       importParams
+		expectations func(params importParams)
+		results      func(result *model.PowerPlantImport, err error)
+	}{
+		{
+			caseName: "ImportPowerPlants_Success",
+			params: importParams{csv: "\ufeffName,Latitude,Longitude,Timezone\n" +
+				"Plant A,1.5,2.5,\n" +
+				"Plant B,-6.2,106.8,Asia/Jakarta\n" +
+				"Plant C,north,2.5,UTC\n" +
+				"Plant A,3,4,UTC\n" +
+				"Plant D,1,2,Mars/Olympus\n" +
+				"Plant \"E\",1,2\n" +
+				"Existing,1,2,UTC\n"},
+			expectations: func(params importParams) {
+				mc.PowerPlantRepository.On("GetPowerPlantByName", mock.Anything, "Existing").
+					Return(&model.PowerPlant{ID: "9", Name: "Existing"}, nil).Once()
+				mc.PowerPlantRepository.On("GetPowerPlantByName", mock.Anything, mock.Anything).
+					Return(nil, nil).Twice()
+				mc.TimezoneDetector.On("DetectTimezone", mock.Anything, 1.5, 2.5).
+					Return("Europe/Berlin", nil).Once()
+				mc.TransactionManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(withinTransaction).Once()
+				created(1, "Plant A", "Plant B")
+				mc.AuditEventRepository.On("CreateAuditEvent", mock.Anything, mock.Anything, auditEvent(model.AuditActionCreate, "1")).Return(nil).Once()
+				mc.AuditEventRepository.On("CreateAuditEvent", mock.Anything, mock.Anything, auditEvent(model.AuditActionCreate, "2")).Return(nil).Once()
+			},
+			results: func(result *model.PowerPlantImport, err error) {
+				assert.NoError(t, err)
+				assert.False(t, result.DryRun)
+				assert.Equal(t, 2, result.ValidCount)
+				assert.Equal(t, 5, result.ErrorCount)
+				assert.Equal(t, [][]string{{}, {}, {"latitude"}, {"name"}, {"timezone"}, {"row"}, {"name"}}, rowErrors(result))
+				if assert.Len(t, result.Rows, 7) {
+					assert.Equal(t, 2, result.Rows[0].Line)
+					assert.Equal(t, "1", *result.Rows[0].ID)
+					assert.Equal(t, "2", *result.Rows[1].ID)
+					assert.Nil(t, result.Rows[2].ID)
+					assert.Equal(t, "name is already used on line 2", result.Rows[3].Errors[0].Message)
+					assert.Equal(t, 7, result.Rows[5].Line)
+				}
+			},
+		},
+		{
+			caseName: "ImportPowerPlants_DryRun",
+			params:   importParams{csv: "latitude,longitude,name\n1.5,2.5,Plant A\n", dryRun: true},
+			expectations: func(params importParams) {
+				mc.PowerPlantRepository.On("GetPowerPlantByName", mock.Anything, "Plant A").
+					Return(nil, nil).Once()
+			},
+			results: func(result *model.PowerPlantImport, err error) {
+				assert.NoError(t, err)
+				assert.True(t, result.DryRun)
+				assert.Equal(t, 1, result.ValidCount)
+				if assert.Len(t, result.Rows, 1) {
+					assert.Equal(t, "Plant A", result.Rows[0].Name)
+					assert.Nil(t, result.Rows[0].ID)
+				}
+			},
+		},
+		{
+			caseName:     "ImportPowerPlants_MissingColumns",
+			params:       importParams{csv: "name,lat,lon\nPlant A,1.5,2.5\n"},
+			expectations: func(params importParams) {},
+			results: func(result *model.PowerPlantImport, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+				assert.Contains(t, err.Error(), "latitude, longitude")
+				assert.Nil(t, result)
+			},
+		},
+		{
+			caseName:     "ImportPowerPlants_Empty",
+			params:       importParams{csv: ""},
+			expectations: func(params importParams) {},
+			results: func(result *model.PowerPlantImport, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+			},
+		},
+		{
+			caseName: "ImportPowerPlants_CreatedMeanwhile",
+			params:   importParams{csv: "name,latitude,longitude,timezone\nPlant A,1.5,2.5,UTC\nPlant B,3.5,4.5,UTC\nPlant C,5.5,6.5,UTC\n"},
+			expectations: func(params importParams) {
+				mc.PowerPlantRepository.On("GetPowerPlantByName", mock.Anything, mock.Anything).
+					Return(nil, nil).Times(3)
+				// Plants B and C are created by other requests before the
+				// import runs, it is repeated once without them.
+				mc.TransactionManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(withinTransaction).Twice()
+				mc.PowerPlantRepository.On("CreatePowerPlants", mock.Anything, mock.Anything, mock.MatchedBy(func(plants []*model.PowerPlant) bool {
+					return len(plants) == 3
+				})).Return(errors.Join(
+					&powerplantrepo.ItemError{Index: 1, Err: derrors.New(derrors.Duplicate, "duplicate power plant name")},
+					&powerplantrepo.ItemError{Index: 2, Err: derrors.New(derrors.Duplicate, "duplicate power plant name")},
+				)).Once()
+				created(3, "Plant A")
+				mc.AuditEventRepository.On("CreateAuditEvent", mock.Anything, mock.Anything, auditEvent(model.AuditActionCreate, "3")).Return(nil).Once()
+			},
+			results: func(result *model.PowerPlantImport, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, 1, result.ValidCount)
+				assert.Equal(t, 2, result.ErrorCount)
+				assert.Equal(t, [][]string{{}, {"name"}, {"name"}}, rowErrors(result))
+				if assert.Len(t, result.Rows, 3) {
+					assert.Equal(t, "3", *result.Rows[0].ID)
+					assert.Nil(t, result.Rows[1].ID)
+					assert.Nil(t, result.Rows[2].ID)
+				}
+			},
+		},
+		{
+			caseName: "ImportPowerPlants_CreateError",
+			params:   importParams{csv: "name,latitude,longitude,timezone\nPlant A,1.5,2.5,UTC\n"},
+			expectations: func(params importParams) {
+				mc.PowerPlantRepository.On("GetPowerPlantByName", mock.Anything, "Plant A").
+					Return(nil, nil).Once()
+				mc.TransactionManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(withinTransaction).Once()
+				mc.PowerPlantRepository.On("CreatePowerPlants", mock.Anything, mock.Anything, mock.Anything).
+					Return(assert.AnError).Once()
+			},
+			results: func(result *model.PowerPlantImport, err error) {
+				assert.ErrorIs(t, err, assert.AnError)
+				assert.Nil(t, result)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			testCase.expectations(testCase.params)
+			result, err := testUsecase.ImportPowerPlants(ctx, strings.NewReader(testCase.params.csv), testCase.params.dryRun)
+			testCase.results(result, err)
+		})
+	}
+}
+
+// plantNames returns the names of plants.
+func plantNames(plants []*model.PowerPlant) []string {
+	names := make([]string, 0, len(plants))
+	for _, plant := range plants {
+		names = append(names, plant.Name)
+	}
+	return names
+}
+
+// batchErrors returns the fields of the errors of every item.
+func batchErrors(batch *model.PowerPlantBatch) [][]string {
+	var items [][]string
+	for _, item := range batch.Items {
+		fields := []string{}
+		for _, fieldError := range item.Errors {
+			fields = append(fields, *fieldError.Field)
+		}
+		items = append(items, fields)
+	}
+	return items
+}
+
+func TestCreatePowerPlants(t *testing.T) {
+	mc := test.InitMockComponent(t)
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "operator"})
+	testUsecase := powerplantusecase.NewPowerPlantUsecase(mc.PowerPlantRepository, mc.AuditEventRepository, mc.TransactionManager, mc.TimezoneDetector, mc.Config.Map)
+
+	var testCases = []struct {
+		caseName     string
+		params       []*model.PowerPlant
+		expectations func(params []*model.PowerPlant)
+		results      func(batch *model.PowerPlantBatch, err error)
+	}{
+		{
+			caseName: "CreatePowerPlants_Success",
+			params: []*model.PowerPlant{
+				{Name: "Plant A", Latitude: 1.5, Longitude: 2.5},
+				{Name: "Plant B", Latitude: 3.5, Longitude: 4.5, Timezone: "UTC"},
+			},
+			expectations: func(params []*model.PowerPlant) {
+				mc.PowerPlantRepository.On("GetPowerPlantByName", mock.Anything, mock.Anything).
+					Return(nil, nil).Twice()
+				mc.TimezoneDetector.On("DetectTimezone", mock.Anything, 1.5, 2.5).
+					Return("Europe/Berlin", nil).Once()
+				mc.TransactionManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(withinTransaction).Once()
+				mc.PowerPlantRepository.On("CreatePowerPlants", mock.Anything, mock.Anything, params).
+					Run(func(args mock.Arguments) {
+						for i, plant := range args.Get(2).([]*model.PowerPlant) {
+							plant.ID = strconv.Itoa(i + 1)
+						}
+					}).Return(nil).Once()
+				mc.AuditEventRepository.On("CreateAuditEvent", mock.Anything, mock.Anything, auditEvent(model.AuditActionCreate, "1")).Return(nil).Once()
+				mc.AuditEventRepository.On("CreateAuditEvent", mock.Anything, mock.Anything, auditEvent(model.AuditActionCreate, "2")).Return(nil).Once()
+			},
+			results: func(batch *model.PowerPlantBatch, err error) {
+				assert.NoError(t, err)
+				assert.True(t, batch.Success)
+				if assert.Len(t, batch.Items, 2) {
+					assert.True(t, batch.Items[0].Success)
+					assert.Equal(t, "1", batch.Items[0].Plant.ID)
+					assert.Equal(t, "Europe/Berlin", batch.Items[0].Plant.Timezone)
+					assert.Equal(t, 1, batch.Items[1].Index)
+					assert.Equal(t, "2", batch.Items[1].Plant.ID)
+				}
+			},
+		},
+		{
+			caseName: "CreatePowerPlants_InvalidItems",
+			params: []*model.PowerPlant{
+				{Name: "Plant A", Latitude: 1.5, Longitude: 2.5},
+				{Name: "Plant A", Latitude: 3.5, Longitude: 4.5},
+				{Name: "Plant C", Latitude: 91, Longitude: 4.5},
+			},
+			expectations: func(params []*model.PowerPlant) {
+				mc.PowerPlantRepository.On("GetPowerPlantByName", mock.Anything, "Plant A").
+					Return(nil, nil).Once()
+			},
+			results: func(batch *model.PowerPlantBatch, err error) {
+				assert.NoError(t, err)
+				assert.False(t, batch.Success)
+				assert.Equal(t, [][]string{{}, {"name"}, {"latitude"}}, batchErrors(batch))
+				for _, item := range batch.Items {
+					assert.False(t, item.Success)
+					assert.Nil(t, item.Plant)
+				}
+				assert.Equal(t, "name is already used on item 0", batch.Items[1].Errors[0].Message)
+			},
+		},
+		{
+			caseName: "CreatePowerPlants_CreatedMeanwhile",
+			params: []*model.PowerPlant{
+				{Name: "Plant A", Latitude: 1.5, Longitude: 2.5},
+				{Name: "Plant B", Latitude: 1.5, Longitude: 2.5},
+			},
+			expectations: func(params []*model.PowerPlant) {
+				mc.PowerPlantRepository.On("GetPowerPlantByName", mock.Anything, mock.Anything).
+					Return(nil, nil).Twice()
+				// Plants at the same location share the detected timezone.
+				mc.TimezoneDetector.On("DetectTimezone", mock.Anything, 1.5, 2.5).
+					Return("Europe/Berlin", nil).Once()
+				mc.TransactionManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(withinTransaction).Once()
+				mc.PowerPlantRepository.On("CreatePowerPlants", mock.Anything, mock.Anything, params).
+					Return(&powerplantrepo.ItemError{Index: 1, Err: derrors.NewWithFields(derrors.Duplicate, []derrors.FieldError{
+						{Field: "name", Message: "a power plant with this name already exists"},
+					}, "duplicate")}).Once()
+			},
+			results: func(batch *model.PowerPlantBatch, err error) {
+				assert.NoError(t, err)
+				assert.False(t, batch.Success)
+				assert.Equal(t, [][]string{{}, {"name"}}, batchErrors(batch))
+				assert.Equal(t, "a power plant with this name already exists", batch.Items[1].Errors[0].Message)
+				for _, item := range batch.Items {
+					assert.Nil(t, item.Plant)
+				}
+			},
+		},
+		{
+			caseName:     "CreatePowerPlants_TooManyItems",
+			params:       make([]*model.PowerPlant, 101),
+			expectations: func(params []*model.PowerPlant) {},
+			results: func(batch *model.PowerPlantBatch, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+				assert.Nil(t, batch)
+			},
+		},
+		{
+			caseName: "CreatePowerPlants_Error",
+			params:   []*model.PowerPlant{{Name: "Plant A", Timezone: "UTC"}},
+			expectations: func(params []*model.PowerPlant) {
+				mc.PowerPlantRepository.On("GetPowerPlantByName", mock.Anything, "Plant A").
+					Return(nil, nil).Once()
+				mc.TransactionManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(withinTransaction).Once()
+				mc.PowerPlantRepository.On("CreatePowerPlants", mock.Anything, mock.Anything, params).
+					Return(assert.AnError).Once()
+			},
+			results: func(batch *model.PowerPlantBatch, err error) {
+				assert.ErrorIs(t, err, assert.AnError)
+				assert.Nil(t, batch)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			testCase.expectations(testCase.params)
+			batch, err := testUsecase.CreatePowerPlants(ctx, testCase.params)
+			testCase.results(batch, err)
+		})
+	}
+}
+
+func TestUpdatePowerPlants(t *testing.T) {
+	mc := test.InitMockComponent(t)
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "operator"})
+	testUsecase := powerplantusecase.NewPowerPlantUsecase(mc.PowerPlantRepository, mc.AuditEventRepository, mc.TransactionManager, mc.TimezoneDetector, mc.Config.Map)
+
+	name := func(name string) *string { return &name }
+	coordinate := func(coordinate float64) *float64 { return &coordinate }
+	stored := func(id, name string) *model.PowerPlant {
+		return &model.PowerPlant{ID: id, Name: name, Latitude: 1, Longitude: 2, Timezone: "UTC", Version: 3}
+	}
+
+	var testCases = []struct {
+		caseName     string
+		params       []*model.UpdatePowerPlantInput
+		expectations func(params []*model.UpdatePowerPlantInput)
+		results      func(batch *model.PowerPlantBatch, err error)
+	}{
+		{
+			caseName: "UpdatePowerPlants_Success",
+			params: []*model.UpdatePowerPlantInput{
+				{ID: "1", Version: 3, Name: name("Plant A2")},
+				{ID: "2", Version: 3, Latitude: coordinate(5)},
+			},
+			expectations: func(params []*model.UpdatePowerPlantInput) {
+				mc.PowerPlantRepository.On("GetPowerPlantByID", mock.Anything, "1").Return(stored("1", "Plant A"), nil).Once()
+				mc.PowerPlantRepository.On("GetPowerPlantByID", mock.Anything, "2").Return(stored("2", "Plant B"), nil).Once()
+				mc.PowerPlantRepository.On("GetPowerPlantByName", mock.Anything, mock.Anything).Return(nil, nil).Twice()
+				mc.TimezoneDetector.On("DetectTimezone", mock.Anything, 5.0, 2.0).Return("Asia/Jakarta", nil).Once()
+				mc.TransactionManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(withinTransaction).Once()
+				mc.PowerPlantRepository.On("UpdatePowerPlants", mock.Anything, mock.Anything, mock.MatchedBy(func(plants []*model.PowerPlant) bool {
+					return len(plants) == 2 && plants[0].Name == "Plant A2" && plants[1].Latitude == 5 && plants[1].Timezone == "Asia/Jakarta"
+				})).Return(nil).Once()
+				mc.AuditEventRepository.On("CreateAuditEvent", mock.Anything, mock.Anything, auditEvent(model.AuditActionUpdate, "1")).Return(nil).Once()
+				mc.AuditEventRepository.On("CreateAuditEvent", mock.Anything, mock.Anything, auditEvent(model.AuditActionUpdate, "2")).Return(nil).Once()
+			},
+			results: func(batch *model.PowerPlantBatch, err error) {
+				assert.NoError(t, err)
+				assert.True(t, batch.Success)
+				if assert.Len(t, batch.Items, 2) {
+					assert.Equal(t, "Plant A2", batch.Items[0].Plant.Name)
+					assert.Equal(t, "Asia/Jakarta", batch.Items[1].Plant.Timezone)
+				}
+			},
+		},
+		{
+			caseName: "UpdatePowerPlants_InvalidItems",
+			params: []*model.UpdatePowerPlantInput{
+				{ID: "1", Version: 3, Name: name("Plant A2")},
+				{ID: "1", Version: 3},
+				{ID: "404", Version: 1},
+				{ID: "2", Version: 2},
+			},
+			expectations: func(params []*model.UpdatePowerPlantInput) {
+				mc.PowerPlantRepository.On("GetPowerPlantByID", mock.Anything, "1").Return(stored("1", "Plant A"), nil).Once()
+				mc.PowerPlantRepository.On("GetPowerPlantByID", mock.Anything, "404").Return(nil, nil).Once()
+				mc.PowerPlantRepository.On("GetPowerPlantByID", mock.Anything, "2").Return(stored("2", "Plant B"), nil).Once()
+				mc.PowerPlantRepository.On("GetPowerPlantByName", mock.Anything, "Plant A2").Return(nil, nil).Once()
+			},
+			results: func(batch *model.PowerPlantBatch, err error) {
+				assert.NoError(t, err)
+				assert.False(t, batch.Success)
+				assert.Equal(t, [][]string{{}, {"id"}, {"id"}, {"version"}}, batchErrors(batch))
+				assert.Equal(t, "power plant is at version 3, not 2", batch.Items[3].Errors[0].Message)
+			},
+		},
+		{
+			caseName: "UpdatePowerPlants_Conflict",
+			params: []*model.UpdatePowerPlantInput{
+				{ID: "1", Version: 3, Name: name("Plant A2")},
+				{ID: "2", Version: 3, Name: name("Plant B2")},
+			},
+			expectations: func(params []*model.UpdatePowerPlantInput) {
+				mc.PowerPlantRepository.On("GetPowerPlantByID", mock.Anything, "1").Return(stored("1", "Plant A"), nil).Once()
+				mc.PowerPlantRepository.On("GetPowerPlantByID", mock.Anything, "2").Return(stored("2", "Plant B"), nil).Once()
+				mc.PowerPlantRepository.On("GetPowerPlantByName", mock.Anything, mock.Anything).Return(nil, nil).Twice()
+				mc.TransactionManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(withinTransaction).Once()
+				mc.PowerPlantRepository.On("UpdatePowerPlants", mock.Anything, mock.Anything, mock.Anything).
+					Return(&powerplantrepo.ItemError{Index: 1, Err: derrors.New(derrors.Conflict, "conflict")}).Once()
+			},
+			results: func(batch *model.PowerPlantBatch, err error) {
+				assert.NoError(t, err)
+				assert.False(t, batch.Success)
+				assert.Equal(t, [][]string{{}, {"version"}}, batchErrors(batch))
+				assert.Nil(t, batch.Items[0].Plant)
+			},
+		},
+		{
+			caseName: "UpdatePowerPlants_RenamedMeanwhile",
+			params: []*model.UpdatePowerPlantInput{
+				{ID: "1", Version: 3, Name: name("Plant B2")},
+			},
+			expectations: func(params []*model.UpdatePowerPlantInput) {
+				mc.PowerPlantRepository.On("GetPowerPlantByID", mock.Anything, "1").Return(stored("1", "Plant A"), nil).Once()
+				mc.PowerPlantRepository.On("GetPowerPlantByName", mock.Anything, "Plant B2").Return(nil, nil).Once()
+				mc.TransactionManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(withinTransaction).Once()
+				mc.PowerPlantRepository.On("UpdatePowerPlants", mock.Anything, mock.Anything, mock.Anything).
+					Return(&powerplantrepo.ItemError{Index: 0, Err: derrors.NewWithFields(derrors.Duplicate, []derrors.FieldError{
+						{Field: "name", Message: "a power plant with this name already exists"},
+					}, "duplicate")}).Once()
+			},
+			results: func(batch *model.PowerPlantBatch, err error) {
+				assert.NoError(t, err)
+				assert.False(t, batch.Success)
+				assert.Equal(t, [][]string{{"name"}}, batchErrors(batch))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			testCase.expectations(testCase.params)
+			batch, err := testUsecase.UpdatePowerPlants(ctx, testCase.params)
+			testCase.results(batch, err)
+		})
+	}
+}
+
+func TestDeletePowerPlant(t *testing.T) {
+	mc := test.InitMockComponent(t)
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "admin"})
+	testUsecase := powerplantusecase.NewPowerPlantUsecase(mc.PowerPlantRepository, mc.AuditEventRepository, mc.TransactionManager, mc.TimezoneDetector, mc.Config.Map)
+
+	var testCases = []struct {
+		caseName     string
+		id           string
+		expectations func(id string)
+		results      func(err error)
+	}{
+		{
+			caseName: "DeletePowerPlant_Success",
+			id:       "1",
+			expectations: func(id string) {
+				mc.PowerPlantRepository.On("GetPowerPlantByID", mock.Anything, id).
+					Return(&model.PowerPlant{ID: id}, nil)
+				mc.TransactionManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(withinTransaction).Once()
+				mc.PowerPlantRepository.On("DeletePowerPlant", mock.Anything, mock.Anything, id).
+					Return(nil)
+				mc.AuditEventRepository.On("CreateAuditEvent", mock.Anything, mock.Anything, auditEvent(model.AuditActionDelete, id)).
+					Return(nil).Once()
+			},
+			results: func(err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			caseName: "DeletePowerPlant_NotFound",
+			id:       "2",
+			expectations: func(id string) {
+				mc.TransactionManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(withinTransaction).Once()
+				mc.PowerPlantRepository.On("GetPowerPlantByID", mock.Anything, id).
+					Return(nil, nil)
+			},
+			results: func(err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.NotFound))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			testCase.expectations(testCase.id)
+			err := testUsecase.DeletePowerPlant(ctx, testCase.id)
+			testCase.results(err)
+		})
+	}
+}
+
+func TestGetAuditTrail(t *testing.T) {
+	mc := test.InitMockComponent(t)
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "admin", OrganizationID: "1"})
+	testUsecase := powerplantusecase.NewPowerPlantUsecase(mc.PowerPlantRepository, mc.AuditEventRepository, mc.TransactionManager, mc.TimezoneDetector, mc.Config.Map)
+
+	mc.AuditEventRepository.On("GetAuditEvents", mock.Anything, "power_plant", "1").
+		Return([]*model.AuditEvent{{ID: "1", EntityID: "1", Action: model.AuditActionCreate}}, nil).Once()
+
+	events, err := testUsecase.GetAuditTrail(ctx, "1")
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+}
@@ -0,0 +1,184 @@
+package powerplantusecase
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"tensor-graphql/internal/model"
+	powerplantrepo "tensor-graphql/internal/repository/power_plant"
+	"tensor-graphql/pkg/derrors"
+	"tensor-graphql/pkg/geo"
+	"time"
+
+	"github.com/asaskevich/govalidator"
+)
+
+const (
+	powerPlantNameMinLength = "1"
+	powerPlantNameMaxLength = "255"
+
+	// powerPlantNamePattern allows letters, digits, spaces and the punctuation
+	// commonly found in site names such as "Block 2 (North)" or "O'Hare #3".
+	powerPlantNamePattern = `^[\p{L}\p{M}\p{N} .,'&()/#_-]+$`
+
+	// nearbyMaxRadiusKm and nearbyMaxLimit bound the work of a single nearby
+	// search.
+	nearbyMaxRadiusKm = 1000
+	nearbyMaxLimit    = 100
+
+	// polygonMaxPositions bounds the positions of a polygon, every edge is
+	// tested by the database.
+	polygonMaxPositions = 1000
+)
+
+type powerplantValidator struct {
+	powerplantRepo powerplantrepo.PowerPlantRepository
+}
+
+func newPowerPlantValidator(powerplantRepo powerplantrepo.PowerPlantRepository) *powerplantValidator {
+	return &powerplantValidator{
+		powerplantRepo: powerplantRepo,
+	}
+}
+
+// Validate checks the fields of powerplant and makes sure no other power plant
+// already uses its name.
+func (v *powerplantValidator) Validate(ctx context.Context, powerplant *model.PowerPlant) (err error) {
+	fields := validatePowerPlantFields(powerplant)
+	if len(fields) > 0 {
+		return derrors.NewWithFields(derrors.InvalidArgument, fields, "invalid power plant")
+	}
+
+	existing, err := v.powerplantRepo.GetPowerPlantByName(ctx, powerplant.Name)
+	if err != nil {
+		return err
+	}
+
+	if existing != nil && existing.ID != powerplant.ID {
+		return derrors.NewWithFields(derrors.Duplicate, []derrors.FieldError{
+			{Field: "name", Message: "a power plant with this name already exists"},
+		}, "duplicate power plant name %q", powerplant.Name)
+	}
+
+	return nil
+}
+
+// validateUnique checks powerplant like Validate and that no earlier item of
+// the same request uses its name. names maps the names of the valid items to
+// where they were given and gets the name of powerplant when it is valid. The
+// problems are returned, err only reports a failed lookup.
+func (u *powerplantUsecase) validateUnique(ctx context.Context, powerplant *model.PowerPlant, names map[string]string, where string) (fieldErrors []*model.FieldError, err error) {
+	fieldErrors = []*model.FieldError{}
+	if used, ok := names[powerplant.Name]; ok {
+		return append(fieldErrors, newFieldError("name", "name is already used on "+used)), nil
+	}
+
+	err = u.validator.Validate(ctx, powerplant)
+	if err != nil && !derrors.IsErrCode(err, derrors.InvalidArgument) && !derrors.IsErrCode(err, derrors.Duplicate) {
+		return nil, err
+	}
+	for _, field := range derrors.FieldsOf(err) {
+		fieldErrors = append(fieldErrors, newFieldError(field.Field, field.Message))
+	}
+
+	if len(fieldErrors) == 0 {
+		names[powerplant.Name] = where
+	}
+	return fieldErrors, nil
+}
+
+// newFieldError returns a problem of an item of a request, field is empty when
+// it concerns the whole item.
+func newFieldError(field, message string) *model.FieldError {
+	fieldError := &model.FieldError{Message: message}
+	if field != "" {
+		fieldError.Field = &field
+	}
+	return fieldError
+}
+
+// validateNearbyQuery checks the arguments of a nearby search.
+func validateNearbyQuery(latitude, longitude, radiusKm float64, limit int) (fields []derrors.FieldError) {
+	fields = append(fields, validateCoordinates(latitude, longitude)...)
+
+	if radiusKm <= 0 || radiusKm > nearbyMaxRadiusKm {
+		fields = append(fields, derrors.FieldError{Field: "radiusKm", Message: "radiusKm must be greater than 0 and at most " + strconv.Itoa(nearbyMaxRadiusKm)})
+	}
+
+	if limit < 1 || limit > nearbyMaxLimit {
+		fields = append(fields, derrors.FieldError{Field: "limit", Message: "limit must be between 1 and " + strconv.Itoa(nearbyMaxLimit)})
+	}
+
+	return fields
+}
+
+// validateBounds checks the edges of a map region, West is greater than East
+// when it crosses the antimeridian.
+func validateBounds(bounds geo.Bounds) (fields []derrors.FieldError) {
+	for _, edge := range []struct {
+		field string
+		value float64
+		max   float64
+	}{
+		{field: "south", value: bounds.South, max: 90},
+		{field: "west", value: bounds.West, max: 180},
+		{field: "north", value: bounds.North, max: 90},
+		{field: "east", value: bounds.East, max: 180},
+	} {
+		if !govalidator.InRangeFloat64(edge.value, -edge.max, edge.max) {
+			fields = append(fields, derrors.FieldError{Field: edge.field, Message: fmt.Sprintf("%s must be between %v and %v", edge.field, -edge.max, edge.max)})
+		}
+	}
+
+	if bounds.South > bounds.North {
+		fields = append(fields, derrors.FieldError{Field: "south", Message: "south must not be greater than north"})
+	}
+
+	return fields
+}
+
+func validatePolygon(polygon geo.Polygon) (fields []derrors.FieldError) {
+	var positions int
+	for _, ring := range polygon.Rings {
+		positions += len(ring)
+	}
+	if positions > polygonMaxPositions {
+		fields = append(fields, derrors.FieldError{Field: "geojson", Message: fmt.Sprintf("polygon must have at most %d positions", polygonMaxPositions)})
+	}
+
+	return fields
+}
+
+func validatePowerPlantFields(powerplant *model.PowerPlant) (fields []derrors.FieldError) {
+	switch {
+	case strings.TrimSpace(powerplant.Name) == "":
+		fields = append(fields, derrors.FieldError{Field: "name", Message: "name is required"})
+	case !govalidator.RuneLength(powerplant.Name, powerPlantNameMinLength, powerPlantNameMaxLength):
+		fields = append(fields, derrors.FieldError{Field: "name", Message: "name must be at most " + powerPlantNameMaxLength + " characters"})
+	case !govalidator.Matches(powerplant.Name, powerPlantNamePattern):
+		fields = append(fields, derrors.FieldError{Field: "name", Message: "name contains unsupported characters"})
+	}
+
+	fields = append(fields, validateCoordinates(powerplant.Latitude, powerplant.Longitude)...)
+
+	if powerplant.Timezone != "" {
+		if _, err := time.LoadLocation(powerplant.Timezone); err != nil {
+			fields = append(fields, derrors.FieldError{Field: "timezone", Message: "timezone must be an IANA timezone name"})
+		}
+	}
+
+	return fields
+}
+
+func validateCoordinates(latitude, longitude float64) (fields []derrors.FieldError) {
+	if !govalidator.InRangeFloat64(latitude, -90, 90) {
+		fields = append(fields, derrors.FieldError{Field: "latitude", Message: "latitude must be between -90 and 90"})
+	}
+
+	if !govalidator.InRangeFloat64(longitude, -180, 180) {
+		fields = append(fields, derrors.FieldError{Field: "longitude", Message: "longitude must be between -180 and 180"})
+	}
+
+	return fields
+}
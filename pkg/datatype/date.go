@@ -3,6 +3,9 @@ package datatype
 import (
 	"database/sql/driver"
 	"errors"
+	"fmt"
+	"io"
+	"strconv"
 	"tensor-graphql/pkg/derrors"
 	"time"
 )
@@ -81,6 +84,31 @@ func (t *Date) UnmarshalText(b []byte) error {
 	return nil
 }
 
+// MarshalGQL implements the graphql.Marshaler interface.
+func (t Date) MarshalGQL(w io.Writer) {
+	if t.value == nil {
+		_, _ = io.WriteString(w, "null")
+		return
+	}
+	_, _ = io.WriteString(w, strconv.Quote(t.value.Format(dateFormat)))
+}
+
+// UnmarshalGQL implements the graphql.Unmarshaler interface, the input must be
+// formatted as YYYY-MM-DD.
+func (t *Date) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("Date must be a string, got %T", v)
+	}
+	tmp, err := time.Parse(dateFormat, str)
+	if err != nil {
+		return fmt.Errorf("Date must be formatted as YYYY-MM-DD: %w", err)
+	}
+	t.value = &tmp
+
+	return nil
+}
+
 // Scan implements the Scanner interface.
 func (t *Date) Scan(value interface{}) error {
 	if value == nil {
@@ -3,6 +3,9 @@ package datatype
 import (
 	"database/sql/driver"
 	"errors"
+	"fmt"
+	"io"
+	"strconv"
 	"time"
 )
 
@@ -59,6 +62,32 @@ func (t *Time) UnmarshalText(b []byte) error {
 	return err
 }
 
+// MarshalGQL implements the graphql.Marshaler interface, the time is written
+// in RFC3339 keeping its own offset.
+func (t Time) MarshalGQL(w io.Writer) {
+	if t.value == nil {
+		_, _ = io.WriteString(w, "null")
+		return
+	}
+	_, _ = io.WriteString(w, strconv.Quote(t.String()))
+}
+
+// UnmarshalGQL implements the graphql.Unmarshaler interface, the input must be
+// an RFC3339 time with an explicit offset.
+func (t *Time) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("DateTime must be a string, got %T", v)
+	}
+	tmp, err := time.Parse(time.RFC3339, str)
+	if err != nil {
+		return fmt.Errorf("DateTime must be an RFC3339 time: %w", err)
+	}
+	t.value = &tmp
+
+	return nil
+}
+
 // Scan implements the Scanner interface.
 func (t *Time) Scan(value interface{}) error {
 	if value == nil {
@@ -66,24 +95,33 @@ func (t *Time) Scan(value interface{}) error {
 		return nil
 	}
 
-	b, ok := value.([]byte)
-	if !ok {
-		return errors.New("type assertion to []byte failed")
+	switch v := value.(type) {
+	case time.Time:
+		t.value = &v
+	case []byte:
+		tmp, err := time.Parse("2006-01-02 15:04:05", string(v))
+		if err != nil {
+			return err
+		}
+		t.value = &tmp
+	case string:
+		tmp, err := time.Parse("2006-01-02 15:04:05", v)
+		if err != nil {
+			return err
+		}
+		t.value = &tmp
+	default:
+		return errors.New("datatype.Time: unsupported scan source type")
 	}
-	tmp, err := time.Parse("2006-01-02 15:04:05", string(b))
-	if err != nil {
-		return err
-	}
-	t.value = &tmp
 	return nil
 }
 
-// Value implements the driver Value interface.
+// Value implements the driver Value interface, times are stored in UTC.
 func (t *Time) Value() (driver.Value, error) {
 	if t.value == nil {
 		return nil, nil
 	}
-	return time.Time(*t.value).Format("2006-01-02 15:04:05"), nil
+	return time.Time(*t.value).UTC().Format("2006-01-02 15:04:05"), nil
 }
 
 func (t *Time) IsBefore(time Time) bool {
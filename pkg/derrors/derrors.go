@@ -12,9 +12,16 @@ import (
 // Error represents an error that could be wrapping another error, it includes a code for determining what
 // triggered the error.
 type Error struct {
-	orig error
-	code ErrorCode
-	msg  string
+	orig   error
+	code   ErrorCode
+	msg    string
+	fields []FieldError
+}
+
+// FieldError describes why a single input field was rejected.
+type FieldError struct {
+	Field   string
+	Message string
 }
 
 // ErrorCode defines supported error status.
@@ -27,6 +34,7 @@ const (
 	Duplicate
 	Unauthorized
 	Forbidden
+	Conflict
 )
 
 var codes = []struct {
@@ -39,6 +47,7 @@ var codes = []struct {
 	{Duplicate, http.StatusBadRequest},
 	{Unauthorized, http.StatusUnauthorized},
 	{Forbidden, http.StatusForbidden},
+	{Conflict, http.StatusConflict},
 }
 
 // ToStatus returns a status code corresponding to err.
@@ -88,6 +97,15 @@ func New(code ErrorCode, format string, args ...interface{}) error {
 	}
 }
 
+// NewWithFields instantiates a new error carrying per-field details.
+func NewWithFields(code ErrorCode, fields []FieldError, format string, args ...interface{}) error {
+	return &Error{
+		code:   code,
+		msg:    fmt.Sprintf(format, args...),
+		fields: fields,
+	}
+}
+
 // WrapStack returns a wrapped error with error code.
 func WrapStack(orig error, code ErrorCode, format string, args ...interface{}) error {
 	if orig == nil {
@@ -122,3 +140,17 @@ func (e *Error) Unwrap() error {
 func (e *Error) Code() ErrorCode {
 	return e.code
 }
+
+// Fields returns the per-field details attached to this error.
+func (e *Error) Fields() []FieldError {
+	return e.fields
+}
+
+// FieldsOf returns the per-field details of err, if any.
+func FieldsOf(err error) []FieldError {
+	var ierr *Error
+	if errors.As(err, &ierr) {
+		return ierr.fields
+	}
+	return nil
+}
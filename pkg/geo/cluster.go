@@ -0,0 +1,144 @@
+package geo
+
+import "math"
+
+// Cluster groups the points falling into one cell of a grid.
+type Cluster struct {
+	// Center is the mean location of the points.
+	Center Point
+	Count  int
+	// Bounds is the grid cell.
+	Bounds Bounds
+}
+
+// Grid counts points per cell of a grid over bounds, the counts are added
+// cell by cell so they can come from a GROUP BY of a database as well.
+type Grid struct {
+	bounds Bounds
+	size   int
+	cells  map[int]*gridCell
+}
+
+type gridCell struct {
+	count               int
+	latitude, longitude float64
+}
+
+// NewGrid splits bounds into size by size cells.
+func NewGrid(bounds Bounds, size int) *Grid {
+	if size < 1 {
+		size = 1
+	}
+
+	return &Grid{
+		bounds: bounds,
+		size:   size,
+		cells:  make(map[int]*gridCell),
+	}
+}
+
+// CellHeight and CellWidth return the size of the cells in degrees.
+func (g *Grid) CellHeight() float64 {
+	return (g.bounds.North - g.bounds.South) / float64(g.size)
+}
+
+func (g *Grid) CellWidth() float64 {
+	return g.bounds.width() / float64(g.size)
+}
+
+// Add counts count points in the cell at row and column, counted from the
+// south west corner, latitude is the sum of their latitudes and offset the sum
+// of their degrees east of West. Indexes past the north or east edge count
+// for the last cell.
+func (g *Grid) Add(row, column, count int, latitude, offset float64) {
+	key := g.clamp(row)*g.size + g.clamp(column)
+	c, ok := g.cells[key]
+	if !ok {
+		c = &gridCell{}
+		g.cells[key] = c
+	}
+	c.count += count
+	c.latitude += latitude
+	// Longitudes are averaged east of West so cells across the antimeridian
+	// get a center inside of them.
+	c.longitude += offset
+}
+
+// Clusters returns a cluster for every cell holding points, ordered from south
+// west to north east.
+func (g *Grid) Clusters() []Cluster {
+	height, width := g.CellHeight(), g.CellWidth()
+
+	clusters := make([]Cluster, 0, len(g.cells))
+	for key := 0; key < g.size*g.size; key++ {
+		c, ok := g.cells[key]
+		if !ok {
+			continue
+		}
+
+		row, column := key/g.size, key%g.size
+		west := g.bounds.West + float64(column)*width
+		clusters = append(clusters, Cluster{
+			Center: Point{
+				Latitude:  c.latitude / float64(c.count),
+				Longitude: normalizeLongitude(g.bounds.West + c.longitude/float64(c.count)),
+			},
+			Count: c.count,
+			Bounds: Bounds{
+				South: g.bounds.South + float64(row)*height,
+				West:  normalizeLongitude(west),
+				North: g.bounds.South + float64(row+1)*height,
+				East:  normalizeLongitude(west + width),
+			},
+		})
+	}
+
+	return clusters
+}
+
+func (g *Grid) clamp(index int) int {
+	return min(max(index, 0), g.size-1)
+}
+
+// ClusterGrid splits bounds into gridSize by gridSize cells and returns a
+// cluster for every cell holding points, ordered from south west to north
+// east. Points outside of bounds are left out.
+func ClusterGrid(points []Point, bounds Bounds, gridSize int) []Cluster {
+	grid := NewGrid(bounds, gridSize)
+	height, width := grid.CellHeight(), grid.CellWidth()
+
+	for _, point := range points {
+		if !bounds.Contains(point) {
+			continue
+		}
+
+		offset := bounds.eastOf(point.Longitude)
+		grid.Add(gridIndex(point.Latitude-bounds.South, height), gridIndex(offset, width), 1, point.Latitude, offset)
+	}
+
+	return grid.Clusters()
+}
+
+// width returns the longitude span of the box in degrees.
+func (b Bounds) width() float64 {
+	if b.CrossesAntimeridian() {
+		return b.East + 360 - b.West
+	}
+	return b.East - b.West
+}
+
+// eastOf returns how many degrees longitude lies east of West.
+func (b Bounds) eastOf(longitude float64) float64 {
+	offset := longitude - b.West
+	if offset < 0 {
+		offset += 360
+	}
+	return offset
+}
+
+func gridIndex(offset, size float64) int {
+	if size <= 0 {
+		return 0
+	}
+	return int(math.Floor(offset / size))
+}
@@ -0,0 +1,56 @@
+package geo_test
+
+import (
+	"tensor-graphql/pkg/geo"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterGrid(t *testing.T) {
+	t.Run("GroupsByCell", func(t *testing.T) {
+		bounds := geo.Bounds{South: 0, West: 0, North: 10, East: 10}
+		points := []geo.Point{
+			{Latitude: 1, Longitude: 1},
+			{Latitude: 3, Longitude: 3},
+			{Latitude: 9, Longitude: 9},
+			{Latitude: 10, Longitude: 10},
+			{Latitude: 20, Longitude: 20},
+		}
+
+		clusters := geo.ClusterGrid(points, bounds, 2)
+		assert.Equal(t, []geo.Cluster{
+			{
+				Center: geo.Point{Latitude: 2, Longitude: 2},
+				Count:  2,
+				Bounds: geo.Bounds{South: 0, West: 0, North: 5, East: 5},
+			},
+			{
+				Center: geo.Point{Latitude: 9.5, Longitude: 9.5},
+				Count:  2,
+				Bounds: geo.Bounds{South: 5, West: 5, North: 10, East: 10},
+			},
+		}, clusters)
+	})
+
+	t.Run("AcrossTheAntimeridian", func(t *testing.T) {
+		bounds := geo.Bounds{South: 0, West: 170, North: 10, East: -170}
+		points := []geo.Point{
+			{Latitude: 2, Longitude: 179},
+			{Latitude: 2, Longitude: -179},
+		}
+
+		clusters := geo.ClusterGrid(points, bounds, 2)
+		if assert.Len(t, clusters, 2) {
+			assert.Equal(t, 1, clusters[0].Count)
+			assert.Equal(t, geo.Bounds{South: 0, West: 170, North: 5, East: 180}, clusters[0].Bounds)
+			assert.Equal(t, geo.Bounds{South: 0, West: 180, North: 5, East: -170}, clusters[1].Bounds)
+		}
+
+		clusters = geo.ClusterGrid(points, bounds, 1)
+		if assert.Len(t, clusters, 1) {
+			assert.Equal(t, 2, clusters[0].Count)
+			assert.InDelta(t, 180, clusters[0].Center.Longitude, 1e-9)
+		}
+	})
+}
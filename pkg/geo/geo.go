@@ -0,0 +1,93 @@
+// Package geo holds spherical geometry helpers on WGS 84 coordinates.
+package geo
+
+import "math"
+
+// EarthRadiusKm is the mean radius of the earth.
+const EarthRadiusKm = 6371.0088
+
+// Point is a location in degrees.
+type Point struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Bounds is a latitude and longitude box in degrees. West is greater than
+// East when the box crosses the antimeridian.
+type Bounds struct {
+	South float64
+	West  float64
+	North float64
+	East  float64
+}
+
+// DistanceKm returns the great-circle distance between a and b with the
+// haversine formula.
+func DistanceKm(a, b Point) float64 {
+	lat1, lat2 := radians(a.Latitude), radians(b.Latitude)
+	dLat := lat2 - lat1
+	dLon := radians(b.Longitude - a.Longitude)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// BoundsAround returns the smallest box holding every point within radiusKm
+// of center. It spans all longitudes when the circle reaches a pole.
+func BoundsAround(center Point, radiusKm float64) Bounds {
+	angle := radiusKm / EarthRadiusKm
+	dLat := degrees(angle)
+
+	bounds := Bounds{
+		South: center.Latitude - dLat,
+		West:  -180,
+		North: center.Latitude + dLat,
+		East:  180,
+	}
+	if bounds.South <= -90 || bounds.North >= 90 || angle >= math.Pi/2 {
+		bounds.South = math.Max(bounds.South, -90)
+		bounds.North = math.Min(bounds.North, 90)
+		return bounds
+	}
+
+	dLon := degrees(math.Asin(math.Sin(angle) / math.Cos(radians(center.Latitude))))
+	bounds.West = normalizeLongitude(center.Longitude - dLon)
+	bounds.East = normalizeLongitude(center.Longitude + dLon)
+
+	return bounds
+}
+
+// CrossesAntimeridian reports whether the box wraps from 180 to -180.
+func (b Bounds) CrossesAntimeridian() bool {
+	return b.West > b.East
+}
+
+// Contains reports whether p lies inside the box, edges included.
+func (b Bounds) Contains(p Point) bool {
+	if p.Latitude < b.South || p.Latitude > b.North {
+		return false
+	}
+	if b.CrossesAntimeridian() {
+		return p.Longitude >= b.West || p.Longitude <= b.East
+	}
+	return p.Longitude >= b.West && p.Longitude <= b.East
+}
+
+func normalizeLongitude(longitude float64) float64 {
+	switch {
+	case longitude > 180:
+		return longitude - 360
+	case longitude < -180:
+		return longitude + 360
+	default:
+		return longitude
+	}
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+func degrees(radians float64) float64 {
+	return radians * 180 / math.Pi
+}
@@ -0,0 +1,75 @@
+package geo_test
+
+import (
+	"tensor-graphql/pkg/geo"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistanceKm(t *testing.T) {
+	var testCases = []struct {
+		caseName string
+		a        geo.Point
+		b        geo.Point
+		distance float64
+	}{
+		{
+			caseName: "SamePoint",
+			a:        geo.Point{Latitude: -6.2, Longitude: 106.8},
+			b:        geo.Point{Latitude: -6.2, Longitude: 106.8},
+			distance: 0,
+		},
+		{
+			caseName: "JakartaToBandung",
+			a:        geo.Point{Latitude: -6.2088, Longitude: 106.8456},
+			b:        geo.Point{Latitude: -6.9175, Longitude: 107.6191},
+			distance: 116.2,
+		},
+		{
+			caseName: "AcrossTheAntimeridian",
+			a:        geo.Point{Latitude: 0, Longitude: 179.5},
+			b:        geo.Point{Latitude: 0, Longitude: -179.5},
+			distance: 111.2,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			assert.InDelta(t, testCase.distance, geo.DistanceKm(testCase.a, testCase.b), 0.1)
+		})
+	}
+}
+
+func TestBoundsAround(t *testing.T) {
+	t.Run("HoldsTheCircle", func(t *testing.T) {
+		center := geo.Point{Latitude: 60, Longitude: 10}
+		bounds := geo.BoundsAround(center, 50)
+
+		assert.False(t, bounds.CrossesAntimeridian())
+		for _, p := range []geo.Point{
+			{Latitude: 60.449, Longitude: 10},
+			{Latitude: 59.551, Longitude: 10},
+			{Latitude: 60, Longitude: 10.899},
+			{Latitude: 60, Longitude: 9.101},
+		} {
+			assert.InDelta(t, 50, geo.DistanceKm(center, p), 0.2)
+			assert.True(t, bounds.Contains(p), "%+v", p)
+		}
+		assert.False(t, bounds.Contains(geo.Point{Latitude: 61, Longitude: 10}))
+	})
+
+	t.Run("CrossesTheAntimeridian", func(t *testing.T) {
+		bounds := geo.BoundsAround(geo.Point{Latitude: 0, Longitude: 179.9}, 50)
+
+		assert.True(t, bounds.CrossesAntimeridian())
+		assert.True(t, bounds.Contains(geo.Point{Latitude: 0, Longitude: -179.9}))
+		assert.False(t, bounds.Contains(geo.Point{Latitude: 0, Longitude: 0}))
+	})
+
+	t.Run("ReachesAPole", func(t *testing.T) {
+		bounds := geo.BoundsAround(geo.Point{Latitude: 89.9, Longitude: 0}, 50)
+
+		assert.Equal(t, geo.Bounds{South: bounds.South, West: -180, North: 90, East: 180}, bounds)
+	})
+}
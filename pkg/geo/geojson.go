@@ -0,0 +1,82 @@
+package geo
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Feature is a GeoJSON Feature with a Point geometry.
+type Feature struct {
+	ID         string
+	Point      Point
+	Properties interface{}
+}
+
+type featureJSON struct {
+	Type       string       `json:"type"`
+	ID         string       `json:"id,omitempty"`
+	Geometry   geometryJSON `json:"geometry"`
+	Properties interface{}  `json:"properties"`
+}
+
+type geometryJSON struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// FeatureCollectionWriter writes a GeoJSON FeatureCollection one feature at a
+// time, so the features never have to be held in memory together.
+type FeatureCollectionWriter struct {
+	w        io.Writer
+	features int
+	err      error
+}
+
+// NewFeatureCollectionWriter returns a writer of a FeatureCollection to w, it
+// writes the opening of the collection with the first feature.
+func NewFeatureCollectionWriter(w io.Writer) *FeatureCollectionWriter {
+	return &FeatureCollectionWriter{w: w}
+}
+
+// Write appends feature to the collection.
+func (fw *FeatureCollectionWriter) Write(feature Feature) error {
+	data, err := json.Marshal(featureJSON{
+		Type: "Feature",
+		ID:   feature.ID,
+		Geometry: geometryJSON{
+			Type: "Point",
+			// GeoJSON positions are in longitude, latitude order.
+			Coordinates: [2]float64{feature.Point.Longitude, feature.Point.Latitude},
+		},
+		Properties: feature.Properties,
+	})
+	if err != nil {
+		return err
+	}
+
+	if fw.features == 0 {
+		fw.write([]byte(`{"type":"FeatureCollection","features":[`))
+	} else {
+		fw.write([]byte(","))
+	}
+	fw.write(data)
+	fw.features++
+
+	return fw.err
+}
+
+// Close ends the collection, it does not close the underlying writer.
+func (fw *FeatureCollectionWriter) Close() error {
+	if fw.features == 0 {
+		fw.write([]byte(`{"type":"FeatureCollection","features":[`))
+	}
+	fw.write([]byte("]}\n"))
+	return fw.err
+}
+
+func (fw *FeatureCollectionWriter) write(data []byte) {
+	if fw.err != nil {
+		return
+	}
+	_, fw.err = fw.w.Write(data)
+}
@@ -0,0 +1,37 @@
+package geo_test
+
+import (
+	"encoding/json"
+	"strings"
+	"tensor-graphql/pkg/geo"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeatureCollectionWriter(t *testing.T) {
+	t.Run("Features", func(t *testing.T) {
+		var b strings.Builder
+		fw := geo.NewFeatureCollectionWriter(&b)
+		assert.NoError(t, fw.Write(geo.Feature{ID: "1", Point: geo.Point{Latitude: -6.2, Longitude: 106.8}, Properties: map[string]string{"name": "Plant A"}}))
+		assert.NoError(t, fw.Write(geo.Feature{ID: "2", Point: geo.Point{Latitude: 1, Longitude: 2}, Properties: map[string]string{"name": "Plant B"}}))
+		assert.NoError(t, fw.Close())
+
+		assert.JSONEq(t, `{
+			"type": "FeatureCollection",
+			"features": [
+				{"type": "Feature", "id": "1", "geometry": {"type": "Point", "coordinates": [106.8, -6.2]}, "properties": {"name": "Plant A"}},
+				{"type": "Feature", "id": "2", "geometry": {"type": "Point", "coordinates": [2, 1]}, "properties": {"name": "Plant B"}}
+			]
+		}`, b.String())
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		var b strings.Builder
+		assert.NoError(t, geo.NewFeatureCollectionWriter(&b).Close())
+
+		var collection map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(b.String()), &collection))
+		assert.Equal(t, []interface{}{}, collection["features"])
+	})
+}
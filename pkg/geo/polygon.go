@@ -0,0 +1,108 @@
+package geo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Polygon is an area bounded by an outer ring, the other rings are holes. Its
+// edges are straight lines in latitude and longitude like in GeoJSON.
+type Polygon struct {
+	Rings [][]Point
+}
+
+type geoJSON struct {
+	Type        string          `json:"type"`
+	Coordinates [][][]float64   `json:"coordinates"`
+	Geometry    json.RawMessage `json:"geometry"`
+}
+
+// ParsePolygon reads a GeoJSON Polygon geometry, or a Feature holding one.
+// Positions are in longitude, latitude order.
+func ParsePolygon(data string) (polygon Polygon, err error) {
+	var object geoJSON
+	err = json.Unmarshal([]byte(data), &object)
+	if err != nil {
+		return polygon, fmt.Errorf("invalid GeoJSON: %w", err)
+	}
+
+	if object.Type == "Feature" {
+		if len(object.Geometry) == 0 || string(object.Geometry) == "null" {
+			return polygon, errors.New("feature has no geometry")
+		}
+		return ParsePolygon(string(object.Geometry))
+	}
+	if object.Type != "Polygon" {
+		return polygon, fmt.Errorf("geometry must be a Polygon, not %q", object.Type)
+	}
+	if len(object.Coordinates) == 0 {
+		return polygon, errors.New("polygon has no rings")
+	}
+
+	for _, coordinates := range object.Coordinates {
+		// A linear ring is closed, its last position repeats the first.
+		if len(coordinates) < 4 {
+			return polygon, errors.New("polygon rings need at least 4 positions")
+		}
+
+		ring := make([]Point, 0, len(coordinates))
+		for _, position := range coordinates {
+			if len(position) < 2 {
+				return polygon, errors.New("positions need a longitude and a latitude")
+			}
+			point := Point{Latitude: position[1], Longitude: position[0]}
+			if math.Abs(point.Latitude) > 90 || math.Abs(point.Longitude) > 180 {
+				return polygon, fmt.Errorf("position %v is out of range", position)
+			}
+			ring = append(ring, point)
+		}
+		if ring[0] != ring[len(ring)-1] {
+			return polygon, errors.New("polygon rings must be closed")
+		}
+
+		polygon.Rings = append(polygon.Rings, ring)
+	}
+
+	return polygon, nil
+}
+
+// Bounds returns the box around the outer ring.
+func (p Polygon) Bounds() Bounds {
+	bounds := Bounds{South: 90, West: 180, North: -90, East: -180}
+	for _, point := range p.Rings[0] {
+		bounds.South = math.Min(bounds.South, point.Latitude)
+		bounds.North = math.Max(bounds.North, point.Latitude)
+		bounds.West = math.Min(bounds.West, point.Longitude)
+		bounds.East = math.Max(bounds.East, point.Longitude)
+	}
+	return bounds
+}
+
+// Contains reports whether point lies inside the outer ring and outside of
+// the holes.
+func (p Polygon) Contains(point Point) bool {
+	if !ringContains(p.Rings[0], point) {
+		return false
+	}
+	for _, hole := range p.Rings[1:] {
+		if ringContains(hole, point) {
+			return false
+		}
+	}
+	return true
+}
+
+// ringContains casts a ray from point and counts the edges it crosses.
+func ringContains(ring []Point, point Point) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a.Latitude > point.Latitude) != (b.Latitude > point.Latitude) &&
+			point.Longitude < (b.Longitude-a.Longitude)*(point.Latitude-a.Latitude)/(b.Latitude-a.Latitude)+a.Longitude {
+			inside = !inside
+		}
+	}
+	return inside
+}
@@ -0,0 +1,58 @@
+package geo_test
+
+import (
+	"tensor-graphql/pkg/geo"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// squareWithHole spans latitude 0 to 10 and longitude 100 to 110 with a hole
+// from 4 to 6.
+const squareWithHole = `{
+	"type": "Polygon",
+	"coordinates": [
+		[[100, 0], [110, 0], [110, 10], [100, 10], [100, 0]],
+		[[104, 4], [106, 4], [106, 6], [104, 6], [104, 4]]
+	]
+}`
+
+func TestParsePolygon(t *testing.T) {
+	var testCases = []struct {
+		caseName string
+		geojson  string
+		valid    bool
+	}{
+		{caseName: "Polygon", geojson: squareWithHole, valid: true},
+		{caseName: "Feature", geojson: `{"type": "Feature", "properties": {}, "geometry": ` + squareWithHole + `}`, valid: true},
+		{caseName: "FeatureWithoutGeometry", geojson: `{"type": "Feature", "geometry": null}`},
+		{caseName: "Point", geojson: `{"type": "Point", "coordinates": [100, 0]}`},
+		{caseName: "OpenRing", geojson: `{"type": "Polygon", "coordinates": [[[100, 0], [110, 0], [110, 10], [100, 10]]]}`},
+		{caseName: "OutOfRange", geojson: `{"type": "Polygon", "coordinates": [[[100, 0], [190, 0], [110, 10], [100, 0]]]}`},
+		{caseName: "NotJSON", geojson: `POLYGON((0 0, 1 0, 1 1, 0 0))`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			_, err := geo.ParsePolygon(testCase.geojson)
+			if testCase.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestPolygon(t *testing.T) {
+	polygon, err := geo.ParsePolygon(squareWithHole)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, geo.Bounds{South: 0, West: 100, North: 10, East: 110}, polygon.Bounds())
+	assert.True(t, polygon.Contains(geo.Point{Latitude: 2, Longitude: 102}))
+	assert.False(t, polygon.Contains(geo.Point{Latitude: 5, Longitude: 105}), "inside the hole")
+	assert.False(t, polygon.Contains(geo.Point{Latitude: 11, Longitude: 105}))
+	assert.False(t, polygon.Contains(geo.Point{Latitude: 5, Longitude: 99}))
+}
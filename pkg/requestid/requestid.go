@@ -0,0 +1,17 @@
+package requestid
+
+import "context"
+
+type contextKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the ID of the current request.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, requestID)
+}
+
+// FromContext returns the request ID stored on ctx, or an empty string when
+// there is none.
+func FromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(contextKey{}).(string)
+	return requestID
+}
@@ -2,6 +2,15 @@
 
 # Generate mocks for repository interfaces
 mockery --name=PowerPlantRepository --dir=internal/repository/power_plant --output=internal/test/mockrepository --outpkg=mockrepository
+mockery --name=APIKeyRepository --dir=internal/repository/api_key --output=internal/test/mockrepository --outpkg=mockrepository
+mockery --name=AuditEventRepository --dir=internal/repository/audit_event --output=internal/test/mockrepository --outpkg=mockrepository
+mockery --name=TransactionManager --dir=internal/repository/common --output=internal/test/mockrepository --outpkg=mockrepository
+mockery --name=PortfolioRepository --dir=internal/repository/portfolio --output=internal/test/mockrepository --outpkg=mockrepository
 
 # Generate mocks for usecase interfaces
-mockery --name=PowerPlantUsecase --dir=internal/usecase/power_plant --output=internal/test/mockusecase --outpkg=mockusecase
\ No newline at end of file
+mockery --name=PowerPlantUsecase --dir=internal/usecase/power_plant --output=internal/test/mockusecase --outpkg=mockusecase
+mockery --name=APIKeyUsecase --dir=internal/usecase/api_key --output=internal/test/mockusecase --outpkg=mockusecase
+mockery --name=TimezoneDetector --dir=internal/usecase/power_plant --output=internal/test/mockusecase --outpkg=mockusecase
+mockery --name=ForecastUsecase --dir=internal/usecase/forecast --output=internal/test/mockusecase --outpkg=mockusecase
+mockery --name=Forecaster --dir=internal/usecase/forecast --output=internal/test/mockusecase --outpkg=mockusecase
+mockery --name=PortfolioUsecase --dir=internal/usecase/portfolio --output=internal/test/mockusecase --outpkg=mockusecase
\ No newline at end of file